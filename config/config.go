@@ -1,12 +1,22 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 	"vet-clinic-api/database"
 	"vet-clinic-api/database/dbmodel"
+	"vet-clinic-api/pkg/authentication"
+	"vet-clinic-api/pkg/mailer"
+	"vet-clinic-api/pkg/password"
 
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+
+	// Timezones embedded for the hosts without the timezone database
+	_ "time/tzdata"
 )
 
 type Config struct {
@@ -17,34 +27,297 @@ type Config struct {
 	// Refresh token
 	JWTRefreshSecret string
 
+	// Access token asymmetric keys, PEM file by "kid" and "kid" of the key signing the new tokens
+	JWTKeyFiles    map[string]string
+	JWTActiveKeyId string
+
+	// Keys used to sign and verify the tokens
+	AccessTokenKeys  *authentication.KeySet
+	RefreshTokenKeys *authentication.KeySet
+
+	// Revoked access tokens
+	RevocationStore authentication.RevocationStore
+
+	// API keys of the machine integrations
+	ApiKeyStore authentication.ApiKeyStore
+
+	// Failed logins by IP address and by unknown email, kept in memory
+	LoginIPThrottle    *authentication.LoginThrottle
+	LoginEmailThrottle *authentication.LoginThrottle
+
+	// Password reset requests by IP address and by email, kept in memory apart from the logins
+	PasswordResetIPThrottle    *authentication.LoginThrottle
+	PasswordResetEmailThrottle *authentication.LoginThrottle
+
+	// Roles which can't log in with a password alone
+	MfaRequiredRoles []string
+
+	// Hashing and strength rules of the passwords
+	PasswordService *password.Service
+
+	// Emails sending and URL of the application used in the links of the emails
+	Mailer mailer.Mailer
+	AppURL string
+
+	// Timezone of the clinic, the times of the visits are given in it
+	ClinicLocation *time.Location
+
+	// Time the deleted cats, visits and treatments stay in the trash before they can be purged
+	TrashRetention time.Duration
+
 	// Repository connection
+	OwnerEntryRepository     dbmodel.OwnerEntryRepository
 	CatEntryRepository       dbmodel.CatEntryRepository
 	TreatmentEntryRepository dbmodel.TreatmentEntryRepository
 	VisitEntryRepository     dbmodel.VisitEntryRepository
 	UserEntryRepository      dbmodel.UserEntryRepository
+
+	// Roles and permissions
+	RoleEntryRepository       dbmodel.RoleEntryRepository
+	PermissionEntryRepository dbmodel.PermissionEntryRepository
+
+	// Issued refresh tokens
+	RefreshTokenEntryRepository dbmodel.RefreshTokenEntryRepository
+
+	// TOTP recovery codes
+	RecoveryCodeEntryRepository dbmodel.RecoveryCodeEntryRepository
+
+	// Password reset and invitation tokens
+	OneTimeTokenEntryRepository dbmodel.OneTimeTokenEntryRepository
+
+	// API keys of the users
+	ApiKeyEntryRepository dbmodel.ApiKeyEntryRepository
+
+	// Changes made through the API
+	AuditLogEntryRepository dbmodel.AuditLogEntryRepository
+
+	// Snapshots of the cats, visits and treatments on every change
+	RevisionEntryRepository dbmodel.RevisionEntryRepository
+
+	// Deleted cats, visits and treatments listed together
+	TrashEntryRepository dbmodel.TrashEntryRepository
+
+	// Search of the cats, visits and treatments, with FTS5 when SQLite is built with it
+	SearchEntryRepository dbmodel.SearchEntryRepository
+
+	// Appointments and the calendars of the vets
+	AppointmentEntryRepository       dbmodel.AppointmentEntryRepository
+	WorkingHoursEntryRepository      dbmodel.WorkingHoursEntryRepository
+	HolidayEntryRepository           dbmodel.HolidayEntryRepository
+	AppointmentReasonEntryRepository dbmodel.AppointmentReasonEntryRepository
 }
 
 func New() (*Config, error) {
 
 	config := Config{}
 
-	// Init DB connection
-	databaseSession, err := gorm.Open(sqlite.Open("vet_clinic_api.db"), &gorm.Config{})
+	// Init DB connection, the constraint errors are translated to be mapped to the HTTP status codes
+	databaseSession, err := gorm.Open(sqlite.Open("vet_clinic_api.db"), &gorm.Config{TranslateError: true})
 	if err != nil {
 		return &config, err
 	}
 
 	config.JWTSecret = os.Getenv("JWT_SECRET")
 	config.JWTRefreshSecret = os.Getenv("JWT_REFRESH_SECRET")
+	config.JWTKeyFiles = parseKeyFiles(os.Getenv("JWT_SIGNING_KEYS"))
+	config.JWTActiveKeyId = os.Getenv("JWT_ACTIVE_KEY_ID")
+
+	// Admin accounts need a second factor unless another list is given
+	mfaRequiredRoles, ok := os.LookupEnv("MFA_REQUIRED_ROLES")
+	if !ok {
+		mfaRequiredRoles = "admin"
+	}
+	config.MfaRequiredRoles = parseList(mfaRequiredRoles)
+
+	// Access tokens are signed with the asymmetric keys when configured, with JWT_SECRET otherwise
+	if len(config.JWTKeyFiles) > 0 {
+		config.AccessTokenKeys, err = authentication.LoadKeySet(config.JWTActiveKeyId, config.JWTKeyFiles)
+		if err != nil {
+			return &config, err
+		}
+
+		// Keep accepting the tokens signed with the secret before the switch
+		if config.JWTSecret != "" {
+			config.AccessTokenKeys.Accept(authentication.NewHMACKeySet(config.JWTSecret))
+		}
+	} else {
+		config.AccessTokenKeys = authentication.NewHMACKeySet(config.JWTSecret)
+	}
+
+	// Refresh tokens are only verified by this API, so they keep the shared secret
+	config.RefreshTokenKeys = authentication.NewHMACKeySet(config.JWTRefreshSecret)
+
+	// The times of the visits are given in UTC unless the timezone of the clinic is given, like Europe/Paris
+	config.ClinicLocation, err = time.LoadLocation(os.Getenv("CLINIC_TIMEZONE"))
+	if err != nil {
+		return &config, fmt.Errorf("CLINIC_TIMEZONE must be a timezone like Europe/Paris")
+	}
 
 	// Models migrate
-	database.Migrate(databaseSession)
+	database.Migrate(databaseSession, config.ClinicLocation)
+
+	// Stamp the user of the request on the created and updated records
+	if err := database.RegisterAuthorship(databaseSession); err != nil {
+		return &config, err
+	}
 
 	// Init repository
+	config.OwnerEntryRepository = dbmodel.NewOwnerEntryRepository(databaseSession)
 	config.CatEntryRepository = dbmodel.NewCatEntryRepository(databaseSession)
 	config.TreatmentEntryRepository = dbmodel.NewTreatmentEntryRepository(databaseSession)
 	config.VisitEntryRepository = dbmodel.NewVisitEntryRepository(databaseSession)
 	config.UserEntryRepository = dbmodel.NewUserEntryRepository(databaseSession)
+	config.RefreshTokenEntryRepository = dbmodel.NewRefreshTokenEntryRepository(databaseSession)
+	config.RoleEntryRepository = dbmodel.NewRoleEntryRepository(databaseSession)
+	config.PermissionEntryRepository = dbmodel.NewPermissionEntryRepository(databaseSession)
+	config.RecoveryCodeEntryRepository = dbmodel.NewRecoveryCodeEntryRepository(databaseSession)
+	config.OneTimeTokenEntryRepository = dbmodel.NewOneTimeTokenEntryRepository(databaseSession)
+	config.ApiKeyEntryRepository = dbmodel.NewApiKeyEntryRepository(databaseSession)
+	config.AuditLogEntryRepository = dbmodel.NewAuditLogEntryRepository(databaseSession)
+	config.RevisionEntryRepository = dbmodel.NewRevisionEntryRepository(databaseSession)
+	config.TrashEntryRepository = dbmodel.NewTrashEntryRepository(databaseSession)
+	config.SearchEntryRepository = dbmodel.NewSearchEntryRepository(databaseSession)
+	config.AppointmentEntryRepository = dbmodel.NewAppointmentEntryRepository(databaseSession)
+	config.WorkingHoursEntryRepository = dbmodel.NewWorkingHoursEntryRepository(databaseSession)
+	config.HolidayEntryRepository = dbmodel.NewHolidayEntryRepository(databaseSession)
+	config.AppointmentReasonEntryRepository = dbmodel.NewAppointmentReasonEntryRepository(databaseSession)
+
+	// Passwords are hashed with argon2id unless another algorithm or cost is requested
+	passwordParams, err := passwordParams()
+	if err != nil {
+		return &config, err
+	}
+
+	config.PasswordService, err = password.New(passwordParams, os.Getenv("PASSWORD_WORDLIST"))
+	if err != nil {
+		return &config, err
+	}
+
+	// Emails are sent with SMTP when requested, written in MAIL_DIR or in the logs otherwise
+	if os.Getenv("MAILER") == "smtp" {
+		smtpPort := os.Getenv("SMTP_PORT")
+		if smtpPort == "" {
+			smtpPort = "587"
+		}
+		config.Mailer = mailer.NewSMTPMailer(os.Getenv("SMTP_HOST"), smtpPort, os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), os.Getenv("MAIL_FROM"))
+	} else {
+		config.Mailer = mailer.NewLogMailer(os.Getenv("MAIL_DIR"))
+	}
+
+	config.AppURL = strings.TrimSuffix(os.Getenv("APP_URL"), "/")
+	if config.AppURL == "" {
+		config.AppURL = "http://localhost:8081"
+	}
+
+	// The trash is kept 30 days unless another retention is requested
+	config.TrashRetention = 30 * 24 * time.Hour
+	if raw := os.Getenv("TRASH_RETENTION_DAYS"); raw != "" {
+		days, err := strconv.Atoi(raw)
+		if err != nil || days < 0 {
+			return &config, fmt.Errorf("TRASH_RETENTION_DAYS must be a positive integer")
+		}
+		config.TrashRetention = time.Duration(days) * 24 * time.Hour
+	}
+
+	// Unknown emails are throttled like the accounts to not reveal which emails exist
+	config.LoginIPThrottle = authentication.NewLoginThrottle(authentication.IPFreeAttempts)
+	config.LoginEmailThrottle = authentication.NewLoginThrottle(authentication.AccountFreeAttempts)
+	config.PasswordResetIPThrottle = authentication.NewLoginThrottle(authentication.IPFreeAttempts)
+	config.PasswordResetEmailThrottle = authentication.NewLoginThrottle(authentication.AccountFreeAttempts)
+
+	// Init the revoked access tokens store, saved in the DB unless "memory" is requested
+	if os.Getenv("TOKEN_REVOCATION_STORE") == "memory" {
+		config.RevocationStore = authentication.NewMemoryRevocationStore()
+	} else {
+		config.RevocationStore = authentication.NewSQLiteRevocationStore(dbmodel.NewRevokedTokenEntryRepository(databaseSession))
+	}
+
+	config.ApiKeyStore = authentication.NewSQLiteApiKeyStore(config.ApiKeyEntryRepository, config.UserEntryRepository)
 
 	return &config, nil
 }
+
+// Read the key files list formatted as "kid1=path/key1.pem,kid2=path/key2.pem"
+func parseKeyFiles(value string) map[string]string {
+
+	files := map[string]string{}
+
+	for _, item := range strings.Split(value, ",") {
+		kid, file, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if ok && kid != "" && file != "" {
+			files[kid] = file
+		}
+	}
+
+	return files
+}
+
+// Read a comma separated list, empty items are ignored
+func parseList(value string) []string {
+
+	var items []string
+
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// Read the password hashing settings, the default value is kept for the missing variables
+func passwordParams() (password.Params, error) {
+
+	params := password.DefaultParams()
+
+	if algorithm := os.Getenv("PASSWORD_HASH_ALGORITHM"); algorithm != "" {
+		params.Algorithm = algorithm
+	}
+
+	values := []struct {
+		name  string
+		value *int
+	}{
+		{"BCRYPT_COST", &params.BcryptCost},
+		{"PASSWORD_MIN_LENGTH", &params.MinLength},
+	}
+
+	for _, item := range values {
+		if raw := os.Getenv(item.name); raw != "" {
+			value, err := strconv.Atoi(raw)
+			if err != nil {
+				return params, fmt.Errorf("%s must be an integer", item.name)
+			}
+			*item.value = value
+		}
+	}
+
+	argon2Values := []struct {
+		name  string
+		value *uint32
+	}{
+		{"ARGON2_MEMORY", &params.Memory},
+		{"ARGON2_ITERATIONS", &params.Iterations},
+	}
+
+	for _, item := range argon2Values {
+		if raw := os.Getenv(item.name); raw != "" {
+			value, err := strconv.ParseUint(raw, 10, 32)
+			if err != nil {
+				return params, fmt.Errorf("%s must be a positive integer", item.name)
+			}
+			*item.value = uint32(value)
+		}
+	}
+
+	if raw := os.Getenv("ARGON2_PARALLELISM"); raw != "" {
+		value, err := strconv.ParseUint(raw, 10, 8)
+		if err != nil {
+			return params, fmt.Errorf("ARGON2_PARALLELISM must be a positive integer")
+		}
+		params.Parallelism = uint8(value)
+	}
+
+	return params, nil
+}
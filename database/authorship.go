@@ -0,0 +1,43 @@
+package database
+
+import (
+	"vet-clinic-api/pkg/authentication"
+
+	"gorm.io/gorm"
+)
+
+// Register the callbacks stamping the user of the request on every created or updated record having a dbmodel.Authorship or a CreatedBy.
+// The request context must be given to the repository with WithContext, records changed without principal are not stamped.
+func RegisterAuthorship(db *gorm.DB) error {
+
+	if err := db.Callback().Create().Before("gorm:create").Register("authorship:create", stampCreatedBy); err != nil {
+		return err
+	}
+
+	return db.Callback().Update().Before("gorm:update").Register("authorship:update", stampUpdatedBy)
+}
+
+func stampCreatedBy(db *gorm.DB) {
+
+	principal, ok := authentication.FromContext(db.Statement.Context)
+	if !ok || db.Statement.Schema == nil || db.Statement.Schema.LookUpField("CreatedBy") == nil {
+		return
+	}
+
+	db.Statement.SetColumn("CreatedBy", principal.UserId, true)
+
+	// The revisions only have a CreatedBy
+	if db.Statement.Schema.LookUpField("UpdatedBy") != nil {
+		db.Statement.SetColumn("UpdatedBy", principal.UserId, true)
+	}
+}
+
+func stampUpdatedBy(db *gorm.DB) {
+
+	principal, ok := authentication.FromContext(db.Statement.Context)
+	if !ok || db.Statement.Schema == nil || db.Statement.Schema.LookUpField("UpdatedBy") == nil {
+		return
+	}
+
+	db.Statement.SetColumn("UpdatedBy", principal.UserId, true)
+}
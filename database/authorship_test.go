@@ -0,0 +1,91 @@
+package database_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"vet-clinic-api/database"
+	"vet-clinic-api/database/databasetest"
+	"vet-clinic-api/database/dbmodel"
+	"vet-clinic-api/pkg/authentication"
+)
+
+// The user of the request is stamped as the creator of a record, then as its last updater
+func TestAuthorship(t *testing.T) {
+
+	db := databasetest.Open(t)
+	if err := database.RegisterAuthorship(db); err != nil {
+		t.Fatal(err)
+	}
+	cats := dbmodel.NewCatEntryRepository(db)
+	revisions := dbmodel.NewRevisionEntryRepository(db)
+
+	// Context of a request made by a user, none for a write made outside of a request
+	as := func(userId uint) context.Context {
+		if userId == 0 {
+			return context.Background()
+		}
+		return authentication.NewContext(context.Background(), &authentication.Principal{UserId: userId})
+	}
+	userId := func(id *uint) string {
+		if id == nil {
+			return "nil"
+		}
+		return fmt.Sprint(*id)
+	}
+
+	tests := []struct {
+		name      string
+		createdBy uint
+		patchedBy uint
+		want      string
+	}{
+		{"created and patched by two users", 1, 2, "created by 1, updated by 2"},
+		{"created by a user and patched without principal", 1, 0, "created by 1, updated by 1"},
+		{"written without principal", 0, 0, "created by nil, updated by nil"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cat, err := cats.WithContext(as(tt.createdBy)).Create(&dbmodel.CatEntry{Name: "Tom", Age: 3})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := cats.WithContext(as(tt.patchedBy)).Patch(int(cat.ID), map[string]interface{}{"age": 4}); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := cats.FindById(int(cat.ID))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stamped := "created by " + userId(got.CreatedBy) + ", updated by " + userId(got.UpdatedBy); stamped != tt.want {
+				t.Errorf("got %s, want %s", stamped, tt.want)
+			}
+		})
+	}
+
+	// The revisions keep the user of each change
+	t.Run("revisions", func(t *testing.T) {
+		cat, err := cats.WithContext(as(1)).Create(&dbmodel.CatEntry{Name: "Felix", Age: 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := cats.WithContext(as(2)).Patch(int(cat.ID), map[string]interface{}{"age": 3}); err != nil {
+			t.Fatal(err)
+		}
+
+		entries, err := revisions.FindByEntity(dbmodel.CatRevision, cat.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		authors := []string{}
+		for _, entry := range entries {
+			authors = append(authors, userId(entry.CreatedBy))
+		}
+		if got := fmt.Sprint(authors); got != "[1 2]" {
+			t.Errorf("got revisions by %s, want [1 2]", got)
+		}
+	})
+}
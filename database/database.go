@@ -2,6 +2,7 @@ package database
 
 import (
 	"log"
+	"time"
 	"vet-clinic-api/database/dbmodel"
 
 	"gorm.io/driver/sqlite"
@@ -19,20 +20,52 @@ func InitDatabase() {
 		log.Fatal("Failed to connect to database:", err)
 	}
 
-	Migrate(DB)
+	Migrate(DB, time.UTC)
 
 	log.Println("Database connected")
 }
 
-// Init the DB by migrate evrey models
-func Migrate(db *gorm.DB) {
+// Init the DB by migrate evrey models, the dates of the visits saved as text are read in the timezone of the clinic
+func Migrate(db *gorm.DB, location *time.Location) {
 
 	db.AutoMigrate(
+		&dbmodel.OwnerEntry{},
 		&dbmodel.CatEntry{},
 		&dbmodel.TreatmentEntry{},
 		&dbmodel.VisitEntry{},
 		&dbmodel.UserEntry{},
+		&dbmodel.RefreshTokenEntry{},
+		&dbmodel.RevokedTokenEntry{},
+		&dbmodel.RecoveryCodeEntry{},
+		&dbmodel.OneTimeTokenEntry{},
+		&dbmodel.PermissionEntry{},
+		&dbmodel.RoleEntry{},
+		&dbmodel.ApiKeyEntry{},
+		&dbmodel.AuditLogEntry{},
+		&dbmodel.RevisionEntry{},
+		&dbmodel.AppointmentEntry{},
+		&dbmodel.WorkingHoursEntry{},
+		&dbmodel.HolidayEntry{},
+		&dbmodel.AppointmentReasonEntry{},
 	)
 
+	// The audit log is append-only, even for a direct access to the DB
+	for _, statement := range []string{
+		"CREATE TRIGGER IF NOT EXISTS audit_log_entries_no_update BEFORE UPDATE ON audit_log_entries BEGIN SELECT RAISE(ABORT, 'audit log is append-only'); END",
+		"CREATE TRIGGER IF NOT EXISTS audit_log_entries_no_delete BEFORE DELETE ON audit_log_entries BEGIN SELECT RAISE(ABORT, 'audit log is append-only'); END",
+	} {
+		if err := db.Exec(statement).Error; err != nil {
+			log.Println("Failed to create the audit log trigger", err)
+		}
+	}
+
+	MigrateVisitDates(db, location)
+
+	MigrateSearch(db)
+
+	BackfillRevisions(db)
+
+	Seed(db)
+
 	log.Println("Database migrated successfully")
 }
@@ -0,0 +1,34 @@
+// Migrated DB for the tests of the repositories and the handlers
+package databasetest
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+	"vet-clinic-api/database"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Open a new DB in a temporary file, migrated and seeded like at the launch of the API, and closed at the end of the test
+func Open(t testing.TB) *gorm.DB {
+
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "vet_clinic_api.db")), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal("Failed to open the test database:", err)
+	}
+
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	database.Migrate(db, time.UTC)
+
+	return db
+}
@@ -0,0 +1,160 @@
+package dbmodel
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Long-lived key of a user for the machine integrations, only its hash is saved
+type ApiKeyEntry struct {
+	gorm.Model
+	Name       string     `json:"api_key_name"`
+	Prefix     string     `json:"api_key_prefix" gorm:"uniqueIndex"`
+	KeyHash    string     `json:"api_key_hash" audit:"-"`
+	UserId     uint       `json:"api_key_user_id" gorm:"index"`
+	ExpiresAt  *time.Time `json:"api_key_expires_at"`
+	LastUsedAt *time.Time `json:"api_key_last_used_at" audit:"-"`
+
+	//Permissions usable with the key, only the ones still granted to the user role, so the key never exceeds the role
+	Scopes []PermissionEntry `json:"api_key_scopes" gorm:"many2many:api_key_scopes;"`
+}
+
+// Audited fields of an API key, recorded on its user, with its scopes by name
+type apiKeyAudit struct {
+	Id        uint       `json:"api_key_id"`
+	Name      string     `json:"api_key_name"`
+	Prefix    string     `json:"api_key_prefix"`
+	ExpiresAt *time.Time `json:"api_key_expires_at"`
+	Scopes    []string   `json:"api_key_scopes"`
+}
+
+func newApiKeyAudit(entry *ApiKeyEntry) *apiKeyAudit {
+
+	scopes := []string{}
+	for _, scope := range entry.Scopes {
+		scopes = append(scopes, scope.Name)
+	}
+	sort.Strings(scopes)
+
+	return &apiKeyAudit{Id: entry.ID, Name: entry.Name, Prefix: entry.Prefix, ExpiresAt: entry.ExpiresAt, Scopes: scopes}
+}
+
+type ApiKeyEntryRepository interface {
+	WithContext(ctx context.Context) ApiKeyEntryRepository
+	Create(entry *ApiKeyEntry) (*ApiKeyEntry, error)
+	FindByUserId(userId uint) ([]*ApiKeyEntry, error)
+	FindByPrefix(prefix string) (*ApiKeyEntry, error)
+	UpdateLastUsed(id uint, usedAt time.Time) error
+	DeleteById(userId uint, id int) error
+	DeleteByUserId(userId uint) error
+}
+
+type apiKeyEntryRepository struct {
+	db *gorm.DB
+}
+
+func NewApiKeyEntryRepository(db *gorm.DB) ApiKeyEntryRepository {
+	return &apiKeyEntryRepository{db: db}
+}
+
+// Return a repository running its queries with the request context, used to record the changes in the audit log
+func (r *apiKeyEntryRepository) WithContext(ctx context.Context) ApiKeyEntryRepository {
+	return &apiKeyEntryRepository{db: r.db.WithContext(ctx)}
+}
+
+func (r *apiKeyEntryRepository) Create(entry *ApiKeyEntry) (*ApiKeyEntry, error) {
+
+	if entry.ExpiresAt != nil {
+		expiresAt := entry.ExpiresAt.UTC()
+		entry.ExpiresAt = &expiresAt
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(entry).Error; err != nil {
+			return err
+		}
+
+		return saveAuditLog(tx, AuditCreate, "user", entry.UserId, nil, newApiKeyAudit(entry))
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (r *apiKeyEntryRepository) FindByUserId(userId uint) ([]*ApiKeyEntry, error) {
+
+	var entries []*ApiKeyEntry
+	if err := r.db.Model(&ApiKeyEntry{}).
+		Preload("Scopes", func(db *gorm.DB) *gorm.DB { return db.Order("name") }).
+		Where("user_id = ?", userId).
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (r *apiKeyEntryRepository) FindByPrefix(prefix string) (*ApiKeyEntry, error) {
+
+	var entries *ApiKeyEntry
+	if err := r.db.Model(&ApiKeyEntry{}).
+		Preload("Scopes", func(db *gorm.DB) *gorm.DB { return db.Order("name") }).
+		Where("prefix = ?", prefix).
+		First(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (r *apiKeyEntryRepository) UpdateLastUsed(id uint, usedAt time.Time) error {
+
+	if err := r.db.Model(&ApiKeyEntry{}).
+		Where("id = ?", id).
+		Update("last_used_at", usedAt.UTC()).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Remove a key of a user for good, it can't be used anymore
+func (r *apiKeyEntryRepository) DeleteById(userId uint, id int) error {
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var entries *ApiKeyEntry
+		if err := tx.Preload("Scopes").Where("id = ? AND user_id = ?", id, userId).First(&entries).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Select("Scopes").Delete(entries).Error; err != nil {
+			return err
+		}
+
+		return saveAuditLog(tx, AuditDelete, "user", userId, newApiKeyAudit(entries), nil)
+	})
+}
+
+// Remove every key of a user, used when the user is deleted
+func (r *apiKeyEntryRepository) DeleteByUserId(userId uint) error {
+
+	entries, err := r.FindByUserId(userId)
+	if err != nil {
+		return err
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, entry := range entries {
+			if err := tx.Unscoped().Select("Scopes").Delete(entry).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,309 @@
+package dbmodel
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// The vet or the cat already has a scheduled appointment at this time
+var ErrAppointmentConflict = errors.New("appointment overlapping another scheduled appointment")
+
+// The appointment is no longer scheduled, it can't be changed
+var ErrAppointmentClosed = errors.New("appointment no longer scheduled")
+
+// States of the appointments, only a scheduled appointment can be changed
+const (
+	AppointmentScheduled   = "scheduled"
+	AppointmentCompleted   = "completed"
+	AppointmentCancelled   = "cancelled"
+	AppointmentRescheduled = "rescheduled"
+	AppointmentNoShow      = "no_show"
+)
+
+type AppointmentEntry struct {
+	gorm.Model
+	CatId  uint   `json:"appointment_cat_id" gorm:"index"`
+	Vet    string `json:"appointment_vet" gorm:"index"`
+	Reason string `json:"appointment_reason"`
+
+	// Booked time, saved in UTC like the visits
+	StartsAt time.Time `json:"appointment_starts_at" gorm:"index"`
+	EndsAt   time.Time `json:"appointment_ends_at"`
+
+	Status string `json:"appointment_status" gorm:"index"`
+
+	// Appointment replaced by this one when it was rescheduled
+	RescheduledFromId *uint `json:"appointment_rescheduled_from_id"`
+
+	// Visit recorded when the appointment was completed
+	VisitId *uint `json:"appointment_visit_id"`
+
+	//Users who booked and last changed the appointment
+	Authorship
+}
+
+// Filters of the appointments, the zero values are ignored
+type AppointmentFilter struct {
+	CatId  uint
+	Vet    string
+	Status string
+
+	// Appointments starting in the range, the end is excluded
+	StartsFrom   time.Time
+	StartsBefore time.Time
+
+	// Appointments of the cats of an owner, an owner id 0 matches no appointment
+	OwnerId *uint
+}
+
+type AppointmentEntryRepository interface {
+	WithContext(ctx context.Context) AppointmentEntryRepository
+	Book(entry *AppointmentEntry) (*AppointmentEntry, error)
+	FindAll(filter AppointmentFilter, page Page) ([]*AppointmentEntry, *PageInfo, error)
+	FindById(id int) (*AppointmentEntry, error)
+	FindScheduled(vet string, from time.Time, to time.Time) ([]*AppointmentEntry, error)
+	Close(id int, status string) (*AppointmentEntry, error)
+	Reschedule(id int, entry *AppointmentEntry) (*AppointmentEntry, error)
+	Complete(id int) (*AppointmentEntry, *VisitEntry, error)
+}
+
+type appointmentEntryRepository struct {
+	db *gorm.DB
+}
+
+func NewAppointmentEntryRepository(db *gorm.DB) AppointmentEntryRepository {
+	return &appointmentEntryRepository{db: db}
+}
+
+// Return a repository running its queries with the request context, used to stamp the user on the changed records
+func (r *appointmentEntryRepository) WithContext(ctx context.Context) AppointmentEntryRepository {
+	return &appointmentEntryRepository{db: r.db.WithContext(ctx)}
+}
+
+// Save a scheduled appointment, refused when the vet or the cat already has an appointment at this time
+func (r *appointmentEntryRepository) Book(entry *AppointmentEntry) (*AppointmentEntry, error) {
+
+	entry.Status = AppointmentScheduled
+
+	// The check and the creation are done together, so two bookings can't take the same time
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkOverlap(tx, entry); err != nil {
+			return err
+		}
+
+		if err := tx.Create(entry).Error; err != nil {
+			return err
+		}
+
+		return saveAuditLog(tx, AuditCreate, "appointment", entry.ID, nil, entry)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (r *appointmentEntryRepository) FindAll(filter AppointmentFilter, page Page) ([]*AppointmentEntry, *PageInfo, error) {
+
+	query := r.db.Model(&AppointmentEntry{})
+
+	if filter.CatId != 0 {
+		query = query.Where("cat_id = ?", filter.CatId)
+	}
+	if filter.Vet != "" {
+		query = query.Where("vet LIKE ?", "%"+filter.Vet+"%")
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if !filter.StartsFrom.IsZero() {
+		query = query.Where("starts_at >= ?", filter.StartsFrom.UTC())
+	}
+	if !filter.StartsBefore.IsZero() {
+		query = query.Where("starts_at < ?", filter.StartsBefore.UTC())
+	}
+	if filter.OwnerId != nil {
+		query = query.Where("cat_id IN (?)", r.db.Model(&CatEntry{}).Select("id").Where("owner_id = ?", *filter.OwnerId))
+	}
+
+	return findPage[AppointmentEntry](query, page)
+}
+
+func (r *appointmentEntryRepository) FindById(id int) (*AppointmentEntry, error) {
+
+	var entries *AppointmentEntry
+	if err := r.db.Model(&AppointmentEntry{}).
+		First(&entries, id).Error; err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// Scheduled appointments overlapping a time range, of every vet when the vet is empty
+func (r *appointmentEntryRepository) FindScheduled(vet string, from time.Time, to time.Time) ([]*AppointmentEntry, error) {
+
+	query := r.db.Where("status = ? AND starts_at < ? AND ends_at > ?", AppointmentScheduled, to.UTC(), from.UTC())
+	if vet != "" {
+		query = query.Where("vet = ?", vet)
+	}
+
+	var entries []*AppointmentEntry
+	if err := query.Order("starts_at").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// Close a scheduled appointment with another status, like cancelled or no_show
+func (r *appointmentEntryRepository) Close(id int, status string) (*AppointmentEntry, error) {
+
+	var current *AppointmentEntry
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if current, err = findScheduled(tx, id); err != nil {
+			return err
+		}
+
+		before := *current
+		current.Status = status
+		if err := tx.Model(current).Update("status", status).Error; err != nil {
+			return err
+		}
+
+		return saveAuditLog(tx, AuditUpdate, "appointment", current.ID, &before, current)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return current, nil
+}
+
+// Replace a scheduled appointment with a new one, the old appointment is kept with the rescheduled status
+func (r *appointmentEntryRepository) Reschedule(id int, entry *AppointmentEntry) (*AppointmentEntry, error) {
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		current, err := findScheduled(tx, id)
+		if err != nil {
+			return err
+		}
+
+		// The old time is freed before checking the new one, the new time may overlap it
+		before := *current
+		if err := tx.Model(current).Update("status", AppointmentRescheduled).Error; err != nil {
+			return err
+		}
+
+		if err := saveAuditLog(tx, AuditUpdate, "appointment", current.ID, &before, current); err != nil {
+			return err
+		}
+
+		entry.Status = AppointmentScheduled
+		entry.RescheduledFromId = &current.ID
+		if err := checkOverlap(tx, entry); err != nil {
+			return err
+		}
+
+		if err := tx.Create(entry).Error; err != nil {
+			return err
+		}
+
+		return saveAuditLog(tx, AuditCreate, "appointment", entry.ID, nil, entry)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// Record the visit of a scheduled appointment, linked to the same cat, and close the appointment as completed
+func (r *appointmentEntryRepository) Complete(id int) (*AppointmentEntry, *VisitEntry, error) {
+
+	var current *AppointmentEntry
+	var visit *VisitEntry
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if current, err = findScheduled(tx, id); err != nil {
+			return err
+		}
+
+		// The cat of the appointment may be in the trash since the booking
+		if err := tx.First(&CatEntry{}, current.CatId).Error; err != nil {
+			return ErrParentDeleted
+		}
+
+		endsAt := current.EndsAt
+		visit = &VisitEntry{CatId: current.CatId, StartsAt: current.StartsAt, EndsAt: &endsAt, Reason: current.Reason, Vet: current.Vet}
+		if err := tx.Create(visit).Error; err != nil {
+			return err
+		}
+
+		if err := saveRevision(tx, VisitRevision, visit.ID, &visit.CatId, RevisionCreate, visit); err != nil {
+			return err
+		}
+
+		if err := saveAuditLog(tx, AuditCreate, "visit", visit.ID, nil, visit); err != nil {
+			return err
+		}
+
+		before := *current
+		current.Status = AppointmentCompleted
+		current.VisitId = &visit.ID
+		if err := tx.Model(current).Updates(map[string]interface{}{"status": current.Status, "visit_id": current.VisitId}).Error; err != nil {
+			return err
+		}
+
+		return saveAuditLog(tx, AuditUpdate, "appointment", current.ID, &before, current)
+	})
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return current, visit, nil
+}
+
+// Find an appointment which can still be changed
+func findScheduled(tx *gorm.DB, id int) (*AppointmentEntry, error) {
+
+	var current *AppointmentEntry
+	if err := tx.First(&current, id).Error; err != nil {
+		return nil, err
+	}
+
+	if current.Status != AppointmentScheduled {
+		return nil, ErrAppointmentClosed
+	}
+
+	return current, nil
+}
+
+// Check that the vet and the cat have no other scheduled appointment during an appointment, the times are compared in UTC
+func checkOverlap(tx *gorm.DB, entry *AppointmentEntry) error {
+
+	var count int64
+	if err := tx.Model(&AppointmentEntry{}).
+		Where("status = ?", AppointmentScheduled).
+		Where("(vet = ? OR cat_id = ?)", entry.Vet, entry.CatId).
+		Where("starts_at < ? AND ends_at > ?", entry.EndsAt.UTC(), entry.StartsAt.UTC()).
+		Count(&count).Error; err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return ErrAppointmentConflict
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package dbmodel_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"vet-clinic-api/database/databasetest"
+	"vet-clinic-api/database/dbmodel"
+)
+
+// An owner account without owner id is scoped to the owner 0, which must match no appointment
+func TestAppointmentOwnerScope(t *testing.T) {
+
+	db := databasetest.Open(t)
+	owners := dbmodel.NewOwnerEntryRepository(db)
+	cats := dbmodel.NewCatEntryRepository(db)
+	appointments := dbmodel.NewAppointmentEntryRepository(db)
+
+	start := time.Date(2030, 3, 18, 9, 0, 0, 0, time.UTC)
+
+	var ownerIds []uint
+	for _, name := range []string{"Alice", "Bob"} {
+		owner, err := owners.Create(&dbmodel.OwnerEntry{Name: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ownerIds = append(ownerIds, owner.ID)
+
+		cat, err := cats.Create(&dbmodel.CatEntry{Name: name + "'s cat", OwnerId: &owner.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := appointments.Book(&dbmodel.AppointmentEntry{CatId: cat.ID, Vet: "Dr " + name, Reason: "Checkup", StartsAt: start, EndsAt: start.Add(30 * time.Minute)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	zero := uint(0)
+	tests := []struct {
+		name    string
+		ownerId *uint
+		want    int64
+	}{
+		{"not scoped", nil, 2},
+		{"scoped to an owner", &ownerIds[0], 1},
+		{"scoped without owner id", &zero, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			_, info, err := appointments.FindAll(dbmodel.AppointmentFilter{OwnerId: tt.ownerId}, dbmodel.Page{Limit: 50})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if info.Total != tt.want {
+				t.Errorf("got %d appointments, want %d", info.Total, tt.want)
+			}
+		})
+	}
+}
+
+// Two scheduled appointments of the same vet or the same cat can't overlap, the end is excluded
+func TestAppointmentOverlap(t *testing.T) {
+
+	db := databasetest.Open(t)
+	cats := dbmodel.NewCatEntryRepository(db)
+	appointments := dbmodel.NewAppointmentEntryRepository(db)
+
+	var catIds []uint
+	for _, name := range []string{"Tom", "Felix"} {
+		cat, err := cats.Create(&dbmodel.CatEntry{Name: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		catIds = append(catIds, cat.ID)
+	}
+
+	start := time.Date(2030, 3, 18, 9, 0, 0, 0, time.UTC)
+	booked, err := appointments.Book(&dbmodel.AppointmentEntry{CatId: catIds[0], Vet: "Dr A", Reason: "Checkup", StartsAt: start, EndsAt: start.Add(30 * time.Minute)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		catId    uint
+		vet      string
+		offset   time.Duration
+		conflict bool
+	}{
+		{"same vet, same time", catIds[1], "Dr A", 0, true},
+		{"same cat, other vet", catIds[0], "Dr B", 15 * time.Minute, true},
+		{"same vet, starting at the end", catIds[1], "Dr A", 30 * time.Minute, false},
+		{"same vet, ending at the start", catIds[1], "Dr A", -30 * time.Minute, false},
+		{"other vet and cat", catIds[1], "Dr B", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			// Each booking is done in its own transaction, rolled back to keep the cases independent
+			tx := db.Begin()
+			defer tx.Rollback()
+
+			entry := &dbmodel.AppointmentEntry{CatId: tt.catId, Vet: tt.vet, Reason: "Checkup", StartsAt: start.Add(tt.offset), EndsAt: start.Add(tt.offset + 30*time.Minute)}
+			_, err := dbmodel.NewAppointmentEntryRepository(tx).Book(entry)
+			if errors.Is(err, dbmodel.ErrAppointmentConflict) != tt.conflict {
+				t.Errorf("got %v, want conflict %t", err, tt.conflict)
+			}
+		})
+	}
+
+	t.Run("reschedule over its own time", func(t *testing.T) {
+		entry := &dbmodel.AppointmentEntry{CatId: catIds[0], Vet: "Dr A", Reason: "Checkup", StartsAt: start.Add(15 * time.Minute), EndsAt: start.Add(45 * time.Minute)}
+		rescheduled, err := appointments.Reschedule(int(booked.ID), entry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rescheduled.RescheduledFromId == nil || *rescheduled.RescheduledFromId != booked.ID {
+			t.Errorf("got rescheduled from %v, want %d", rescheduled.RescheduledFromId, booked.ID)
+		}
+
+		old, err := appointments.FindById(int(booked.ID))
+		if err != nil || old.Status != dbmodel.AppointmentRescheduled {
+			t.Errorf("got %+v (%v), want the old appointment rescheduled", old, err)
+		}
+	})
+
+	t.Run("reschedule a closed appointment", func(t *testing.T) {
+		entry := &dbmodel.AppointmentEntry{CatId: catIds[0], Vet: "Dr A", Reason: "Checkup", StartsAt: start.Add(time.Hour), EndsAt: start.Add(90 * time.Minute)}
+		if _, err := appointments.Reschedule(int(booked.ID), entry); !errors.Is(err, dbmodel.ErrAppointmentClosed) {
+			t.Errorf("got %v, want ErrAppointmentClosed", err)
+		}
+	})
+}
@@ -0,0 +1,308 @@
+package dbmodel
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Actions recorded in the audit log by the repositories
+const (
+	AuditCreate  = "create"
+	AuditUpdate  = "update"
+	AuditDelete  = "delete"
+	AuditRestore = "restore"
+	AuditPurge   = "purge"
+)
+
+// Record of a change made through the API, the table is append-only and every entry is chained to the previous one by its hash
+type AuditLogEntry struct {
+	ID         uint      `json:"id" gorm:"primarykey"`
+	CreatedAt  time.Time `json:"audit_created_at" gorm:"index"`
+	ActorId    *uint     `json:"audit_actor_id" gorm:"index"`
+	ActorEmail string    `json:"audit_actor_email"`
+	Action     string    `json:"audit_action"`
+	Entity     string    `json:"audit_entity" gorm:"index"`
+	EntityId   uint      `json:"audit_entity_id"`
+	Diff       string    `json:"audit_diff"`
+	RequestId  string    `json:"audit_request_id"`
+	IP         string    `json:"audit_ip"`
+	PrevHash   string    `json:"audit_prev_hash" gorm:"uniqueIndex"`
+	Hash       string    `json:"audit_hash" gorm:"uniqueIndex"`
+}
+
+// Request changing the records, given to the repositories with WithContext so they record their changes in the audit log
+type AuditActor struct {
+	ActorId    *uint
+	ActorEmail string
+	RequestId  string
+	IP         string
+
+	// Action recorded instead of the one of the repository, for the changes of the accounts like a password reset
+	Action string
+}
+
+type auditActorKey struct{}
+
+// Return a context recording the changes made with it in the audit log
+func NewAuditContext(ctx context.Context, actor *AuditActor) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, actor)
+}
+
+// Filters of the audit log, the zero values are ignored
+type AuditLogFilter struct {
+	Entity   string
+	EntityId uint
+	ActorId  uint
+	From     time.Time
+	To       time.Time
+}
+
+type AuditLogEntryRepository interface {
+	Append(entry *AuditLogEntry) (*AuditLogEntry, error)
+	FindAll(filter AuditLogFilter, page Page) ([]*AuditLogEntry, *PageInfo, error)
+	Verify() (int64, uint, error)
+}
+
+type auditLogEntryRepository struct {
+	db *gorm.DB
+
+	// Entries are appended one at a time to keep the chain of hashes linear
+	mutex sync.Mutex
+}
+
+func NewAuditLogEntryRepository(db *gorm.DB) AuditLogEntryRepository {
+	return &auditLogEntryRepository{db: db}
+}
+
+// Chain the entry to the last one and save it
+func (r *auditLogEntryRepository) Append(entry *AuditLogEntry) (*AuditLogEntry, error) {
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		return appendAuditLog(tx, entry)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// Record a change in the audit log, in the transaction of the change like its revision.
+// Before and after are the records of the entity, nil for a creation or a deletion, only the changed fields are kept.
+// The changes made without the context of a request, given with NewAuditContext, are not recorded.
+func saveAuditLog(tx *gorm.DB, action string, entity string, id uint, before interface{}, after interface{}) error {
+
+	actor, ok := tx.Statement.Context.Value(auditActorKey{}).(*AuditActor)
+	if !ok {
+		return nil
+	}
+
+	if actor.Action != "" {
+		action = actor.Action
+	}
+
+	diff, err := AuditDiff(before, after)
+	if err != nil {
+		return err
+	}
+
+	return appendAuditLog(tx, &AuditLogEntry{
+		ActorId:    actor.ActorId,
+		ActorEmail: actor.ActorEmail,
+		Action:     action,
+		Entity:     entity,
+		EntityId:   id,
+		Diff:       diff,
+		RequestId:  actor.RequestId,
+		IP:         actor.IP,
+	})
+}
+
+// Chain the entry to the last one and save it, two entries chained to the same one break the unique previous hash
+func appendAuditLog(tx *gorm.DB, entry *AuditLogEntry) error {
+
+	var last AuditLogEntry
+	if err := tx.Order("id DESC").Limit(1).Find(&last).Error; err != nil {
+		return err
+	}
+
+	entry.ID = 0
+	entry.CreatedAt = time.Now().UTC()
+	entry.PrevHash = last.Hash
+	entry.Hash = entry.ComputeHash()
+
+	return tx.Create(entry).Error
+}
+
+func (r *auditLogEntryRepository) FindAll(filter AuditLogFilter, page Page) ([]*AuditLogEntry, *PageInfo, error) {
+
+	query := r.db.Model(&AuditLogEntry{})
+
+	if filter.Entity != "" {
+		query = query.Where("entity = ?", filter.Entity)
+	}
+	if filter.EntityId != 0 {
+		query = query.Where("entity_id = ?", filter.EntityId)
+	}
+	if filter.ActorId != 0 {
+		query = query.Where("actor_id = ?", filter.ActorId)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From.UTC())
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at <= ?", filter.To.UTC())
+	}
+
+	return findPage[AuditLogEntry](query, page)
+}
+
+// Check the chain of hashes, return the number of entries checked and the id of the first altered entry, 0 if none
+func (r *auditLogEntryRepository) Verify() (int64, uint, error) {
+
+	var count int64
+	prevHash := ""
+	var brokenId uint
+
+	var batch []*AuditLogEntry
+	err := r.db.Model(&AuditLogEntry{}).Order("id").FindInBatches(&batch, 500, func(tx *gorm.DB, _ int) error {
+		for _, entry := range batch {
+			count++
+			if entry.PrevHash != prevHash || entry.Hash != entry.ComputeHash() {
+				brokenId = entry.ID
+				return gorm.ErrInvalidData
+			}
+			prevHash = entry.Hash
+		}
+		return nil
+	}).Error
+
+	if brokenId != 0 {
+		return count, brokenId, nil
+	}
+
+	return count, 0, err
+}
+
+// Hash of the content of the entry and of the hash of the previous entry
+func (e *AuditLogEntry) ComputeHash() string {
+
+	content, _ := json.Marshal([]interface{}{
+		e.PrevHash,
+		e.CreatedAt.UTC().Format(time.RFC3339Nano),
+		e.ActorId,
+		e.ActorEmail,
+		e.Action,
+		e.Entity,
+		e.EntityId,
+		e.Diff,
+		e.RequestId,
+		e.IP,
+	})
+
+	sum := sha256.Sum256(content)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// Compare two records by their audited fields, return the changed fields as {"field": {"before": ..., "after": ...}}
+func AuditDiff(before interface{}, after interface{}) (string, error) {
+
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return "{}", err
+	}
+
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return "{}", err
+	}
+
+	changes := map[string]map[string]interface{}{}
+
+	for name, value := range beforeFields {
+		if !reflect.DeepEqual(value, afterFields[name]) {
+			changes[name] = map[string]interface{}{"before": value, "after": afterFields[name]}
+		}
+	}
+
+	for name, value := range afterFields {
+		if _, ok := beforeFields[name]; !ok {
+			changes[name] = map[string]interface{}{"before": nil, "after": value}
+		}
+	}
+
+	diff, err := json.Marshal(changes)
+	if err != nil {
+		return "{}", err
+	}
+
+	return string(diff), nil
+}
+
+// Fields of a record once encoded in JSON, none for nil.
+// The columns of gorm.Model and the fields tagged audit:"-", like the secrets and the associations, are left out.
+func auditFields(record interface{}) (map[string]interface{}, error) {
+
+	fields := map[string]interface{}{}
+
+	value := reflect.ValueOf(record)
+	if !value.IsValid() || (value.Kind() == reflect.Pointer && value.IsNil()) {
+		return fields, nil
+	}
+
+	value = reflect.Indirect(value)
+	if value.Kind() == reflect.Struct {
+		collected := map[string]interface{}{}
+		collectAuditFields(value, collected)
+		record = collected
+	}
+
+	content, err := json.Marshal(record)
+	if err != nil {
+		return fields, err
+	}
+
+	if err := json.Unmarshal(content, &fields); err != nil {
+		return fields, err
+	}
+
+	return fields, nil
+}
+
+func collectAuditFields(value reflect.Value, fields map[string]interface{}) {
+
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() || field.Tag.Get("audit") == "-" {
+			continue
+		}
+
+		// The fields of the embedded structs, like Authorship, are audited with the record
+		if field.Anonymous {
+			if field.Type != reflect.TypeOf(gorm.Model{}) && field.Type.Kind() == reflect.Struct {
+				collectAuditFields(value.Field(i), fields)
+			}
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		fields[name] = value.Field(i).Interface()
+	}
+}
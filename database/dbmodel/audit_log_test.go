@@ -0,0 +1,188 @@
+package dbmodel_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	"vet-clinic-api/database/databasetest"
+	"vet-clinic-api/database/dbmodel"
+
+	"gorm.io/gorm"
+)
+
+// Audit log of a new DB with a chain of 5 entries
+func auditLog(t *testing.T) (*gorm.DB, dbmodel.AuditLogEntryRepository) {
+
+	t.Helper()
+
+	db := databasetest.Open(t)
+	repository := dbmodel.NewAuditLogEntryRepository(db)
+
+	for i := uint(1); i <= 5; i++ {
+		if _, err := repository.Append(&dbmodel.AuditLogEntry{Action: "update", Entity: "cat", EntityId: i, Diff: `{"cat_age":{"after":3,"before":2}}`}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return db, repository
+}
+
+func TestAuditLogAppendOnly(t *testing.T) {
+
+	db, _ := auditLog(t)
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"update", "UPDATE audit_log_entries SET diff = '{}' WHERE id = 3"},
+		{"delete", "DELETE FROM audit_log_entries WHERE id = 3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := db.Exec(tt.query).Error; err == nil {
+				t.Error("got no error, want the change refused by the trigger")
+			}
+		})
+	}
+}
+
+// The triggers are dropped to alter the log like a write outside the API would
+func TestAuditLogVerify(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		queries []string
+		count   int64
+		broken  uint
+	}{
+		{"intact chain", nil, 5, 0},
+		{"altered entry", []string{"UPDATE audit_log_entries SET diff = '{}' WHERE id = 3"}, 3, 3},
+		{"altered entry with a new hash", []string{"UPDATE audit_log_entries SET actor_email = 'someone@example.com', hash = 'forged' WHERE id = 3"}, 3, 3},
+		{"deleted entry", []string{"DELETE FROM audit_log_entries WHERE id = 3"}, 3, 4},
+		{"last entry deleted", []string{"DELETE FROM audit_log_entries WHERE id = 5"}, 4, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			db, repository := auditLog(t)
+			for _, query := range append([]string{
+				"DROP TRIGGER audit_log_entries_no_update",
+				"DROP TRIGGER audit_log_entries_no_delete"}, tt.queries...) {
+				if err := db.Exec(query).Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			count, broken, err := repository.Verify()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != tt.count || broken != tt.broken {
+				t.Errorf("got %d entries checked and entry %d broken, want %d and %d", count, broken, tt.count, tt.broken)
+			}
+		})
+	}
+
+	t.Run("entries appended after a check", func(t *testing.T) {
+		_, repository := auditLog(t)
+		if _, err := repository.Append(&dbmodel.AuditLogEntry{Action: "delete", Entity: "cat", EntityId: 1}); err != nil {
+			t.Fatal(err)
+		}
+
+		if count, broken, err := repository.Verify(); err != nil || count != 6 || broken != 0 {
+			t.Errorf("got %d entries checked and entry %d broken (%v), want 6 intact", count, broken, err)
+		}
+	})
+}
+
+func TestAuditDiff(t *testing.T) {
+
+	type cat struct {
+		gorm.Model
+		Name   string `json:"cat_name"`
+		Age    int    `json:"cat_age"`
+		Owner  *uint  `json:"cat_owner_id"`
+		Secret string `json:"cat_secret" audit:"-"`
+		dbmodel.Authorship
+	}
+
+	var nilCat *cat
+	updatedBy := uint(2)
+	tests := []struct {
+		name   string
+		before interface{}
+		after  interface{}
+		want   string
+	}{
+		{"creation", nil, &cat{Name: "Tom", Age: 2}, `{"cat_age":{"after":2,"before":null},"cat_name":{"after":"Tom","before":null},"cat_owner_id":{"after":null,"before":null},"created_by":{"after":null,"before":null},"updated_by":{"after":null,"before":null}}`},
+		{"deletion with a nil pointer", &cat{Name: "Tom"}, nilCat, `{"cat_age":{"after":null,"before":0},"cat_name":{"after":null,"before":"Tom"}}`},
+		{"changed field only", &cat{Name: "Tom", Age: 2}, &cat{Name: "Tom", Age: 3}, `{"cat_age":{"after":3,"before":2}}`},
+		{"embedded fields", &cat{Name: "Tom"}, &cat{Name: "Tom", Authorship: dbmodel.Authorship{UpdatedBy: &updatedBy}}, `{"updated_by":{"after":2,"before":null}}`},
+		{"gorm.Model and secret left out", &cat{Name: "Tom", Secret: "a"}, &cat{Model: gorm.Model{ID: 1, UpdatedAt: time.Now()}, Name: "Tom", Secret: "b"}, `{}`},
+		{"no change", &cat{Name: "Tom"}, &cat{Name: "Tom"}, `{}`},
+		{"no entity", nil, nil, `{}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := dbmodel.AuditDiff(tt.before, tt.after)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// A role is recorded with its permissions by name
+func TestAuditLogRole(t *testing.T) {
+
+	db := databasetest.Open(t)
+	ctx := dbmodel.NewAuditContext(context.Background(), &dbmodel.AuditActor{})
+	roles := dbmodel.NewRoleEntryRepository(db).WithContext(ctx)
+	permissions := dbmodel.NewPermissionEntryRepository(db)
+
+	read, err := permissions.FindByNames([]string{"cats:read"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	write, err := permissions.FindByNames([]string{"cats:read", "cats:write"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	role, err := roles.Create(&dbmodel.RoleEntry{Name: "intern", Permissions: read})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := roles.Update(int(role.ID), &dbmodel.RoleEntry{Name: "intern", Permissions: write}); err != nil {
+		t.Fatal(err)
+	}
+	if err := roles.DeleteById(int(role.ID)); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, _, err := dbmodel.NewAuditLogEntryRepository(db).FindAll(dbmodel.AuditLogFilter{Entity: "role"}, dbmodel.Page{Limit: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		`create {"role_description":{"after":"","before":null},"role_name":{"after":"intern","before":null},"role_permissions":{"after":["cats:read"],"before":null}}`,
+		`update {"role_permissions":{"after":["cats:read","cats:write"],"before":["cats:read"]}}`,
+		`delete {"role_description":{"after":null,"before":""},"role_name":{"after":null,"before":"intern"},"role_permissions":{"after":null,"before":["cats:read","cats:write"]}}`,
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(want))
+	}
+	for i, entry := range entries {
+		if got := entry.Action + " " + entry.Diff; got != want[i] {
+			t.Errorf("got %s, want %s", got, want[i])
+		}
+	}
+}
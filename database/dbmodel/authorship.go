@@ -0,0 +1,7 @@
+package dbmodel
+
+// Users who created and last updated a record, stamped by the callbacks registered with database.RegisterAuthorship
+type Authorship struct {
+	CreatedBy *uint `json:"created_by" gorm:"index"`
+	UpdatedBy *uint `json:"updated_by"`
+}
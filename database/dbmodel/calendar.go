@@ -0,0 +1,330 @@
+package dbmodel
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Working hours of a vet on a day of the week, in the timezone of the clinic.
+// A day can have several ranges, like a morning and an afternoon.
+type WorkingHoursEntry struct {
+	gorm.Model
+	Vet string `json:"working_hours_vet" gorm:"index"`
+
+	// Day of the week, 0 for Sunday to 6 for Saturday like time.Weekday
+	Weekday int `json:"working_hours_weekday"`
+
+	// Times of the day with the format HH:MM
+	StartTime string `json:"working_hours_start"`
+	EndTime   string `json:"working_hours_end"`
+}
+
+// Time off of a vet, or of the whole clinic when the vet is empty
+type HolidayEntry struct {
+	gorm.Model
+	Vet  string `json:"holiday_vet" gorm:"index"`
+	Name string `json:"holiday_name"`
+
+	// Saved in UTC like the visits
+	StartsAt time.Time `json:"holiday_starts_at" gorm:"index"`
+	EndsAt   time.Time `json:"holiday_ends_at"`
+}
+
+// Duration of the appointments booked for a reason of visit
+type AppointmentReasonEntry struct {
+	gorm.Model
+	Name string `json:"reason_name" gorm:"uniqueIndex"`
+
+	// Duration in minutes
+	Duration int `json:"reason_duration"`
+}
+
+type WorkingHoursEntryRepository interface {
+	WithContext(ctx context.Context) WorkingHoursEntryRepository
+	Create(entry *WorkingHoursEntry) (*WorkingHoursEntry, error)
+	FindAll(vet string) ([]*WorkingHoursEntry, error)
+	FindById(id int) (*WorkingHoursEntry, error)
+	DeleteById(id int) error
+}
+
+type HolidayEntryRepository interface {
+	WithContext(ctx context.Context) HolidayEntryRepository
+	Create(entry *HolidayEntry) (*HolidayEntry, error)
+	FindAll(vet string, from time.Time, to time.Time) ([]*HolidayEntry, error)
+	FindById(id int) (*HolidayEntry, error)
+	DeleteById(id int) error
+}
+
+type AppointmentReasonEntryRepository interface {
+	WithContext(ctx context.Context) AppointmentReasonEntryRepository
+	Create(entry *AppointmentReasonEntry) (*AppointmentReasonEntry, error)
+	FindAll() ([]*AppointmentReasonEntry, error)
+	FindById(id int) (*AppointmentReasonEntry, error)
+	FindByName(name string) (*AppointmentReasonEntry, error)
+	Update(id int, entry *AppointmentReasonEntry) (*AppointmentReasonEntry, error)
+	DeleteById(id int) error
+}
+
+type workingHoursEntryRepository struct {
+	db *gorm.DB
+}
+
+func NewWorkingHoursEntryRepository(db *gorm.DB) WorkingHoursEntryRepository {
+	return &workingHoursEntryRepository{db: db}
+}
+
+// Return a repository running its queries with the request context, used to record the changes in the audit log
+func (r *workingHoursEntryRepository) WithContext(ctx context.Context) WorkingHoursEntryRepository {
+	return &workingHoursEntryRepository{db: r.db.WithContext(ctx)}
+}
+
+func (r *workingHoursEntryRepository) Create(entry *WorkingHoursEntry) (*WorkingHoursEntry, error) {
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(entry).Error; err != nil {
+			return err
+		}
+
+		return saveAuditLog(tx, AuditCreate, "working_hours", entry.ID, nil, entry)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// Working hours of a vet, of every vet when the vet is empty, in the order of the week
+func (r *workingHoursEntryRepository) FindAll(vet string) ([]*WorkingHoursEntry, error) {
+
+	query := r.db.Model(&WorkingHoursEntry{})
+	if vet != "" {
+		query = query.Where("vet = ?", vet)
+	}
+
+	var entries []*WorkingHoursEntry
+	if err := query.Order("vet, weekday, start_time").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (r *workingHoursEntryRepository) FindById(id int) (*WorkingHoursEntry, error) {
+
+	var entries *WorkingHoursEntry
+	if err := r.db.First(&entries, id).Error; err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (r *workingHoursEntryRepository) DeleteById(id int) error {
+
+	// The calendars are removed for good, they have no history
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var before *WorkingHoursEntry
+		if err := tx.First(&before, id).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Delete(&WorkingHoursEntry{}, id).Error; err != nil {
+			return err
+		}
+
+		return saveAuditLog(tx, AuditDelete, "working_hours", before.ID, before, nil)
+	})
+}
+
+type holidayEntryRepository struct {
+	db *gorm.DB
+}
+
+func NewHolidayEntryRepository(db *gorm.DB) HolidayEntryRepository {
+	return &holidayEntryRepository{db: db}
+}
+
+// Return a repository running its queries with the request context, used to record the changes in the audit log
+func (r *holidayEntryRepository) WithContext(ctx context.Context) HolidayEntryRepository {
+	return &holidayEntryRepository{db: r.db.WithContext(ctx)}
+}
+
+func (r *holidayEntryRepository) Create(entry *HolidayEntry) (*HolidayEntry, error) {
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(entry).Error; err != nil {
+			return err
+		}
+
+		return saveAuditLog(tx, AuditCreate, "holiday", entry.ID, nil, entry)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// Holidays overlapping a time range, the zero times are ignored.
+// The holidays of a vet come with the ones of the whole clinic, every holiday is given when the vet is empty.
+func (r *holidayEntryRepository) FindAll(vet string, from time.Time, to time.Time) ([]*HolidayEntry, error) {
+
+	query := r.db.Model(&HolidayEntry{})
+	if vet != "" {
+		query = query.Where("vet = ? OR vet = ''", vet)
+	}
+	if !from.IsZero() {
+		query = query.Where("ends_at > ?", from.UTC())
+	}
+	if !to.IsZero() {
+		query = query.Where("starts_at < ?", to.UTC())
+	}
+
+	var entries []*HolidayEntry
+	if err := query.Order("starts_at").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (r *holidayEntryRepository) FindById(id int) (*HolidayEntry, error) {
+
+	var entries *HolidayEntry
+	if err := r.db.First(&entries, id).Error; err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (r *holidayEntryRepository) DeleteById(id int) error {
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var before *HolidayEntry
+		if err := tx.First(&before, id).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Delete(&HolidayEntry{}, id).Error; err != nil {
+			return err
+		}
+
+		return saveAuditLog(tx, AuditDelete, "holiday", before.ID, before, nil)
+	})
+}
+
+type appointmentReasonEntryRepository struct {
+	db *gorm.DB
+}
+
+func NewAppointmentReasonEntryRepository(db *gorm.DB) AppointmentReasonEntryRepository {
+	return &appointmentReasonEntryRepository{db: db}
+}
+
+// Return a repository running its queries with the request context, used to record the changes in the audit log
+func (r *appointmentReasonEntryRepository) WithContext(ctx context.Context) AppointmentReasonEntryRepository {
+	return &appointmentReasonEntryRepository{db: r.db.WithContext(ctx)}
+}
+
+func (r *appointmentReasonEntryRepository) Create(entry *AppointmentReasonEntry) (*AppointmentReasonEntry, error) {
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(entry).Error; err != nil {
+			return err
+		}
+
+		return saveAuditLog(tx, AuditCreate, "appointment_reason", entry.ID, nil, entry)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (r *appointmentReasonEntryRepository) FindAll() ([]*AppointmentReasonEntry, error) {
+
+	var entries []*AppointmentReasonEntry
+	if err := r.db.Order("name").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (r *appointmentReasonEntryRepository) FindById(id int) (*AppointmentReasonEntry, error) {
+
+	var entries *AppointmentReasonEntry
+	if err := r.db.First(&entries, id).Error; err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// The reasons are matched without case, "Vaccine" has the duration of "vaccine"
+func (r *appointmentReasonEntryRepository) FindByName(name string) (*AppointmentReasonEntry, error) {
+
+	var entries *AppointmentReasonEntry
+	if err := r.db.Where("LOWER(name) = LOWER(?)", name).First(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (r *appointmentReasonEntryRepository) Update(id int, entry *AppointmentReasonEntry) (*AppointmentReasonEntry, error) {
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var before *AppointmentReasonEntry
+		if err := tx.First(&before, id).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&AppointmentReasonEntry{}).
+			Where("id = ?", id).
+			Updates(map[string]interface{}{
+				"name":     entry.Name,
+				"duration": entry.Duration,
+			}).Error; err != nil {
+			return err
+		}
+
+		var after *AppointmentReasonEntry
+		if err := tx.First(&after, id).Error; err != nil {
+			return err
+		}
+
+		return saveAuditLog(tx, AuditUpdate, "appointment_reason", after.ID, before, after)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (r *appointmentReasonEntryRepository) DeleteById(id int) error {
+
+	// Removed for good so the name can be used again
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var before *AppointmentReasonEntry
+		if err := tx.First(&before, id).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Delete(&AppointmentReasonEntry{}, id).Error; err != nil {
+			return err
+		}
+
+		return saveAuditLog(tx, AuditDelete, "appointment_reason", before.ID, before, nil)
+	})
+}
@@ -1,6 +1,9 @@
 package dbmodel
 
 import (
+	"context"
+	"time"
+
 	"gorm.io/gorm"
 )
 
@@ -11,18 +14,38 @@ type CatEntry struct {
 	Breed  string `json:"cat_breed"`
 	Weight int    `json:"cat_weight"`
 
+	//Users who created and last updated the cat
+	Authorship
+
+	//Optional link to the owner of the cat
+	OwnerId *uint       `json:"cat_owner_id"`
+	Owner   *OwnerEntry `json:"owner" audit:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+
 	//Add a foreignKey to CatId on the table Visit, and a Delete On Cascade
-	Visits []VisitEntry `json:"visits" gorm:"foreignKey:CatId; constraint:OnUpdate:CASCADE, OnDelete:CASCADE;"`
+	Visits []VisitEntry `json:"visits" audit:"-" gorm:"foreignKey:CatId; constraint:OnUpdate:CASCADE, OnDelete:CASCADE;"`
+}
+
+// Filters of the cats, the zero values are ignored.
+// The owner is a pointer, so an owner id 0 still scopes the cats and matches none of them.
+type CatFilter struct {
+	Name    string
+	Breed   string
+	OwnerId *uint
 }
 
 type CatEntryRepository interface {
+	WithContext(ctx context.Context) CatEntryRepository
 	Create(entry *CatEntry) (*CatEntry, error)
-	FindAll() ([]*CatEntry, error)
+	FindAll(filter CatFilter, page Page) ([]*CatEntry, *PageInfo, error)
 	FindById(id int) (*CatEntry, error)
 	FindCatHistory(id int) (*CatEntry, error)
 	FindLastCatId(id int) bool
+	BelongsToOwner(id int, ownerId uint) bool
 	Update(id int, entry *CatEntry) (*CatEntry, error)
+	Patch(id int, columns map[string]interface{}) (*CatEntry, error)
 	DeleteById(id int) error
+	Restore(id int) (*CatEntry, error)
+	PurgeDeleted(before time.Time) ([]uint, error)
 }
 
 type catEntryRepository struct {
@@ -33,29 +56,55 @@ func NewCatEntryRepository(db *gorm.DB) CatEntryRepository {
 	return &catEntryRepository{db: db}
 }
 
+// Return a repository running its queries with the request context, used to stamp the user on the changed records
+func (r *catEntryRepository) WithContext(ctx context.Context) CatEntryRepository {
+	return &catEntryRepository{db: r.db.WithContext(ctx)}
+}
+
 func (r *catEntryRepository) Create(entry *CatEntry) (*CatEntry, error) {
 
-	if err := r.db.Create(entry).Error; err != nil {
+	// The record and its first revision are saved together
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(entry).Error; err != nil {
+			return err
+		}
+
+		if err := saveRevision(tx, CatRevision, entry.ID, nil, RevisionCreate, entry); err != nil {
+			return err
+		}
+
+		return saveAuditLog(tx, AuditCreate, "cat", entry.ID, nil, entry)
+	})
+
+	if err != nil {
 		return nil, err
 	}
 
 	return entry, nil
 }
 
-func (r *catEntryRepository) FindAll() ([]*CatEntry, error) {
+func (r *catEntryRepository) FindAll(filter CatFilter, page Page) ([]*CatEntry, *PageInfo, error) {
 
-	var entries []*CatEntry
-	if err := r.db.Find(&entries).Error; err != nil {
-		return nil, err
+	query := r.db.Model(&CatEntry{})
+
+	if filter.Name != "" {
+		query = query.Where("name LIKE ?", "%"+filter.Name+"%")
+	}
+	if filter.Breed != "" {
+		query = query.Where("breed LIKE ?", "%"+filter.Breed+"%")
+	}
+	if filter.OwnerId != nil {
+		query = query.Where("owner_id = ?", *filter.OwnerId)
 	}
 
-	return entries, nil
+	return findPage[CatEntry](query, page, "Owner")
 }
 
 func (r *catEntryRepository) FindById(id int) (*CatEntry, error) {
 
 	var entries *CatEntry
 	if err := r.db.Model(&CatEntry{}).
+		Preload("Owner").
 		Preload("Visits.Treatments").
 		First(&entries, id).Error; err != nil {
 		return nil, err
@@ -66,8 +115,13 @@ func (r *catEntryRepository) FindById(id int) (*CatEntry, error) {
 
 func (r *catEntryRepository) FindCatHistory(id int) (*CatEntry, error) {
 
+	// The visits are given in chronological order
 	var entries *CatEntry
 	if err := r.db.Model(&CatEntry{}).
+		Preload("Owner").
+		Preload("Visits", func(db *gorm.DB) *gorm.DB {
+			return db.Order("starts_at, id")
+		}).
 		Preload("Visits.Treatments").
 		First(&entries, id).Error; err != nil {
 		return nil, err
@@ -84,34 +138,165 @@ func (r *catEntryRepository) FindLastCatId(id int) bool {
 	return count > 0
 }
 
+func (r *catEntryRepository) BelongsToOwner(id int, ownerId uint) bool {
+
+	var count int64
+	r.db.Model(&CatEntry{}).Where("id = ? AND owner_id = ?", id, ownerId).Count(&count)
+
+	return count > 0
+}
+
 func (r *catEntryRepository) Update(id int, entry *CatEntry) (*CatEntry, error) {
 
-	result := r.db.Model(&CatEntry{}).
-		Where("id = ?", id).
-		Updates(map[string]interface{}{
-			"name":   entry.Name,
-			"age":    entry.Age,
-			"breed":  entry.Breed,
-			"weight": entry.Weight,
-		})
-
-	if result.Error != nil {
-		return nil, result.Error
+	if _, err := r.Patch(id, map[string]interface{}{
+		"name":     entry.Name,
+		"age":      entry.Age,
+		"breed":    entry.Breed,
+		"weight":   entry.Weight,
+		"owner_id": entry.OwnerId,
+	}); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// Update only the given columns, for the partial updates
+func (r *catEntryRepository) Patch(id int, columns map[string]interface{}) (*CatEntry, error) {
+
+	var current *CatEntry
+
+	// The new values are saved in a revision, the old ones stay in the previous revisions
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var before *CatEntry
+		if err := tx.First(&before, id).Error; err != nil {
+			return err
+		}
+
+		result := tx.Model(&CatEntry{}).
+			Where("id = ?", id).
+			Updates(columns)
+
+		if result.Error != nil {
+			return result.Error
+		}
+
+		// Check if something has been update
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.First(&current, id).Error; err != nil {
+			return err
+		}
+
+		if err := saveRevision(tx, CatRevision, current.ID, nil, RevisionUpdate, current); err != nil {
+			return err
+		}
+
+		return saveAuditLog(tx, AuditUpdate, "cat", current.ID, before, current)
+	})
+
+	if err != nil {
+		return nil, err
 	}
 
-	// Check if something has been update
-	if result.RowsAffected == 0 {
-		return nil, gorm.ErrRecordNotFound
+	return current, nil
+}
+
+func (r *catEntryRepository) DeleteById(id int) error {
+
+	// The deleted record is kept in its last revision, its visits and treatments are deleted with it
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var current *CatEntry
+		if err := tx.First(&current, id).Error; err != nil {
+			return err
+		}
+
+		deletedAt := time.Now().UTC()
+		if err := softDelete(tx, &CatEntry{}, current.ID, deletedAt); err != nil {
+			return err
+		}
+
+		if err := saveRevision(tx, CatRevision, current.ID, nil, RevisionDelete, current); err != nil {
+			return err
+		}
+
+		if err := saveAuditLog(tx, AuditDelete, "cat", current.ID, current, nil); err != nil {
+			return err
+		}
+
+		return deleteVisitsOf(tx, []uint{current.ID}, deletedAt)
+	})
+}
+
+// Take a cat out of the trash with the visits and treatments deleted with it
+func (r *catEntryRepository) Restore(id int) (*CatEntry, error) {
+
+	var entry *CatEntry
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("deleted_at IS NOT NULL").First(&entry, id).Error; err != nil {
+			return err
+		}
+
+		deletedAt := entry.DeletedAt.Time
+		if err := restore(tx, entry, entry.ID); err != nil {
+			return err
+		}
+
+		if err := saveRevision(tx, CatRevision, entry.ID, nil, RevisionRestore, entry); err != nil {
+			return err
+		}
+
+		if err := saveAuditLog(tx, AuditRestore, "cat", entry.ID, nil, entry); err != nil {
+			return err
+		}
+
+		return restoreVisitsOf(tx, entry.ID, deletedAt)
+	})
+
+	if err != nil {
+		return nil, err
 	}
 
 	return entry, nil
 }
 
-func (r *catEntryRepository) DeleteById(id int) error {
+// Permanently delete the cats in the trash since before a given time with their visits and treatments, return their ids
+func (r *catEntryRepository) PurgeDeleted(before time.Time) ([]uint, error) {
 
-	if err := r.db.Delete(&CatEntry{}, id).Error; err != nil {
-		return err
+	var ids []uint
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if ids, err = deletedBefore(tx, &CatEntry{}, before); err != nil || len(ids) == 0 {
+			return err
+		}
+
+		visitIds := tx.Unscoped().Model(&VisitEntry{}).Select("id").Where("cat_id IN ?", ids)
+		if err := tx.Unscoped().Where("visit_id IN (?)", visitIds).Delete(&TreatmentEntry{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("cat_id IN ?", ids).Delete(&VisitEntry{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Delete(&CatEntry{}, ids).Error; err != nil {
+			return err
+		}
+
+		for _, id := range ids {
+			if err := saveAuditLog(tx, AuditPurge, "cat", id, nil, nil); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return ids, nil
 }
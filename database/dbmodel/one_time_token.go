@@ -0,0 +1,96 @@
+package dbmodel
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Purposes of the one-time tokens sent by email
+const (
+	PasswordResetToken = "password_reset"
+	InvitationToken    = "invitation"
+)
+
+// Single-use token sent by email, only its hash is saved
+type OneTimeTokenEntry struct {
+	gorm.Model
+	TokenHash string     `json:"one_time_token_hash" gorm:"uniqueIndex"`
+	Purpose   string     `json:"one_time_token_purpose"`
+	UserId    uint       `json:"one_time_token_user_id" gorm:"index"`
+	ExpiresAt time.Time  `json:"one_time_token_expires_at"`
+	UsedAt    *time.Time `json:"one_time_token_used_at"`
+}
+
+type OneTimeTokenEntryRepository interface {
+	Create(entry *OneTimeTokenEntry) (*OneTimeTokenEntry, error)
+	FindValid(tokenHash string, purpose string) (*OneTimeTokenEntry, error)
+	Consume(tokenHash string, purpose string) (*OneTimeTokenEntry, error)
+	DeleteByUserId(userId uint, purpose string) error
+}
+
+type oneTimeTokenEntryRepository struct {
+	db *gorm.DB
+}
+
+func NewOneTimeTokenEntryRepository(db *gorm.DB) OneTimeTokenEntryRepository {
+	return &oneTimeTokenEntryRepository{db: db}
+}
+
+func (r *oneTimeTokenEntryRepository) Create(entry *OneTimeTokenEntry) (*OneTimeTokenEntry, error) {
+
+	entry.ExpiresAt = entry.ExpiresAt.UTC()
+
+	if err := r.db.Create(entry).Error; err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// Find a token not used and not expired
+func (r *oneTimeTokenEntryRepository) FindValid(tokenHash string, purpose string) (*OneTimeTokenEntry, error) {
+
+	var entries *OneTimeTokenEntry
+	if err := r.db.Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", tokenHash, purpose, time.Now().UTC()).
+		First(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// Mark a valid token as used and return it, a token can only be consumed once before its expiration
+func (r *oneTimeTokenEntryRepository) Consume(tokenHash string, purpose string) (*OneTimeTokenEntry, error) {
+
+	now := time.Now().UTC()
+
+	result := r.db.Model(&OneTimeTokenEntry{}).
+		Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", tokenHash, purpose, now).
+		Update("used_at", now)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	var entries *OneTimeTokenEntry
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// Remove the tokens of a user for a purpose, a new token replaces the previous ones
+func (r *oneTimeTokenEntryRepository) DeleteByUserId(userId uint, purpose string) error {
+
+	if err := r.db.Unscoped().Where("user_id = ? AND purpose = ?", userId, purpose).Delete(&OneTimeTokenEntry{}).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
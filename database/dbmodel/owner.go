@@ -0,0 +1,158 @@
+package dbmodel
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type OwnerEntry struct {
+	gorm.Model
+	Name    string `json:"owner_name"`
+	Phone   string `json:"owner_phone"`
+	Email   string `json:"owner_email"`
+	Address string `json:"owner_address"`
+
+	//Add a foreignKey to OwnerId on the table Cat, and set it to NULL when the owner is deleted
+	Cats []CatEntry `json:"cats" audit:"-" gorm:"foreignKey:OwnerId;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+}
+
+// Filters of the owners, the zero values are ignored
+type OwnerFilter struct {
+	Id    *uint
+	Name  string
+	Email string
+}
+
+type OwnerEntryRepository interface {
+	WithContext(ctx context.Context) OwnerEntryRepository
+	Create(entry *OwnerEntry) (*OwnerEntry, error)
+	FindAll(filter OwnerFilter, page Page) ([]*OwnerEntry, *PageInfo, error)
+	FindById(id int) (*OwnerEntry, error)
+	FindLastOwnerId(id int) bool
+	Update(id int, entry *OwnerEntry) (*OwnerEntry, error)
+	DeleteById(id int) error
+}
+
+type ownerEntryRepository struct {
+	db *gorm.DB
+}
+
+func NewOwnerEntryRepository(db *gorm.DB) OwnerEntryRepository {
+	return &ownerEntryRepository{db: db}
+}
+
+// Return a repository running its queries with the request context, used to record the changes in the audit log
+func (r *ownerEntryRepository) WithContext(ctx context.Context) OwnerEntryRepository {
+	return &ownerEntryRepository{db: r.db.WithContext(ctx)}
+}
+
+func (r *ownerEntryRepository) Create(entry *OwnerEntry) (*OwnerEntry, error) {
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(entry).Error; err != nil {
+			return err
+		}
+
+		return saveAuditLog(tx, AuditCreate, "owner", entry.ID, nil, entry)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (r *ownerEntryRepository) FindAll(filter OwnerFilter, page Page) ([]*OwnerEntry, *PageInfo, error) {
+
+	query := r.db.Model(&OwnerEntry{})
+
+	if filter.Id != nil {
+		query = query.Where("id = ?", *filter.Id)
+	}
+	if filter.Name != "" {
+		query = query.Where("name LIKE ?", "%"+filter.Name+"%")
+	}
+	if filter.Email != "" {
+		query = query.Where("email LIKE ?", "%"+filter.Email+"%")
+	}
+
+	return findPage[OwnerEntry](query, page)
+}
+
+func (r *ownerEntryRepository) FindById(id int) (*OwnerEntry, error) {
+
+	var entries *OwnerEntry
+	if err := r.db.Model(&OwnerEntry{}).
+		Preload("Cats").
+		First(&entries, id).Error; err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (r *ownerEntryRepository) FindLastOwnerId(id int) bool {
+
+	var count int64
+	r.db.Model(&OwnerEntry{}).Where("id = ?", id).Count(&count)
+
+	return count > 0
+}
+
+func (r *ownerEntryRepository) Update(id int, entry *OwnerEntry) (*OwnerEntry, error) {
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var before *OwnerEntry
+		if err := tx.First(&before, id).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&OwnerEntry{}).
+			Where("id = ?", id).
+			Updates(map[string]interface{}{
+				"name":    entry.Name,
+				"phone":   entry.Phone,
+				"email":   entry.Email,
+				"address": entry.Address,
+			}).Error; err != nil {
+			return err
+		}
+
+		var after *OwnerEntry
+		if err := tx.First(&after, id).Error; err != nil {
+			return err
+		}
+
+		return saveAuditLog(tx, AuditUpdate, "owner", after.ID, before, after)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (r *ownerEntryRepository) DeleteById(id int) error {
+
+	// Soft delete doesn't trigger the SET NULL constraint, so the cats are unlinked by hand
+	return r.db.Transaction(func(tx *gorm.DB) error {
+
+		var before *OwnerEntry
+		if err := tx.First(&before, id).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&CatEntry{}).Where("owner_id = ?", id).Update("owner_id", nil).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&OwnerEntry{}, id).Error; err != nil {
+			return err
+		}
+
+		return saveAuditLog(tx, AuditDelete, "owner", before.ID, before, nil)
+	})
+}
@@ -0,0 +1,80 @@
+package dbmodel_test
+
+import (
+	"testing"
+	"time"
+	"vet-clinic-api/database/databasetest"
+	"vet-clinic-api/database/dbmodel"
+)
+
+// An owner account without owner id is scoped to the owner 0, which must match nothing instead of every record
+func TestOwnerScope(t *testing.T) {
+
+	db := databasetest.Open(t)
+	owners := dbmodel.NewOwnerEntryRepository(db)
+	cats := dbmodel.NewCatEntryRepository(db)
+	visits := dbmodel.NewVisitEntryRepository(db)
+	treatments := dbmodel.NewTreatmentEntryRepository(db)
+
+	// Two owners with a cat, a visit and a treatment each
+	var ownerIds []uint
+	for _, name := range []string{"Alice", "Bob"} {
+		owner, err := owners.Create(&dbmodel.OwnerEntry{Name: name, Email: name + "@example.com"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ownerIds = append(ownerIds, owner.ID)
+
+		cat, err := cats.Create(&dbmodel.CatEntry{Name: name + "'s cat", OwnerId: &owner.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		visit, err := visits.Create(&dbmodel.VisitEntry{CatId: cat.ID, StartsAt: time.Now().UTC(), Reason: "Checkup", Vet: "Dr A"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := treatments.Create(&dbmodel.TreatmentEntry{VisitId: visit.ID, Name: "Vaccine"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	zero := uint(0)
+	tests := []struct {
+		name    string
+		ownerId *uint
+		want    int64
+	}{
+		{"not scoped", nil, 2},
+		{"scoped to an owner", &ownerIds[0], 1},
+		{"scoped without owner id", &zero, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			page := dbmodel.Page{Limit: 50}
+
+			_, info, err := cats.FindAll(dbmodel.CatFilter{OwnerId: tt.ownerId}, page)
+			if err != nil || info.Total != tt.want {
+				t.Errorf("cats: got %v (%v), want %d", info, err, tt.want)
+			}
+
+			_, info, err = owners.FindAll(dbmodel.OwnerFilter{Id: tt.ownerId}, page)
+			if err != nil || info.Total != tt.want {
+				t.Errorf("owners: got %v (%v), want %d", info, err, tt.want)
+			}
+
+			_, info, err = visits.FindAll(dbmodel.VisitQuery{OwnerId: tt.ownerId}, page)
+			if err != nil || info.Total != tt.want {
+				t.Errorf("visits: got %v (%v), want %d", info, err, tt.want)
+			}
+
+			_, info, err = treatments.FindAll(dbmodel.TreatmentFilter{OwnerId: tt.ownerId}, page)
+			if err != nil || info.Total != tt.want {
+				t.Errorf("treatments: got %v (%v), want %d", info, err, tt.want)
+			}
+		})
+	}
+}
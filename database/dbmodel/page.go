@@ -0,0 +1,177 @@
+package dbmodel
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Error of a page requested with a cursor which doesn't match its sort
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Sort of a list on a column
+type Sort struct {
+	Column string
+	Desc   bool
+}
+
+// Page of a list, from the offset or after the cursor of the previous page.
+// The records are sorted by the sort columns then by id, the sort columns must not be null.
+type Page struct {
+	Limit  int
+	Offset int
+	Cursor string
+	Sort   []Sort
+}
+
+// Informations of a page
+type PageInfo struct {
+	// Number of records matching the filters, in every page
+	Total int64
+
+	// Cursor of the next page, empty for the last page
+	NextCursor string
+}
+
+// Find a page of the records of the query with the total count of the records
+func findPage[T any](query *gorm.DB, page Page, preloads ...string) ([]*T, *PageInfo, error) {
+
+	// The query is reused for the count and the records
+	query = query.Session(&gorm.Session{})
+
+	info := &PageInfo{}
+	if err := query.Count(&info.Total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	statement := &gorm.Statement{DB: query}
+	if err := statement.Parse(new(T)); err != nil {
+		return nil, nil, err
+	}
+
+	// The id is the last sort column to keep the order stable
+	sorts := page.Sort
+	if len(sorts) == 0 || sorts[len(sorts)-1].Column != "id" {
+		sorts = append(sorts[:len(sorts):len(sorts)], Sort{Column: "id"})
+	}
+
+	fields := make([]*schema.Field, len(sorts))
+	for i, sort := range sorts {
+		if fields[i] = statement.Schema.LookUpField(sort.Column); fields[i] == nil {
+			return nil, nil, fmt.Errorf("unknown sort column %s", sort.Column)
+		}
+	}
+
+	if page.Cursor != "" {
+		values, err := decodeCursor(page.Cursor, fields)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		query = query.Where(after(sorts, values))
+	} else {
+		query = query.Offset(page.Offset)
+	}
+
+	for _, sort := range sorts {
+		query = query.Order(clause.OrderByColumn{Column: column(sort), Desc: sort.Desc})
+	}
+
+	for _, preload := range preloads {
+		query = query.Preload(preload)
+	}
+
+	// One more record is read to know if there is a next page
+	var entries []*T
+	if err := query.Limit(page.Limit + 1).Find(&entries).Error; err != nil {
+		return nil, nil, err
+	}
+
+	if len(entries) > page.Limit {
+		entries = entries[:page.Limit]
+
+		cursor, err := encodeCursor(query.Statement.Context, entries[len(entries)-1], fields)
+		if err != nil {
+			return nil, nil, err
+		}
+		info.NextCursor = cursor
+	}
+
+	return entries, info, nil
+}
+
+// Condition of the records after the values of the sort columns, for a sort on a, b:
+// a > va OR (a = va AND b > vb)
+func after(sorts []Sort, values []interface{}) clause.Expression {
+
+	var conditions []clause.Expression
+	for i, sort := range sorts {
+		var expressions []clause.Expression
+		for j := 0; j < i; j++ {
+			expressions = append(expressions, clause.Eq{Column: column(sorts[j]), Value: values[j]})
+		}
+
+		if sort.Desc {
+			expressions = append(expressions, clause.Lt{Column: column(sort), Value: values[i]})
+		} else {
+			expressions = append(expressions, clause.Gt{Column: column(sort), Value: values[i]})
+		}
+
+		conditions = append(conditions, clause.And(expressions...))
+	}
+
+	return clause.Or(conditions...)
+}
+
+// Column of the sort, in the table of the query when other tables are joined
+func column(sort Sort) clause.Column {
+	return clause.Column{Table: clause.CurrentTable, Name: sort.Column}
+}
+
+// The cursor holds the values of the sort columns of the last record of a page
+func encodeCursor(ctx context.Context, entry interface{}, fields []*schema.Field) (string, error) {
+
+	values := make([]interface{}, len(fields))
+	for i, field := range fields {
+		values[i], _ = field.ValueOf(ctx, reflect.ValueOf(entry).Elem())
+	}
+
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(encoded), nil
+}
+
+// Values of the cursor, decoded with the types of the sort columns
+func decodeCursor(cursor string, fields []*schema.Field) ([]interface{}, error) {
+
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var encoded []json.RawMessage
+	if err := json.Unmarshal(decoded, &encoded); err != nil || len(encoded) != len(fields) {
+		return nil, ErrInvalidCursor
+	}
+
+	values := make([]interface{}, len(fields))
+	for i, field := range fields {
+		value := reflect.New(field.FieldType)
+		if err := json.Unmarshal(encoded[i], value.Interface()); err != nil {
+			return nil, ErrInvalidCursor
+		}
+		values[i] = value.Elem().Interface()
+	}
+
+	return values, nil
+}
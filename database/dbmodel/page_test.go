@@ -0,0 +1,187 @@
+package dbmodel_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+	"vet-clinic-api/database/databasetest"
+	"vet-clinic-api/database/dbmodel"
+)
+
+// Read every page of a list by following the cursors, and check no record is read twice
+func readPages[T any](t *testing.T, find func(page dbmodel.Page) ([]*T, *dbmodel.PageInfo, error), page dbmodel.Page, key func(*T) string) []string {
+
+	t.Helper()
+
+	var keys []string
+	seen := map[string]bool{}
+	for {
+		entries, info, err := find(page)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, entry := range entries {
+			if seen[key(entry)] {
+				t.Fatalf("record %s read twice", key(entry))
+			}
+			seen[key(entry)] = true
+			keys = append(keys, key(entry))
+		}
+
+		if info.NextCursor == "" {
+			return keys
+		}
+		page.Cursor = info.NextCursor
+	}
+}
+
+func TestFindPage(t *testing.T) {
+
+	db := databasetest.Open(t)
+	cats := dbmodel.NewCatEntryRepository(db)
+
+	// Ages with ties, so the id breaks them
+	for i, age := range []int{3, 1, 3, 2, 3, 1, 2} {
+		if _, err := cats.Create(&dbmodel.CatEntry{Name: fmt.Sprintf("cat%d", i+1), Age: age}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	find := func(page dbmodel.Page) ([]*dbmodel.CatEntry, *dbmodel.PageInfo, error) {
+		return cats.FindAll(dbmodel.CatFilter{}, page)
+	}
+	name := func(cat *dbmodel.CatEntry) string { return cat.Name }
+
+	tests := []struct {
+		name string
+		page dbmodel.Page
+		want string
+	}{
+		{"by id", dbmodel.Page{Limit: 3}, "[cat1 cat2 cat3 cat4 cat5 cat6 cat7]"},
+		{"by age then id", dbmodel.Page{Limit: 2, Sort: []dbmodel.Sort{{Column: "age"}}}, "[cat2 cat6 cat4 cat7 cat1 cat3 cat5]"},
+		{"by descending age then id", dbmodel.Page{Limit: 2, Sort: []dbmodel.Sort{{Column: "age", Desc: true}}}, "[cat1 cat3 cat5 cat4 cat7 cat2 cat6]"},
+		{"single page", dbmodel.Page{Limit: 50}, "[cat1 cat2 cat3 cat4 cat5 cat6 cat7]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fmt.Sprint(readPages(t, find, tt.page, name)); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("offset", func(t *testing.T) {
+		entries, info, err := find(dbmodel.Page{Limit: 2, Offset: 5})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Total != 7 || len(entries) != 2 || entries[0].Name != "cat6" {
+			t.Errorf("got %d cats from %v of %d, want cat6 and cat7 of 7", len(entries), entries, info.Total)
+		}
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		_, _, err := find(dbmodel.Page{Limit: 2, Cursor: "not a cursor"})
+		if !errors.Is(err, dbmodel.ErrInvalidCursor) {
+			t.Errorf("got %v, want ErrInvalidCursor", err)
+		}
+	})
+
+	t.Run("cursor of another sort", func(t *testing.T) {
+		_, info, err := find(dbmodel.Page{Limit: 2, Sort: []dbmodel.Sort{{Column: "age"}}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, _, err = find(dbmodel.Page{Limit: 2, Cursor: info.NextCursor})
+		if !errors.Is(err, dbmodel.ErrInvalidCursor) {
+			t.Errorf("got %v, want ErrInvalidCursor", err)
+		}
+	})
+}
+
+// The cats, visits and treatments deleted together share their deletion time and often their id
+func TestTrashPages(t *testing.T) {
+
+	db := databasetest.Open(t)
+	cats := dbmodel.NewCatEntryRepository(db)
+	visits := dbmodel.NewVisitEntryRepository(db)
+	treatments := dbmodel.NewTreatmentEntryRepository(db)
+
+	for i := 0; i < 3; i++ {
+		cat, err := cats.Create(&dbmodel.CatEntry{Name: fmt.Sprintf("cat%d", i+1)})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		visit, err := visits.Create(&dbmodel.VisitEntry{CatId: cat.ID, StartsAt: time.Now().UTC(), Reason: "Checkup"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := treatments.Create(&dbmodel.TreatmentEntry{VisitId: visit.ID, Name: "Vaccine"}); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := cats.DeleteById(int(cat.ID)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	trash := dbmodel.NewTrashEntryRepository(db)
+	key := func(entry *dbmodel.TrashEntry) string { return entry.ID }
+
+	tests := []struct {
+		entity string
+		want   int
+	}{
+		{"", 9},
+		{"cat", 3},
+		{"visit", 3},
+		{"treatment", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run("entity "+tt.entity, func(t *testing.T) {
+			find := func(page dbmodel.Page) ([]*dbmodel.TrashEntry, *dbmodel.PageInfo, error) {
+				return trash.FindAll(tt.entity, page)
+			}
+
+			if got := readPages(t, find, dbmodel.Page{Limit: 2}, key); len(got) != tt.want {
+				t.Errorf("got %d records %v, want %d", len(got), got, tt.want)
+			}
+		})
+	}
+
+	t.Run("last deleted first", func(t *testing.T) {
+		entries, _, err := trash.FindAll("cat", dbmodel.Page{Limit: 50})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 3 || entries[0].Name != "cat3" || entries[0].DeletedAt.IsZero() {
+			t.Errorf("got %+v, want cat3 first", entries)
+		}
+	})
+}
+
+func TestRolePages(t *testing.T) {
+
+	roles := dbmodel.NewRoleEntryRepository(databasetest.Open(t))
+	name := func(role *dbmodel.RoleEntry) string { return role.Name }
+
+	got := readPages(t, roles.FindAll, dbmodel.Page{Limit: 2, Sort: []dbmodel.Sort{{Column: "name"}}}, name)
+	if fmt.Sprint(got) != "[admin owner receptionist technician vet]" {
+		t.Errorf("got %v, want the default roles by name", got)
+	}
+
+	entries, _, err := roles.FindAll(dbmodel.Page{Limit: 1, Sort: []dbmodel.Sort{{Column: "name"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries[0].Permissions) == 0 {
+		t.Error("got no permission, want the permissions of the role")
+	}
+}
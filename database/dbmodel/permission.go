@@ -0,0 +1,54 @@
+package dbmodel
+
+import (
+	"gorm.io/gorm"
+)
+
+type PermissionEntry struct {
+	gorm.Model
+	Name        string `json:"permission_name" gorm:"uniqueIndex"`
+	Description string `json:"permission_description"`
+}
+
+type PermissionEntryRepository interface {
+	Create(entry *PermissionEntry) (*PermissionEntry, error)
+	FindAll() ([]*PermissionEntry, error)
+	FindByNames(names []string) ([]PermissionEntry, error)
+}
+
+type permissionEntryRepository struct {
+	db *gorm.DB
+}
+
+func NewPermissionEntryRepository(db *gorm.DB) PermissionEntryRepository {
+	return &permissionEntryRepository{db: db}
+}
+
+func (r *permissionEntryRepository) Create(entry *PermissionEntry) (*PermissionEntry, error) {
+
+	if err := r.db.Create(entry).Error; err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (r *permissionEntryRepository) FindAll() ([]*PermissionEntry, error) {
+
+	var entries []*PermissionEntry
+	if err := r.db.Order("name").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (r *permissionEntryRepository) FindByNames(names []string) ([]PermissionEntry, error) {
+
+	var entries []PermissionEntry
+	if err := r.db.Where("name IN ?", names).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
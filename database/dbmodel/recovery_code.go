@@ -0,0 +1,79 @@
+package dbmodel
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type RecoveryCodeEntry struct {
+	gorm.Model
+	UserId   uint   `json:"recovery_code_user_id" gorm:"index"`
+	CodeHash string `json:"recovery_code_hash" gorm:"index"`
+	Used     bool   `json:"recovery_code_used"`
+}
+
+type RecoveryCodeEntryRepository interface {
+	WithContext(ctx context.Context) RecoveryCodeEntryRepository
+	ReplaceByUserId(userId uint, codeHashes []string) error
+	UseCode(userId uint, codeHash string) (bool, error)
+	DeleteByUserId(userId uint) error
+}
+
+type recoveryCodeEntryRepository struct {
+	db *gorm.DB
+}
+
+func NewRecoveryCodeEntryRepository(db *gorm.DB) RecoveryCodeEntryRepository {
+	return &recoveryCodeEntryRepository{db: db}
+}
+
+// Return a repository running its queries with the request context, used to record the changes in the audit log
+func (r *recoveryCodeEntryRepository) WithContext(ctx context.Context) RecoveryCodeEntryRepository {
+	return &recoveryCodeEntryRepository{db: r.db.WithContext(ctx)}
+}
+
+// Replace every recovery code of a user by the new ones, the codes are never recorded in the audit log
+func (r *recoveryCodeEntryRepository) ReplaceByUserId(userId uint, codeHashes []string) error {
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+
+		if err := tx.Unscoped().Where("user_id = ?", userId).Delete(&RecoveryCodeEntry{}).Error; err != nil {
+			return err
+		}
+
+		var entries []RecoveryCodeEntry
+		for _, codeHash := range codeHashes {
+			entries = append(entries, RecoveryCodeEntry{UserId: userId, CodeHash: codeHash})
+		}
+
+		if err := tx.Create(&entries).Error; err != nil {
+			return err
+		}
+
+		return saveAuditLog(tx, AuditUpdate, "user", userId, nil, nil)
+	})
+}
+
+// Mark a recovery code as used, return false if the code doesn't exist or was already used
+func (r *recoveryCodeEntryRepository) UseCode(userId uint, codeHash string) (bool, error) {
+
+	result := r.db.Model(&RecoveryCodeEntry{}).
+		Where("user_id = ? AND code_hash = ? AND used = ?", userId, codeHash, false).
+		Update("used", true)
+
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *recoveryCodeEntryRepository) DeleteByUserId(userId uint) error {
+
+	if err := r.db.Unscoped().Where("user_id = ?", userId).Delete(&RecoveryCodeEntry{}).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package dbmodel
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type RefreshTokenEntry struct {
+	gorm.Model
+	Jti       string    `json:"refresh_token_jti" gorm:"uniqueIndex"`
+	FamilyId  string    `json:"refresh_token_family_id" gorm:"index"`
+	UserId    uint      `json:"refresh_token_user_id" gorm:"index"`
+	ExpiresAt time.Time `json:"refresh_token_expires_at"`
+	Revoked   bool      `json:"refresh_token_revoked"`
+}
+
+type RefreshTokenEntryRepository interface {
+	WithContext(ctx context.Context) RefreshTokenEntryRepository
+	Create(entry *RefreshTokenEntry) (*RefreshTokenEntry, error)
+	FindByJti(jti string) (*RefreshTokenEntry, error)
+	RevokeByJti(jti string) (bool, error)
+	RevokeFamily(familyId string) error
+	RevokeByUserId(userId uint) error
+	RevokeOtherFamilies(userId uint, familyId string) error
+}
+
+type refreshTokenEntryRepository struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenEntryRepository(db *gorm.DB) RefreshTokenEntryRepository {
+	return &refreshTokenEntryRepository{db: db}
+}
+
+// Return a repository running its queries with the request context, used to record the changes in the audit log
+func (r *refreshTokenEntryRepository) WithContext(ctx context.Context) RefreshTokenEntryRepository {
+	return &refreshTokenEntryRepository{db: r.db.WithContext(ctx)}
+}
+
+func (r *refreshTokenEntryRepository) Create(entry *RefreshTokenEntry) (*RefreshTokenEntry, error) {
+
+	if err := r.db.Create(entry).Error; err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (r *refreshTokenEntryRepository) FindByJti(jti string) (*RefreshTokenEntry, error) {
+
+	var entries *RefreshTokenEntry
+	if err := r.db.Where("jti = ?", jti).First(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// Revoke a single token, return false if the token was already revoked
+func (r *refreshTokenEntryRepository) RevokeByJti(jti string) (bool, error) {
+
+	result := r.db.Model(&RefreshTokenEntry{}).
+		Where("jti = ? AND revoked = ?", jti, false).
+		Update("revoked", true)
+
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *refreshTokenEntryRepository) RevokeFamily(familyId string) error {
+
+	if err := r.db.Model(&RefreshTokenEntry{}).
+		Where("family_id = ?", familyId).
+		Update("revoked", true).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *refreshTokenEntryRepository) RevokeByUserId(userId uint) error {
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&RefreshTokenEntry{}).
+			Where("user_id = ?", userId).
+			Update("revoked", true).Error; err != nil {
+			return err
+		}
+
+		return saveAuditLog(tx, AuditUpdate, "user", userId, nil, nil)
+	})
+}
+
+// Revoke every session of a user but one
+func (r *refreshTokenEntryRepository) RevokeOtherFamilies(userId uint, familyId string) error {
+
+	if err := r.db.Model(&RefreshTokenEntry{}).
+		Where("user_id = ? AND family_id <> ?", userId, familyId).
+		Update("revoked", true).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
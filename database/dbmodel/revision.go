@@ -0,0 +1,199 @@
+package dbmodel
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Entities having revisions
+const (
+	CatRevision       = "cat"
+	VisitRevision     = "visit"
+	TreatmentRevision = "treatment"
+)
+
+// Actions of the revisions
+const (
+	RevisionCreate  = "create"
+	RevisionUpdate  = "update"
+	RevisionDelete  = "delete"
+	RevisionRestore = "restore"
+)
+
+// Snapshot of a cat, visit or treatment saved by the repositories on every change, used to find a record as it was at a given time
+type RevisionEntry struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"revision_created_at" gorm:"index"`
+	Entity    string    `json:"revision_entity" gorm:"index:idx_revision_entity"`
+	EntityId  uint      `json:"revision_entity_id" gorm:"index:idx_revision_entity"`
+
+	// Cat of a visit or visit of a treatment when the revision was saved
+	ParentId *uint `json:"revision_parent_id" gorm:"index"`
+
+	Action   string `json:"revision_action"`
+	Snapshot string `json:"revision_snapshot"`
+
+	// User who made the change, stamped like the Authorship of the records
+	CreatedBy *uint `json:"revision_created_by"`
+}
+
+type RevisionEntryRepository interface {
+	FindByEntity(entity string, id uint) ([]*RevisionEntry, error)
+	FindCatAsOf(id uint, at time.Time) (*CatEntry, error)
+}
+
+type revisionEntryRepository struct {
+	db *gorm.DB
+}
+
+func NewRevisionEntryRepository(db *gorm.DB) RevisionEntryRepository {
+	return &revisionEntryRepository{db: db}
+}
+
+// Build the revision of a record, the snapshot is the record encoded in JSON
+func NewRevision(entity string, id uint, parentId *uint, action string, record interface{}) (*RevisionEntry, error) {
+
+	snapshot, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RevisionEntry{
+		CreatedAt: time.Now().UTC(),
+		Entity:    entity,
+		EntityId:  id,
+		ParentId:  parentId,
+		Action:    action,
+		Snapshot:  string(snapshot),
+	}, nil
+}
+
+// Save the revision of a record, called by the repositories in the transaction of the change
+func saveRevision(tx *gorm.DB, entity string, id uint, parentId *uint, action string, record interface{}) error {
+
+	revision, err := NewRevision(entity, id, parentId, action, record)
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(revision).Error
+}
+
+// Decode the snapshot into the record, a CatEntry, VisitEntry or TreatmentEntry
+func (e *RevisionEntry) Decode(record interface{}) error {
+	return json.Unmarshal([]byte(e.Snapshot), record)
+}
+
+// Revisions of a record, oldest first
+func (r *revisionEntryRepository) FindByEntity(entity string, id uint) ([]*RevisionEntry, error) {
+
+	var entries []*RevisionEntry
+	if err := r.db.Where("entity = ? AND entity_id = ?", entity, id).
+		Order("id").
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// Rebuild a cat with its owner, visits and treatments as they were at a given time
+func (r *revisionEntryRepository) FindCatAsOf(id uint, at time.Time) (*CatEntry, error) {
+
+	var revision *RevisionEntry
+	if err := r.db.Where("entity = ? AND entity_id = ? AND created_at <= ?", CatRevision, id, at.UTC()).
+		Order("id DESC").
+		First(&revision).Error; err != nil {
+		return nil, err
+	}
+
+	// The cat was deleted at this time
+	if revision.Action == RevisionDelete {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	var cat *CatEntry
+	if err := revision.Decode(&cat); err != nil {
+		return nil, err
+	}
+
+	// The owners have no revisions, the current informations of the owner are given
+	if cat.OwnerId != nil {
+		var owner *OwnerEntry
+		if err := r.db.Unscoped().First(&owner, *cat.OwnerId).Error; err == nil {
+			cat.Owner = owner
+		}
+	}
+
+	visitRevisions, err := r.findChildrenAsOf(VisitRevision, []uint{cat.ID}, at)
+	if err != nil {
+		return nil, err
+	}
+
+	cat.Visits = []VisitEntry{}
+	var visitIds []uint
+	for _, visitRevision := range visitRevisions {
+		var visit VisitEntry
+		if err := visitRevision.Decode(&visit); err != nil {
+			return nil, err
+		}
+		visit.Treatments = []TreatmentEntry{}
+		cat.Visits = append(cat.Visits, visit)
+		visitIds = append(visitIds, visit.ID)
+	}
+
+	// The visits are given in chronological order, like the history of the cat
+	sort.SliceStable(cat.Visits, func(i, j int) bool {
+		return cat.Visits[i].StartsAt.Before(cat.Visits[j].StartsAt)
+	})
+
+	treatmentRevisions, err := r.findChildrenAsOf(TreatmentRevision, visitIds, at)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, treatmentRevision := range treatmentRevisions {
+		var treatment TreatmentEntry
+		if err := treatmentRevision.Decode(&treatment); err != nil {
+			return nil, err
+		}
+
+		for i := range cat.Visits {
+			if cat.Visits[i].ID == treatment.VisitId {
+				cat.Visits[i].Treatments = append(cat.Visits[i].Treatments, treatment)
+			}
+		}
+	}
+
+	return cat, nil
+}
+
+// Last revisions at a given time of the records linked to one of the parents at this time, the deleted records are left out
+func (r *revisionEntryRepository) findChildrenAsOf(entity string, parentIds []uint, at time.Time) ([]*RevisionEntry, error) {
+
+	var entries []*RevisionEntry
+	if len(parentIds) == 0 {
+		return entries, nil
+	}
+
+	// Records linked to one of the parents at some point, they may have been moved to another parent since
+	children := r.db.Model(&RevisionEntry{}).
+		Select("entity_id").
+		Where("entity = ? AND parent_id IN ?", entity, parentIds)
+
+	latest := r.db.Model(&RevisionEntry{}).
+		Select("MAX(id)").
+		Where("entity = ? AND created_at <= ? AND entity_id IN (?)", entity, at.UTC(), children).
+		Group("entity_id")
+
+	if err := r.db.Where("id IN (?) AND parent_id IN ? AND action <> ?", latest, parentIds, RevisionDelete).
+		Order("entity_id").
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,111 @@
+package dbmodel_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+	"vet-clinic-api/database/databasetest"
+	"vet-clinic-api/database/dbmodel"
+
+	"gorm.io/gorm"
+)
+
+// A cat is rebuilt with the visits and treatments it had at a given time
+func TestFindCatAsOf(t *testing.T) {
+
+	db := databasetest.Open(t)
+	cats := dbmodel.NewCatEntryRepository(db)
+	visits := dbmodel.NewVisitEntryRepository(db)
+	treatments := dbmodel.NewTreatmentEntryRepository(db)
+	revisions := dbmodel.NewRevisionEntryRepository(db)
+
+	// Time after the changes made so far, the next changes are saved later
+	checkpoint := func() time.Time {
+		at := time.Now()
+		time.Sleep(2 * time.Millisecond)
+		return at
+	}
+
+	beforeCreation := checkpoint()
+
+	cat, err := cats.Create(&dbmodel.CatEntry{Name: "Tom", Age: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkup, err := visits.Create(&dbmodel.VisitEntry{CatId: cat.ID, StartsAt: time.Now().UTC(), Reason: "Checkup"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	created := checkpoint()
+
+	if _, err := cats.Patch(int(cat.ID), map[string]interface{}{"age": 3}); err != nil {
+		t.Fatal(err)
+	}
+	vaccine, err := treatments.Create(&dbmodel.TreatmentEntry{VisitId: checkup.ID, Name: "Vaccine"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := visits.Create(&dbmodel.VisitEntry{CatId: cat.ID, StartsAt: time.Now().UTC().Add(-24 * time.Hour), Reason: "Injury"}); err != nil {
+		t.Fatal(err)
+	}
+	treated := checkpoint()
+
+	if err := treatments.DeleteById(int(vaccine.ID)); err != nil {
+		t.Fatal(err)
+	}
+	treatmentDeleted := checkpoint()
+
+	if err := cats.DeleteById(int(cat.ID)); err != nil {
+		t.Fatal(err)
+	}
+	catDeleted := checkpoint()
+
+	if _, err := cats.Restore(int(cat.ID)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want string
+	}{
+		{"before the creation", beforeCreation, "not found"},
+		{"created", created, "Tom 2 [Checkup []]"},
+		{"updated, visits in chronological order", treated, "Tom 3 [Injury [] Checkup [Vaccine]]"},
+		{"treatment deleted", treatmentDeleted, "Tom 3 [Injury [] Checkup []]"},
+		{"cat deleted", catDeleted, "not found"},
+		{"cat restored with its visits", time.Now(), "Tom 3 [Injury [] Checkup []]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := revisions.FindCatAsOf(cat.ID, tt.at)
+
+			got := "not found"
+			if err == nil {
+				got = fmt.Sprintf("%s %d [", entry.Name, entry.Age)
+				for i, visit := range entry.Visits {
+					if i > 0 {
+						got += " "
+					}
+					got += visit.Reason + " ["
+					for j, treatment := range visit.Treatments {
+						if j > 0 {
+							got += " "
+						}
+						got += treatment.Name
+					}
+					got += "]"
+				}
+				got += "]"
+			} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+				t.Fatal(err)
+			}
+
+			if got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
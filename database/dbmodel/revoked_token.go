@@ -0,0 +1,60 @@
+package dbmodel
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// A revoked access token, or every access token of a user issued before RevokedAt when Jti is empty
+type RevokedTokenEntry struct {
+	gorm.Model
+	Jti       string    `json:"revoked_token_jti" gorm:"index"`
+	UserId    uint      `json:"revoked_token_user_id" gorm:"index"`
+	RevokedAt time.Time `json:"revoked_token_revoked_at"`
+	ExpiresAt time.Time `json:"revoked_token_expires_at" gorm:"index"`
+}
+
+type RevokedTokenEntryRepository interface {
+	Create(entry *RevokedTokenEntry) (*RevokedTokenEntry, error)
+	IsRevoked(jti string, userId uint, issuedAt time.Time) bool
+	DeleteExpired(now time.Time) error
+}
+
+type revokedTokenEntryRepository struct {
+	db *gorm.DB
+}
+
+func NewRevokedTokenEntryRepository(db *gorm.DB) RevokedTokenEntryRepository {
+	return &revokedTokenEntryRepository{db: db}
+}
+
+func (r *revokedTokenEntryRepository) Create(entry *RevokedTokenEntry) (*RevokedTokenEntry, error) {
+
+	if err := r.db.Create(entry).Error; err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (r *revokedTokenEntryRepository) IsRevoked(jti string, userId uint, issuedAt time.Time) bool {
+
+	var count int64
+	r.db.Model(&RevokedTokenEntry{}).
+		Where("expires_at > ?", time.Now().UTC()).
+		Where(r.db.Where("jti = ? AND jti <> ''", jti).
+			Or("jti = '' AND user_id = ? AND revoked_at >= ?", userId, issuedAt)).
+		Count(&count)
+
+	return count > 0
+}
+
+func (r *revokedTokenEntryRepository) DeleteExpired(now time.Time) error {
+
+	if err := r.db.Unscoped().Where("expires_at <= ?", now).Delete(&RevokedTokenEntry{}).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
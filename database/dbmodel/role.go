@@ -0,0 +1,177 @@
+package dbmodel
+
+import (
+	"context"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+type RoleEntry struct {
+	gorm.Model
+	Name        string `json:"role_name" gorm:"uniqueIndex"`
+	Description string `json:"role_description"`
+
+	//Permissions granted to the users having the role
+	Permissions []PermissionEntry `json:"role_permissions" gorm:"many2many:role_permissions;"`
+}
+
+// Audited fields of a role, its permissions by name
+type roleAudit struct {
+	Name        string   `json:"role_name"`
+	Description string   `json:"role_description"`
+	Permissions []string `json:"role_permissions"`
+}
+
+func newRoleAudit(entry *RoleEntry) *roleAudit {
+
+	permissions := []string{}
+	for _, permission := range entry.Permissions {
+		permissions = append(permissions, permission.Name)
+	}
+	sort.Strings(permissions)
+
+	return &roleAudit{Name: entry.Name, Description: entry.Description, Permissions: permissions}
+}
+
+type RoleEntryRepository interface {
+	WithContext(ctx context.Context) RoleEntryRepository
+	Create(entry *RoleEntry) (*RoleEntry, error)
+	FindAll(page Page) ([]*RoleEntry, *PageInfo, error)
+	FindById(id int) (*RoleEntry, error)
+	FindByName(name string) (*RoleEntry, error)
+	HasPermission(role string, permission string) bool
+	Update(id int, entry *RoleEntry) (*RoleEntry, error)
+	DeleteById(id int) error
+}
+
+type roleEntryRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleEntryRepository(db *gorm.DB) RoleEntryRepository {
+	return &roleEntryRepository{db: db}
+}
+
+// Return a repository running its queries with the request context, used to record the changes in the audit log
+func (r *roleEntryRepository) WithContext(ctx context.Context) RoleEntryRepository {
+	return &roleEntryRepository{db: r.db.WithContext(ctx)}
+}
+
+func (r *roleEntryRepository) Create(entry *RoleEntry) (*RoleEntry, error) {
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(entry).Error; err != nil {
+			return err
+		}
+
+		return saveAuditLog(tx, AuditCreate, "role", entry.ID, nil, newRoleAudit(entry))
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (r *roleEntryRepository) FindAll(page Page) ([]*RoleEntry, *PageInfo, error) {
+
+	query := r.db.Model(&RoleEntry{}).
+		Preload("Permissions", func(db *gorm.DB) *gorm.DB { return db.Order("name") })
+
+	return findPage[RoleEntry](query, page)
+}
+
+func (r *roleEntryRepository) FindById(id int) (*RoleEntry, error) {
+
+	var entries *RoleEntry
+	if err := r.db.Model(&RoleEntry{}).
+		Preload("Permissions", func(db *gorm.DB) *gorm.DB { return db.Order("name") }).
+		First(&entries, id).Error; err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (r *roleEntryRepository) FindByName(name string) (*RoleEntry, error) {
+
+	var entries *RoleEntry
+	if err := r.db.Model(&RoleEntry{}).
+		Preload("Permissions", func(db *gorm.DB) *gorm.DB { return db.Order("name") }).
+		Where("name = ?", name).
+		First(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (r *roleEntryRepository) HasPermission(role string, permission string) bool {
+
+	var count int64
+	r.db.Model(&RoleEntry{}).
+		Joins("JOIN role_permissions ON role_permissions.role_entry_id = role_entries.id").
+		Joins("JOIN permission_entries ON permission_entries.id = role_permissions.permission_entry_id").
+		Where("role_entries.name = ? AND permission_entries.name = ?", role, permission).
+		Count(&count)
+
+	return count > 0
+}
+
+// Update the role and replace its permissions, the users having the role follow a renaming
+func (r *roleEntryRepository) Update(id int, entry *RoleEntry) (*RoleEntry, error) {
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+
+		var current RoleEntry
+		if err := tx.Preload("Permissions").First(&current, id).Error; err != nil {
+			return err
+		}
+		before := newRoleAudit(&current)
+		oldName := current.Name
+
+		if err := tx.Model(&current).Updates(map[string]interface{}{
+			"name":        entry.Name,
+			"description": entry.Description,
+		}).Error; err != nil {
+			return err
+		}
+
+		if oldName != entry.Name {
+			if err := tx.Model(&UserEntry{}).Where("role = ?", oldName).Update("role", entry.Name).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&current).Association("Permissions").Replace(entry.Permissions); err != nil {
+			return err
+		}
+
+		return saveAuditLog(tx, AuditUpdate, "role", current.ID, before, newRoleAudit(entry))
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (r *roleEntryRepository) DeleteById(id int) error {
+
+	// Roles are removed for good so the name can be used again
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var current RoleEntry
+		if err := tx.Preload("Permissions").First(&current, id).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Select("Permissions").Delete(&current).Error; err != nil {
+			return err
+		}
+
+		return saveAuditLog(tx, AuditDelete, "role", current.ID, newRoleAudit(&current), nil)
+	})
+}
@@ -0,0 +1,317 @@
+package dbmodel
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Types of the search results
+const (
+	SearchCat       = "cat"
+	SearchVisit     = "visit"
+	SearchTreatment = "treatment"
+)
+
+// Markers around the matched terms in the highlighted texts of the search results
+const (
+	HighlightStart = "\x02"
+	HighlightEnd   = "\x03"
+)
+
+// Full-text index of a type of record, the rowid of the index is the id of the record
+type SearchTable struct {
+	Type    string
+	Index   string
+	Table   string
+	Columns []string
+}
+
+// Indexed records, the index is maintained by the triggers created with the migration
+var SearchTables = []SearchTable{
+	{Type: SearchCat, Index: "search_cats", Table: "cat_entries", Columns: []string{"name", "breed"}},
+	{Type: SearchVisit, Index: "search_visits", Table: "visit_entries", Columns: []string{"reason", "vet"}},
+	{Type: SearchTreatment, Index: "search_treatments", Table: "treatment_entries", Columns: []string{"name"}},
+}
+
+// Joins from a record to its cat, used to give the cat of the results and to filter them by owner
+var searchCatJoins = map[string]string{
+	SearchCat:       "",
+	SearchVisit:     "JOIN cat_entries ON cat_entries.id = visit_entries.cat_id",
+	SearchTreatment: "JOIN visit_entries ON visit_entries.id = treatment_entries.visit_id JOIN cat_entries ON cat_entries.id = visit_entries.cat_id",
+}
+
+// Search of the cats, visits and treatments, a record matches when it contains one of the terms
+type SearchQuery struct {
+	Terms []string
+	Types []string
+
+	// Records of the cats of an owner, an owner id 0 matches no record
+	OwnerId *uint
+	Limit   int
+}
+
+// Record matching a search, the best results have the highest rank
+type SearchResult struct {
+	Type  string
+	Id    uint
+	CatId uint
+	Rank  float64
+
+	// Texts of the indexed columns, the matched terms are between HighlightStart and HighlightEnd
+	Highlights map[string]string
+}
+
+type SearchEntryRepository interface {
+	Search(query SearchQuery) ([]*SearchResult, error)
+	Rebuild() error
+	FullText() bool
+}
+
+// Check if SQLite is built with FTS5, with the "sqlite_fts5" build tag
+func FullTextAvailable(db *gorm.DB) bool {
+
+	var enabled bool
+	if err := db.Raw("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&enabled).Error; err != nil {
+		return false
+	}
+
+	return enabled
+}
+
+// Return the FTS5 search when available, a search with LIKE otherwise
+func NewSearchEntryRepository(db *gorm.DB) SearchEntryRepository {
+
+	if FullTextAvailable(db) {
+		return &ftsSearchEntryRepository{db: db}
+	}
+
+	return &likeSearchEntryRepository{db: db}
+}
+
+// Search ranked by bm25 in the FTS5 indexes
+type ftsSearchEntryRepository struct {
+	db *gorm.DB
+}
+
+// Columns of a row of the results, the highlighted texts are in the order of the columns of the index
+type searchRow struct {
+	Type   string
+	Id     uint
+	CatId  uint
+	Rank   float64
+	First  string
+	Second string
+}
+
+func (r *ftsSearchEntryRepository) Search(query SearchQuery) ([]*SearchResult, error) {
+
+	// Each term is quoted and matched as a prefix, the records containing more terms are ranked first
+	terms := make([]string, 0, len(query.Terms))
+	for _, term := range query.Terms {
+		terms = append(terms, `"`+strings.ReplaceAll(term, `"`, `""`)+`"*`)
+	}
+	match := strings.Join(terms, " OR ")
+
+	var selects []string
+	var values []interface{}
+
+	for _, table := range searchTables(query.Types) {
+		second := "''"
+		if len(table.Columns) > 1 {
+			second = "highlight(" + table.Index + ", 1, ?, ?)"
+		}
+
+		statement := "SELECT '" + table.Type + "' AS type, " + table.Table + ".id AS id, cat_entries.id AS cat_id, -bm25(" + table.Index + ") AS rank, " +
+			"highlight(" + table.Index + ", 0, ?, ?) AS first, " + second + " AS second " +
+			"FROM " + table.Index + " JOIN " + table.Table + " ON " + table.Table + ".id = " + table.Index + ".rowid " + searchCatJoins[table.Type] +
+			" WHERE " + table.Index + " MATCH ?"
+
+		values = append(values, HighlightStart, HighlightEnd)
+		if len(table.Columns) > 1 {
+			values = append(values, HighlightStart, HighlightEnd)
+		}
+		values = append(values, match)
+
+		if query.OwnerId != nil {
+			statement += " AND cat_entries.owner_id = ?"
+			values = append(values, *query.OwnerId)
+		}
+
+		selects = append(selects, statement)
+	}
+
+	if len(selects) == 0 || len(terms) == 0 {
+		return []*SearchResult{}, nil
+	}
+
+	var rows []*searchRow
+	if err := r.db.Raw(strings.Join(selects, " UNION ALL ")+" ORDER BY rank DESC, type, id LIMIT ?", append(values, query.Limit)...).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	results := make([]*SearchResult, 0, len(rows))
+	for _, row := range rows {
+		result := &SearchResult{Type: row.Type, Id: row.Id, CatId: row.CatId, Rank: row.Rank, Highlights: map[string]string{}}
+
+		columns := searchTables([]string{row.Type})[0].Columns
+		for i, text := range []string{row.First, row.Second}[:len(columns)] {
+			result.Highlights[columns[i]] = text
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// Fill the indexes again from the records which are not in the trash
+func (r *ftsSearchEntryRepository) Rebuild() error {
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, table := range SearchTables {
+			columns := strings.Join(table.Columns, ", ")
+
+			for _, statement := range []string{
+				"DELETE FROM " + table.Index,
+				"INSERT INTO " + table.Index + "(rowid, " + columns + ") SELECT id, " + columns + " FROM " + table.Table + " WHERE deleted_at IS NULL",
+				"INSERT INTO " + table.Index + "(" + table.Index + ") VALUES('optimize')",
+			} {
+				if err := tx.Exec(statement).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *ftsSearchEntryRepository) FullText() bool {
+	return true
+}
+
+// Search with LIKE when SQLite is built without FTS5, ranked by the number of matched terms
+type likeSearchEntryRepository struct {
+	db *gorm.DB
+}
+
+// Escape the wildcards of LIKE in a term, "%" and "_" are matched as themselves with ESCAPE '\'
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (r *likeSearchEntryRepository) Search(query SearchQuery) ([]*SearchResult, error) {
+
+	results := []*SearchResult{}
+	if len(query.Terms) == 0 {
+		return results, nil
+	}
+
+	quoted := make([]string, 0, len(query.Terms))
+	for _, term := range query.Terms {
+		quoted = append(quoted, regexp.QuoteMeta(term))
+	}
+	terms := regexp.MustCompile("(?i)" + strings.Join(quoted, "|"))
+
+	for _, table := range searchTables(query.Types) {
+		columns := make([]string, 0, len(table.Columns))
+		for _, column := range table.Columns {
+			columns = append(columns, table.Table+"."+column)
+		}
+
+		db := r.db.Table(table.Table).
+			Select(table.Table + ".id, cat_entries.id, " + strings.Join(columns, ", ")).
+			Where(table.Table + ".deleted_at IS NULL")
+
+		if joins := searchCatJoins[table.Type]; joins != "" {
+			db = db.Joins(joins)
+		}
+
+		// A record matches when one of its columns contains one of the terms
+		var conditions []string
+		var values []interface{}
+		for _, column := range columns {
+			for _, term := range query.Terms {
+				conditions = append(conditions, column+` LIKE ? ESCAPE '\'`)
+				values = append(values, "%"+likeEscaper.Replace(term)+"%")
+			}
+		}
+		db = db.Where("("+strings.Join(conditions, " OR ")+")", values...)
+
+		if query.OwnerId != nil {
+			db = db.Where("cat_entries.owner_id = ?", *query.OwnerId)
+		}
+
+		rows, err := db.Rows()
+		if err != nil {
+			return nil, err
+		}
+
+		for rows.Next() {
+			result := &SearchResult{Type: table.Type, Highlights: map[string]string{}}
+
+			texts := make([]string, len(table.Columns))
+			destinations := []interface{}{&result.Id, &result.CatId}
+			for i := range texts {
+				destinations = append(destinations, &texts[i])
+			}
+
+			if err := rows.Scan(destinations...); err != nil {
+				rows.Close()
+				return nil, err
+			}
+
+			// The rank is the number of different terms found in the record
+			matched := map[string]bool{}
+			for i, text := range texts {
+				for _, match := range terms.FindAllString(text, -1) {
+					matched[strings.ToLower(match)] = true
+				}
+				result.Highlights[table.Columns[i]] = terms.ReplaceAllString(text, HighlightStart+"$0"+HighlightEnd)
+			}
+			result.Rank = float64(len(matched))
+
+			results = append(results, result)
+		}
+
+		if err := rows.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Rank > results[j].Rank
+	})
+
+	if len(results) > query.Limit {
+		results = results[:query.Limit]
+	}
+
+	return results, nil
+}
+
+// Nothing to rebuild, the records are searched directly
+func (r *likeSearchEntryRepository) Rebuild() error {
+	return nil
+}
+
+func (r *likeSearchEntryRepository) FullText() bool {
+	return false
+}
+
+// Indexes of the searched types, in the order of SearchTables
+func searchTables(types []string) []SearchTable {
+
+	var tables []SearchTable
+	for _, table := range SearchTables {
+		for _, searchType := range types {
+			if table.Type == searchType {
+				tables = append(tables, table)
+			}
+		}
+	}
+
+	return tables
+}
@@ -0,0 +1,267 @@
+package dbmodel_test
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+	"vet-clinic-api/database/databasetest"
+	"vet-clinic-api/database/dbmodel"
+)
+
+// The search is scoped like the lists, with FTS5 when the tests are built with the "sqlite_fts5" tag and with LIKE otherwise
+func TestSearchOwnerScope(t *testing.T) {
+
+	db := databasetest.Open(t)
+	owners := dbmodel.NewOwnerEntryRepository(db)
+	cats := dbmodel.NewCatEntryRepository(db)
+	visits := dbmodel.NewVisitEntryRepository(db)
+	treatments := dbmodel.NewTreatmentEntryRepository(db)
+
+	var ownerIds []uint
+	for _, name := range []string{"Alice", "Bob"} {
+		owner, err := owners.Create(&dbmodel.OwnerEntry{Name: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ownerIds = append(ownerIds, owner.ID)
+
+		cat, err := cats.Create(&dbmodel.CatEntry{Name: "Whiskers", OwnerId: &owner.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		visit, err := visits.Create(&dbmodel.VisitEntry{CatId: cat.ID, StartsAt: time.Now().UTC(), Reason: "Whiskers limping", Vet: "Dr A"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := treatments.Create(&dbmodel.TreatmentEntry{VisitId: visit.ID, Name: "Whiskers cream"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	zero := uint(0)
+	tests := []struct {
+		name    string
+		ownerId *uint
+		want    int
+	}{
+		{"not scoped", nil, 6},
+		{"scoped to an owner", &ownerIds[0], 3},
+		{"scoped without owner id", &zero, 0},
+	}
+
+	search := dbmodel.NewSearchEntryRepository(db)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			results, err := search.Search(dbmodel.SearchQuery{
+				Terms:   []string{"whiskers"},
+				Types:   []string{dbmodel.SearchCat, dbmodel.SearchVisit, dbmodel.SearchTreatment},
+				OwnerId: tt.ownerId,
+				Limit:   20})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(results) != tt.want {
+				t.Errorf("got %d results, want %d", len(results), tt.want)
+			}
+		})
+	}
+}
+
+// The records containing more terms are ranked first, the matched terms are highlighted
+func TestSearchRank(t *testing.T) {
+
+	db := databasetest.Open(t)
+	cats := dbmodel.NewCatEntryRepository(db)
+	visits := dbmodel.NewVisitEntryRepository(db)
+
+	var catIds []uint
+	for _, entry := range []*dbmodel.CatEntry{
+		{Name: "Tom", Breed: "Persian"},
+		{Name: "Tom", Breed: "Siamese"},
+		{Name: "Felix", Breed: "Bengal"},
+	} {
+		cat, err := cats.Create(entry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		catIds = append(catIds, cat.ID)
+	}
+
+	visit, err := visits.Create(&dbmodel.VisitEntry{CatId: catIds[2], StartsAt: time.Now().UTC(), Reason: "Vaccine booster", Vet: "Dr A"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	search := dbmodel.NewSearchEntryRepository(db)
+	results, err := search.Search(dbmodel.SearchQuery{
+		Terms: []string{"tom", "siamese", "booster"},
+		Types: []string{dbmodel.SearchCat, dbmodel.SearchVisit},
+		Limit: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+	if results[0].Type != dbmodel.SearchCat || results[0].Id != catIds[1] {
+		t.Errorf("got %s %d first, want the cat %d matching two terms", results[0].Type, results[0].Id, catIds[1])
+	}
+	if results[0].Rank <= results[1].Rank {
+		t.Errorf("got rank %v then %v, want a decreasing rank", results[0].Rank, results[1].Rank)
+	}
+
+	tests := []struct {
+		name   string
+		typ    string
+		id     uint
+		column string
+		want   string
+	}{
+		{"cat name", dbmodel.SearchCat, catIds[1], "name", dbmodel.HighlightStart + "Tom" + dbmodel.HighlightEnd},
+		{"cat breed", dbmodel.SearchCat, catIds[1], "breed", dbmodel.HighlightStart + "Siamese" + dbmodel.HighlightEnd},
+		{"cat breed not matched", dbmodel.SearchCat, catIds[0], "breed", "Persian"},
+		{"visit reason", dbmodel.SearchVisit, visit.ID, "reason", "Vaccine " + dbmodel.HighlightStart + "booster" + dbmodel.HighlightEnd},
+		{"visit vet not matched", dbmodel.SearchVisit, visit.ID, "vet", "Dr A"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			for _, result := range results {
+				if result.Type == tt.typ && result.Id == tt.id {
+					if got := result.Highlights[tt.column]; got != tt.want {
+						t.Errorf("got %q, want %q", got, tt.want)
+					}
+					return
+				}
+			}
+			t.Errorf("got no result for %s %d", tt.typ, tt.id)
+		})
+	}
+}
+
+// The index follows the changes of the records, a deleted record is found again once restored
+func TestSearchSync(t *testing.T) {
+
+	db := databasetest.Open(t)
+	cats := dbmodel.NewCatEntryRepository(db)
+	search := dbmodel.NewSearchEntryRepository(db)
+
+	cat, err := cats.Create(&dbmodel.CatEntry{Name: "Tom"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		change func() error
+		term   string
+		want   int
+	}{
+		{"created", func() error { return nil }, "tom", 1},
+		{"old name after update", func() error {
+			_, err := cats.Patch(int(cat.ID), map[string]interface{}{"name": "Felix"})
+			return err
+		}, "tom", 0},
+		{"new name after update", func() error { return nil }, "felix", 1},
+		{"deleted", func() error { return cats.DeleteById(int(cat.ID)) }, "felix", 0},
+		{"restored", func() error {
+			_, err := cats.Restore(int(cat.ID))
+			return err
+		}, "felix", 1},
+		{"purged", func() error {
+			if err := cats.DeleteById(int(cat.ID)); err != nil {
+				return err
+			}
+			_, err := cats.PurgeDeleted(time.Now().Add(time.Hour))
+			return err
+		}, "felix", 0},
+	}
+
+	// The steps change the same record, they are run in order
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			if err := tt.change(); err != nil {
+				t.Fatal(err)
+			}
+
+			results, err := search.Search(dbmodel.SearchQuery{Terms: []string{tt.term}, Types: []string{dbmodel.SearchCat}, Limit: 20})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(results) != tt.want {
+				t.Errorf("got %d results, want %d", len(results), tt.want)
+			}
+		})
+	}
+
+	// The FTS5 index holds no row of the purged record
+	if search.FullText() {
+		var count int64
+		if err := db.Table("search_cats").Where("rowid = ?", cat.ID).Count(&count).Error; err != nil {
+			t.Fatal(err)
+		}
+		if count != 0 {
+			t.Errorf("got %d rows in the index, want 0", count)
+		}
+	}
+}
+
+// Without FTS5 the terms are matched anywhere in the columns, the wildcards of LIKE are matched as themselves
+func TestSearchLike(t *testing.T) {
+
+	db := databasetest.Open(t)
+	search := dbmodel.NewSearchEntryRepository(db)
+	if search.FullText() {
+		t.Skip("built with FTS5, the LIKE search is used without the sqlite_fts5 tag")
+	}
+
+	cats := dbmodel.NewCatEntryRepository(db)
+	for _, name := range []string{"Tom_1", "Tomx1", "Felix 100%", "Felix 1000", `Back\slash`} {
+		if _, err := cats.Create(&dbmodel.CatEntry{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		term string
+		want []string
+	}{
+		{"inside a word", "omx", []string{"Tomx1"}},
+		{"case insensitive", "TOM", []string{"Tom_1", "Tomx1"}},
+		{"underscore", "m_1", []string{"Tom_1"}},
+		{"percent", "100%", []string{"Felix 100%"}},
+		{"percent alone", "%", []string{"Felix 100%"}},
+		{"backslash", `\`, []string{`Back\slash`}},
+		{"no match", "garfield", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			results, err := search.Search(dbmodel.SearchQuery{Terms: []string{tt.term}, Types: []string{dbmodel.SearchCat}, Limit: 20})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, result := range results {
+				got = append(got, strings.NewReplacer(dbmodel.HighlightStart, "", dbmodel.HighlightEnd, "").Replace(result.Highlights["name"]))
+			}
+			sort.Strings(got)
+
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package dbmodel
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Error of a restore when the cat of the visit or the visit of the treatment is still in the trash
+var ErrParentDeleted = errors.New("parent record deleted")
+
+// Deleted cat, visit or treatment, the records of the three tables are listed together
+type TrashEntry struct {
+	// Key of the record in the trash, like "visit:12", the ids of the tables are not unique together
+	ID string
+
+	Entity   string
+	EntityId uint
+
+	// Name of the cat or treatment, reason of the visit
+	Name string
+
+	// Start of a visit, the deletion time for the cats and treatments
+	StartsAt time.Time
+
+	// Cat of a visit or visit of a treatment
+	ParentId *uint
+
+	DeletedAt time.Time
+	UpdatedBy *uint
+}
+
+// Deleted records of the three tables.
+// SQLite only keeps the type of a column of a union when every select gives a table column, so the start of the visits is read as a time
+// only when the cats and treatments give their deletion time in its place.
+const trashQuery = "SELECT 'cat:' || id AS id, 'cat' AS entity, id AS entity_id, name, deleted_at AS starts_at, NULL AS parent_id, deleted_at, updated_by " +
+	"FROM cat_entries WHERE deleted_at IS NOT NULL " +
+	"UNION ALL SELECT 'visit:' || id, 'visit', id, reason, starts_at, cat_id, deleted_at, updated_by " +
+	"FROM visit_entries WHERE deleted_at IS NOT NULL " +
+	"UNION ALL SELECT 'treatment:' || id, 'treatment', id, name, deleted_at, visit_id, deleted_at, updated_by " +
+	"FROM treatment_entries WHERE deleted_at IS NOT NULL"
+
+type TrashEntryRepository interface {
+	FindAll(entity string, page Page) ([]*TrashEntry, *PageInfo, error)
+}
+
+type trashEntryRepository struct {
+	db *gorm.DB
+}
+
+func NewTrashEntryRepository(db *gorm.DB) TrashEntryRepository {
+	return &trashEntryRepository{db: db}
+}
+
+// Find a page of the deleted records of an entity, or of every entity when it is empty, the last deleted first without sort
+func (r *trashEntryRepository) FindAll(entity string, page Page) ([]*TrashEntry, *PageInfo, error) {
+
+	query := r.db.Table("(?) AS trash", r.db.Raw(trashQuery))
+
+	if entity != "" {
+		query = query.Where("entity = ?", entity)
+	}
+	if len(page.Sort) == 0 {
+		page.Sort = []Sort{{Column: "deleted_at", Desc: true}}
+	}
+
+	return findPage[TrashEntry](query, page)
+}
+
+// Set the deletion time of a record, the records deleted with it get the same time to be restored together
+func softDelete(tx *gorm.DB, model interface{}, id uint, at time.Time) error {
+	return tx.Model(model).Where("id = ?", id).Update("deleted_at", at).Error
+}
+
+// Take a record out of the trash and reload it
+func restore(tx *gorm.DB, record interface{}, id uint) error {
+
+	if err := tx.Unscoped().Model(record).Where("id = ?", id).Update("deleted_at", nil).Error; err != nil {
+		return err
+	}
+
+	return tx.First(record, id).Error
+}
+
+// Soft delete the visits of cats with their treatments
+func deleteVisitsOf(tx *gorm.DB, catIds []uint, at time.Time) error {
+
+	var visits []*VisitEntry
+	if err := tx.Where("cat_id IN ?", catIds).Find(&visits).Error; err != nil {
+		return err
+	}
+
+	var visitIds []uint
+	for _, visit := range visits {
+		if err := softDelete(tx, &VisitEntry{}, visit.ID, at); err != nil {
+			return err
+		}
+
+		if err := saveRevision(tx, VisitRevision, visit.ID, &visit.CatId, RevisionDelete, visit); err != nil {
+			return err
+		}
+
+		visitIds = append(visitIds, visit.ID)
+	}
+
+	if len(visitIds) == 0 {
+		return nil
+	}
+
+	return deleteTreatmentsOf(tx, visitIds, at)
+}
+
+// Soft delete the treatments of visits
+func deleteTreatmentsOf(tx *gorm.DB, visitIds []uint, at time.Time) error {
+
+	var treatments []*TreatmentEntry
+	if err := tx.Where("visit_id IN ?", visitIds).Find(&treatments).Error; err != nil {
+		return err
+	}
+
+	for _, treatment := range treatments {
+		if err := softDelete(tx, &TreatmentEntry{}, treatment.ID, at); err != nil {
+			return err
+		}
+
+		if err := saveRevision(tx, TreatmentRevision, treatment.ID, &treatment.VisitId, RevisionDelete, treatment); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Restore the visits of a cat deleted at the same time as the cat, with their treatments
+func restoreVisitsOf(tx *gorm.DB, catId uint, deletedAt time.Time) error {
+
+	var visits []*VisitEntry
+	if err := tx.Unscoped().Where("cat_id = ? AND deleted_at IS NOT NULL", catId).Find(&visits).Error; err != nil {
+		return err
+	}
+
+	for _, visit := range visits {
+		if !visit.DeletedAt.Time.Equal(deletedAt) {
+			continue
+		}
+
+		if err := restore(tx, visit, visit.ID); err != nil {
+			return err
+		}
+
+		if err := saveRevision(tx, VisitRevision, visit.ID, &visit.CatId, RevisionRestore, visit); err != nil {
+			return err
+		}
+
+		if err := restoreTreatmentsOf(tx, visit.ID, deletedAt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Restore the treatments of a visit deleted at the same time as the visit
+func restoreTreatmentsOf(tx *gorm.DB, visitId uint, deletedAt time.Time) error {
+
+	var treatments []*TreatmentEntry
+	if err := tx.Unscoped().Where("visit_id = ? AND deleted_at IS NOT NULL", visitId).Find(&treatments).Error; err != nil {
+		return err
+	}
+
+	for _, treatment := range treatments {
+		if !treatment.DeletedAt.Time.Equal(deletedAt) {
+			continue
+		}
+
+		if err := restore(tx, treatment, treatment.ID); err != nil {
+			return err
+		}
+
+		if err := saveRevision(tx, TreatmentRevision, treatment.ID, &treatment.VisitId, RevisionRestore, treatment); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Ids of the records in the trash since before a given time
+func deletedBefore(tx *gorm.DB, model interface{}, before time.Time) ([]uint, error) {
+
+	var records []struct {
+		ID        uint
+		DeletedAt time.Time
+	}
+	if err := tx.Unscoped().Model(model).Where("deleted_at IS NOT NULL").Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	// The times are compared here, the DB stores them as text
+	ids := []uint{}
+	for _, record := range records {
+		if record.DeletedAt.Before(before) {
+			ids = append(ids, record.ID)
+		}
+	}
+
+	return ids, nil
+}
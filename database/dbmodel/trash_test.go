@@ -0,0 +1,146 @@
+package dbmodel_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+	"vet-clinic-api/database/databasetest"
+	"vet-clinic-api/database/dbmodel"
+
+	"gorm.io/gorm"
+)
+
+// A cat is restored with the visits and treatments deleted with it, not with the ones deleted before
+func TestTrashCascadeRestore(t *testing.T) {
+
+	db := databasetest.Open(t)
+	cats := dbmodel.NewCatEntryRepository(db)
+	visits := dbmodel.NewVisitEntryRepository(db)
+	treatments := dbmodel.NewTreatmentEntryRepository(db)
+
+	cat, err := cats.Create(&dbmodel.CatEntry{Name: "Tom"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Records by name, a visit v with its treatment t
+	ids := map[string]int{"cat": int(cat.ID)}
+	for _, name := range []string{"1", "2"} {
+		visit, err := visits.Create(&dbmodel.VisitEntry{CatId: cat.ID, StartsAt: time.Now().UTC(), Reason: "Checkup"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		treatment, err := treatments.Create(&dbmodel.TreatmentEntry{VisitId: visit.ID, Name: "Vaccine"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids["v"+name], ids["t"+name] = int(visit.ID), int(treatment.ID)
+	}
+
+	// Names of the records out of the trash
+	live := func() string {
+		found := map[string]error{}
+		_, found["cat"] = cats.FindById(ids["cat"])
+		_, found["v1"] = visits.FindById(ids["v1"])
+		_, found["t1"] = treatments.FindById(ids["t1"])
+		_, found["v2"] = visits.FindById(ids["v2"])
+		_, found["t2"] = treatments.FindById(ids["t2"])
+
+		var names []string
+		for _, name := range []string{"cat", "v1", "t1", "v2", "t2"} {
+			if found[name] == nil {
+				names = append(names, name)
+			}
+		}
+		return fmt.Sprint(names)
+	}
+
+	restoreCat := func() error { _, err := cats.Restore(ids["cat"]); return err }
+	restoreVisit := func(name string) func() error {
+		return func() error { _, err := visits.Restore(ids[name]); return err }
+	}
+	restoreTreatment := func(name string) func() error {
+		return func() error { _, err := treatments.Restore(ids[name]); return err }
+	}
+
+	// The steps change the same records in order
+	steps := []struct {
+		name   string
+		action func() error
+		err    error
+		live   string
+	}{
+		{"delete a treatment alone", func() error { return treatments.DeleteById(ids["t2"]) }, nil, "[cat v1 t1 v2]"},
+		{"delete the cat", func() error { return cats.DeleteById(ids["cat"]) }, nil, "[]"},
+		{"restore a visit of a deleted cat", restoreVisit("v1"), dbmodel.ErrParentDeleted, "[]"},
+		{"restore a treatment of a deleted visit", restoreTreatment("t1"), dbmodel.ErrParentDeleted, "[]"},
+		{"restore the cat", restoreCat, nil, "[cat v1 t1 v2]"},
+		{"restore a record out of the trash", restoreCat, gorm.ErrRecordNotFound, "[cat v1 t1 v2]"},
+		{"restore the treatment deleted alone", restoreTreatment("t2"), nil, "[cat v1 t1 v2 t2]"},
+		{"delete a visit", func() error { return visits.DeleteById(ids["v1"]) }, nil, "[cat v2 t2]"},
+		{"restore the visit", restoreVisit("v1"), nil, "[cat v1 t1 v2 t2]"},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			if err := step.action(); !errors.Is(err, step.err) {
+				t.Fatalf("got %v, want %v", err, step.err)
+			}
+
+			if got := live(); got != step.live {
+				t.Errorf("got %s out of the trash, want %s", got, step.live)
+			}
+		})
+	}
+}
+
+func TestTrashPurge(t *testing.T) {
+
+	db := databasetest.Open(t)
+	cats := dbmodel.NewCatEntryRepository(db)
+	visits := dbmodel.NewVisitEntryRepository(db)
+
+	var catIds []uint
+	for _, name := range []string{"Tom", "Felix"} {
+		cat, err := cats.Create(&dbmodel.CatEntry{Name: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := visits.Create(&dbmodel.VisitEntry{CatId: cat.ID, StartsAt: time.Now().UTC(), Reason: "Checkup"}); err != nil {
+			t.Fatal(err)
+		}
+		catIds = append(catIds, cat.ID)
+	}
+
+	if err := cats.DeleteById(int(catIds[0])); err != nil {
+		t.Fatal(err)
+	}
+	cutoff := time.Now()
+	time.Sleep(10 * time.Millisecond)
+	if err := cats.DeleteById(int(catIds[1])); err != nil {
+		t.Fatal(err)
+	}
+
+	purged, err := cats.PurgeDeleted(cutoff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(purged) != fmt.Sprint(catIds[:1]) {
+		t.Errorf("got %v purged, want %v", purged, catIds[:1])
+	}
+
+	// The purged cat can't be restored, the other one still can
+	if _, err := cats.Restore(int(catIds[0])); err == nil {
+		t.Error("purged cat restored")
+	}
+	if _, err := cats.Restore(int(catIds[1])); err != nil {
+		t.Errorf("got %v, want the cat deleted after the cutoff restored", err)
+	}
+
+	var count int64
+	db.Unscoped().Model(&dbmodel.VisitEntry{}).Where("cat_id = ?", catIds[0]).Count(&count)
+	if count != 0 {
+		t.Errorf("got %d visits of the purged cat, want 0", count)
+	}
+}
@@ -1,6 +1,9 @@
 package dbmodel
 
 import (
+	"context"
+	"time"
+
 	"gorm.io/gorm"
 )
 
@@ -8,15 +11,31 @@ type TreatmentEntry struct {
 	gorm.Model
 	Name    string `json:"treatment_name"`
 	VisitId uint   `json:"treatment_visit_id"`
+
+	//Users who created and last updated the treatment
+	Authorship
+}
+
+// Filters of the treatments, the zero values are ignored
+type TreatmentFilter struct {
+	Name    string
+	VisitId uint
+
+	// Pointer, so an owner id 0 still scopes the treatments and matches none of them
+	OwnerId *uint
 }
 
 type TreatmentEntryRepository interface {
+	WithContext(ctx context.Context) TreatmentEntryRepository
 	Create(entry *TreatmentEntry) (*TreatmentEntry, error)
-	FindAll() ([]*TreatmentEntry, error)
+	FindAll(filter TreatmentFilter, page Page) ([]*TreatmentEntry, *PageInfo, error)
 	FindByVisitId(id int) ([]*TreatmentEntry, error)
 	FindById(id int) (*TreatmentEntry, error)
 	Update(id int, entry *TreatmentEntry) (*TreatmentEntry, error)
+	Patch(id int, columns map[string]interface{}) (*TreatmentEntry, error)
 	DeleteById(id int) error
+	Restore(id int) (*TreatmentEntry, error)
+	PurgeDeleted(before time.Time) ([]uint, error)
 }
 
 type treatmentEntryRepository struct {
@@ -27,23 +46,53 @@ func NewTreatmentEntryRepository(db *gorm.DB) TreatmentEntryRepository {
 	return &treatmentEntryRepository{db: db}
 }
 
+// Return a repository running its queries with the request context, used to stamp the user on the changed records
+func (r *treatmentEntryRepository) WithContext(ctx context.Context) TreatmentEntryRepository {
+	return &treatmentEntryRepository{db: r.db.WithContext(ctx)}
+}
+
 func (r *treatmentEntryRepository) Create(entry *TreatmentEntry) (*TreatmentEntry, error) {
 
-	if err := r.db.Create(entry).Error; err != nil {
+	// The record and its first revision are saved together
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(entry).Error; err != nil {
+			return err
+		}
+
+		if err := saveRevision(tx, TreatmentRevision, entry.ID, &entry.VisitId, RevisionCreate, entry); err != nil {
+			return err
+		}
+
+		return saveAuditLog(tx, AuditCreate, "treatment", entry.ID, nil, entry)
+	})
+
+	if err != nil {
 		return nil, err
 	}
 
 	return entry, nil
 }
 
-func (r *treatmentEntryRepository) FindAll() ([]*TreatmentEntry, error) {
+func (r *treatmentEntryRepository) FindAll(filter TreatmentFilter, page Page) ([]*TreatmentEntry, *PageInfo, error) {
 
-	var entries []*TreatmentEntry
-	if err := r.db.Find(&entries).Error; err != nil {
-		return nil, err
+	query := r.db.Model(&TreatmentEntry{})
+
+	if filter.Name != "" {
+		query = query.Where("name LIKE ?", "%"+filter.Name+"%")
+	}
+	if filter.VisitId != 0 {
+		query = query.Where("visit_id = ?", filter.VisitId)
 	}
 
-	return entries, nil
+	// Treatments of the visits of the cats of an owner
+	if filter.OwnerId != nil {
+		query = query.Where("visit_id IN (?)", r.db.Model(&VisitEntry{}).
+			Select("visit_entries.id").
+			Joins("JOIN cat_entries ON cat_entries.id = visit_entries.cat_id AND cat_entries.deleted_at IS NULL").
+			Where("cat_entries.owner_id = ?", *filter.OwnerId))
+	}
+
+	return findPage[TreatmentEntry](query, page)
 }
 
 func (r *treatmentEntryRepository) FindByVisitId(id int) ([]*TreatmentEntry, error) {
@@ -68,30 +117,138 @@ func (r *treatmentEntryRepository) FindById(id int) (*TreatmentEntry, error) {
 
 func (r *treatmentEntryRepository) Update(id int, entry *TreatmentEntry) (*TreatmentEntry, error) {
 
-	result := r.db.Model(&TreatmentEntry{}).
-		Where("id = ?", id).
-		Updates(map[string]interface{}{
-			"name":     entry.Name,
-			"visit_id": entry.VisitId,
-		})
+	if _, err := r.Patch(id, map[string]interface{}{
+		"name":     entry.Name,
+		"visit_id": entry.VisitId,
+	}); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// Update only the given columns, for the partial updates
+func (r *treatmentEntryRepository) Patch(id int, columns map[string]interface{}) (*TreatmentEntry, error) {
+
+	var current *TreatmentEntry
+
+	// The new values are saved in a revision, the old ones stay in the previous revisions
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var before *TreatmentEntry
+		if err := tx.First(&before, id).Error; err != nil {
+			return err
+		}
+
+		result := tx.Model(&TreatmentEntry{}).
+			Where("id = ?", id).
+			Updates(columns)
+
+		if result.Error != nil {
+			return result.Error
+		}
 
-	if result.Error != nil {
-		return nil, result.Error
+		// Check if something has been update
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.First(&current, id).Error; err != nil {
+			return err
+		}
+
+		if err := saveRevision(tx, TreatmentRevision, current.ID, &current.VisitId, RevisionUpdate, current); err != nil {
+			return err
+		}
+
+		return saveAuditLog(tx, AuditUpdate, "treatment", current.ID, before, current)
+	})
+
+	if err != nil {
+		return nil, err
 	}
 
-	// Check if something has been update
-	if result.RowsAffected == 0 {
-		return nil, gorm.ErrRecordNotFound
+	return current, nil
+}
+
+func (r *treatmentEntryRepository) DeleteById(id int) error {
+
+	// The deleted record is kept in its last revision
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var current *TreatmentEntry
+		if err := tx.First(&current, id).Error; err != nil {
+			return err
+		}
+
+		if err := softDelete(tx, &TreatmentEntry{}, current.ID, time.Now().UTC()); err != nil {
+			return err
+		}
+
+		if err := saveRevision(tx, TreatmentRevision, current.ID, &current.VisitId, RevisionDelete, current); err != nil {
+			return err
+		}
+
+		return saveAuditLog(tx, AuditDelete, "treatment", current.ID, current, nil)
+	})
+}
+
+// Take a treatment out of the trash
+func (r *treatmentEntryRepository) Restore(id int) (*TreatmentEntry, error) {
+
+	var entry *TreatmentEntry
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("deleted_at IS NOT NULL").First(&entry, id).Error; err != nil {
+			return err
+		}
+
+		// The visit of the treatment must be restored first
+		if err := tx.First(&VisitEntry{}, entry.VisitId).Error; err != nil {
+			return ErrParentDeleted
+		}
+
+		if err := restore(tx, entry, entry.ID); err != nil {
+			return err
+		}
+
+		if err := saveRevision(tx, TreatmentRevision, entry.ID, &entry.VisitId, RevisionRestore, entry); err != nil {
+			return err
+		}
+
+		return saveAuditLog(tx, AuditRestore, "treatment", entry.ID, nil, entry)
+	})
+
+	if err != nil {
+		return nil, err
 	}
 
 	return entry, nil
 }
 
-func (r *treatmentEntryRepository) DeleteById(id int) error {
+// Permanently delete the treatments in the trash since before a given time, return their ids
+func (r *treatmentEntryRepository) PurgeDeleted(before time.Time) ([]uint, error) {
+
+	var ids []uint
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if ids, err = deletedBefore(tx, &TreatmentEntry{}, before); err != nil || len(ids) == 0 {
+			return err
+		}
+
+		if err := tx.Unscoped().Delete(&TreatmentEntry{}, ids).Error; err != nil {
+			return err
+		}
 
-	if err := r.db.Delete(&TreatmentEntry{}, id).Error; err != nil {
-		return err
+		for _, id := range ids {
+			if err := saveAuditLog(tx, AuditPurge, "treatment", id, nil, nil); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return ids, nil
 }
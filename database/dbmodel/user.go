@@ -1,22 +1,53 @@
 package dbmodel
 
 import (
+	"context"
+	"time"
+
 	"gorm.io/gorm"
 )
 
 type UserEntry struct {
 	gorm.Model
 	Email    string `json:"user_email"`
-	Password string `json:"user_password"`
+	Password string `json:"user_password" audit:"-"`
 	Role     string `json:"user_role"`
+
+	//Owner linked to the account when the role is "owner"
+	OwnerId *uint `json:"user_owner_id"`
+
+	//TOTP second factor, the secret is kept while the enrollment is not confirmed
+	TotpSecret   string `json:"user_totp_secret" audit:"-"`
+	TotpEnabled  bool   `json:"user_totp_enabled"`
+	TotpLastStep int64  `json:"user_totp_last_step" audit:"-"`
+
+	//Failed logins since the last successful one, the account is locked until the given time
+	FailedLoginAttempts int        `json:"user_failed_login_attempts"`
+	LockedUntil         *time.Time `json:"user_locked_until"`
+}
+
+// Filters of the users, the zero values are ignored
+type UserFilter struct {
+	Email string
+	Role  string
 }
 
 type UserEntryRepository interface {
+	WithContext(ctx context.Context) UserEntryRepository
 	Create(entry *UserEntry) (*UserEntry, error)
-	FindAll() ([]*UserEntry, error)
+	FindAll(filter UserFilter, page Page) ([]*UserEntry, *PageInfo, error)
 	FindById(id int) (*UserEntry, error)
 	FindByEmail(email string) (*UserEntry, error)
+	Count() (int64, error)
+	CountByRole(role string) (int64, error)
 	Update(id int, entry *UserEntry) (*UserEntry, error)
+	Patch(id int, columns map[string]interface{}) error
+	UpdatePassword(id int, password string) error
+	UpdateTotp(id int, secret string, enabled bool) error
+	UseTotpStep(id int, step int64) (bool, error)
+	IncrementFailedLogins(id int) (int, error)
+	Lock(id int, until time.Time) error
+	ResetFailedLogins(id int) error
 	DeleteById(id int) error
 }
 
@@ -28,23 +59,40 @@ func NewUserEntryRepository(db *gorm.DB) UserEntryRepository {
 	return &userEntryRepository{db: db}
 }
 
+// Return a repository running its queries with the request context, used to record the changes in the audit log
+func (r *userEntryRepository) WithContext(ctx context.Context) UserEntryRepository {
+	return &userEntryRepository{db: r.db.WithContext(ctx)}
+}
+
 func (r *userEntryRepository) Create(entry *UserEntry) (*UserEntry, error) {
 
-	if err := r.db.Create(entry).Error; err != nil {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(entry).Error; err != nil {
+			return err
+		}
+
+		return saveAuditLog(tx, AuditCreate, "user", entry.ID, nil, entry)
+	})
+
+	if err != nil {
 		return nil, err
 	}
 
 	return entry, nil
 }
 
-func (r *userEntryRepository) FindAll() ([]*UserEntry, error) {
+func (r *userEntryRepository) FindAll(filter UserFilter, page Page) ([]*UserEntry, *PageInfo, error) {
 
-	var entries []*UserEntry
-	if err := r.db.Find(&entries).Error; err != nil {
-		return nil, err
+	query := r.db.Model(&UserEntry{})
+
+	if filter.Email != "" {
+		query = query.Where("email LIKE ?", "%"+filter.Email+"%")
+	}
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
 	}
 
-	return entries, nil
+	return findPage[UserEntry](query, page)
 }
 
 func (r *userEntryRepository) FindById(id int) (*UserEntry, error) {
@@ -67,32 +115,137 @@ func (r *userEntryRepository) FindByEmail(email string) (*UserEntry, error) {
 	return entries, nil
 }
 
+func (r *userEntryRepository) Count() (int64, error) {
+
+	var count int64
+	if err := r.db.Model(&UserEntry{}).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *userEntryRepository) CountByRole(role string) (int64, error) {
+
+	var count int64
+	if err := r.db.Model(&UserEntry{}).Where("role = ?", role).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
 func (r *userEntryRepository) Update(id int, entry *UserEntry) (*UserEntry, error) {
 
+	if err := r.Patch(id, map[string]interface{}{
+		"email":    entry.Email,
+		"password": entry.Password,
+		"role":     entry.Role,
+		"owner_id": entry.OwnerId,
+	}); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// Update only the given columns, for the partial updates
+func (r *userEntryRepository) Patch(id int, columns map[string]interface{}) error {
+
+	// The user before and after the change is recorded in the audit log with the change
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var before *UserEntry
+		if err := tx.First(&before, id).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&UserEntry{}).
+			Where("id = ?", id).
+			Updates(columns).Error; err != nil {
+			return err
+		}
+
+		var after *UserEntry
+		if err := tx.First(&after, id).Error; err != nil {
+			return err
+		}
+
+		return saveAuditLog(tx, AuditUpdate, "user", after.ID, before, after)
+	})
+}
+
+func (r *userEntryRepository) UpdatePassword(id int, password string) error {
+	return r.Patch(id, map[string]interface{}{"password": password})
+}
+
+func (r *userEntryRepository) UpdateTotp(id int, secret string, enabled bool) error {
+	return r.Patch(id, map[string]interface{}{
+		"totp_secret":  secret,
+		"totp_enabled": enabled,
+	})
+}
+
+// Save the time step of a used TOTP code, return false if a code of this step or a later one was already used
+func (r *userEntryRepository) UseTotpStep(id int, step int64) (bool, error) {
+
 	result := r.db.Model(&UserEntry{}).
-		Where("id = ?", id).
-		Updates(map[string]interface{}{
-			"email":    entry.Email,
-			"password": entry.Password,
-		})
+		Where("id = ? AND totp_last_step < ?", id, step).
+		Update("totp_last_step", step)
 
 	if result.Error != nil {
-		return nil, result.Error
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// Count a failed login and return the number of failures since the last successful one
+func (r *userEntryRepository) IncrementFailedLogins(id int) (int, error) {
+
+	if err := r.db.Model(&UserEntry{}).
+		Where("id = ?", id).
+		Update("failed_login_attempts", gorm.Expr("failed_login_attempts + 1")).Error; err != nil {
+		return 0, err
 	}
 
-	// Check if something has been update
-	if result.RowsAffected == 0 {
-		return nil, gorm.ErrRecordNotFound
+	var entries *UserEntry
+	if err := r.db.Select("failed_login_attempts").First(&entries, id).Error; err != nil {
+		return 0, err
 	}
 
-	return entry, nil
+	return entries.FailedLoginAttempts, nil
 }
 
-func (r *userEntryRepository) DeleteById(id int) error {
+func (r *userEntryRepository) Lock(id int, until time.Time) error {
 
-	if err := r.db.Delete(&UserEntry{}, id).Error; err != nil {
+	if err := r.db.Model(&UserEntry{}).
+		Where("id = ?", id).
+		Update("locked_until", until.UTC()).Error; err != nil {
 		return err
 	}
 
 	return nil
 }
+
+func (r *userEntryRepository) ResetFailedLogins(id int) error {
+	return r.Patch(id, map[string]interface{}{
+		"failed_login_attempts": 0,
+		"locked_until":          nil,
+	})
+}
+
+func (r *userEntryRepository) DeleteById(id int) error {
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var before *UserEntry
+		if err := tx.First(&before, id).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&UserEntry{}, id).Error; err != nil {
+			return err
+		}
+
+		return saveAuditLog(tx, AuditDelete, "user", before.ID, before, nil)
+	})
+}
@@ -1,30 +1,52 @@
 package dbmodel
 
 import (
+	"context"
+	"time"
+
 	"gorm.io/gorm"
 )
 
 type VisitEntry struct {
 	gorm.Model
-	CatId  uint   `json:"visit_cat_id"`
-	Date   string `json:"visit_date"`
+	CatId uint `json:"visit_cat_id"`
+
+	// Start and end of the visit, saved in UTC, the end is not known for the visits migrated from a date
+	StartsAt time.Time  `json:"visit_starts_at" gorm:"index"`
+	EndsAt   *time.Time `json:"visit_ends_at"`
+
 	Reason string `json:"visit_reason"`
 	Vet    string `json:"visit_vet"`
 
+	//Users who created and last updated the visit
+	Authorship
+
 	//Add a foreignKey to VisitId on the table Treatment, and a Delete On Cascade
-	Treatments []TreatmentEntry `json:"treatments" gorm:"foreignKey:VisitId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Treatments []TreatmentEntry `json:"treatments" audit:"-" gorm:"foreignKey:VisitId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+// Start and end of the visit in a timezone, the one of the clinic for the responses
+func (e *VisitEntry) In(location *time.Location) (time.Time, *time.Time) {
+
+	if e.EndsAt == nil {
+		return e.StartsAt.In(location), nil
+	}
+
+	endsAt := e.EndsAt.In(location)
+	return e.StartsAt.In(location), &endsAt
 }
 
 type VisitEntryRepository interface {
+	WithContext(ctx context.Context) VisitEntryRepository
 	Create(entry *VisitEntry) (*VisitEntry, error)
-	FindAll() ([]*VisitEntry, error)
+	FindAll(query VisitQuery, page Page) ([]*VisitEntry, *PageInfo, error)
 	FindById(id int) (*VisitEntry, error)
-	FindByReason(reason string) ([]*VisitEntry, error)
-	FindByVet(vet string) ([]*VisitEntry, error)
-	FindByDate(date string) ([]*VisitEntry, error)
 	FindLastVisitId(id int) bool
 	Update(id int, entry *VisitEntry) (*VisitEntry, error)
+	Patch(id int, columns map[string]interface{}) (*VisitEntry, error)
 	DeleteById(id int) error
+	Restore(id int) (*VisitEntry, error)
+	PurgeDeleted(before time.Time) ([]uint, error)
 }
 
 type visitEntryRepository struct {
@@ -35,25 +57,35 @@ func NewVisitEntryRepository(db *gorm.DB) VisitEntryRepository {
 	return &visitEntryRepository{db: db}
 }
 
+// Return a repository running its queries with the request context, used to stamp the user on the changed records
+func (r *visitEntryRepository) WithContext(ctx context.Context) VisitEntryRepository {
+	return &visitEntryRepository{db: r.db.WithContext(ctx)}
+}
+
 func (r *visitEntryRepository) Create(entry *VisitEntry) (*VisitEntry, error) {
 
-	if err := r.db.Create(entry).Error; err != nil {
-		return nil, err
-	}
+	// The record and its first revision are saved together
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(entry).Error; err != nil {
+			return err
+		}
 
-	return entry, nil
-}
+		if err := saveRevision(tx, VisitRevision, entry.ID, &entry.CatId, RevisionCreate, entry); err != nil {
+			return err
+		}
 
-func (r *visitEntryRepository) FindAll() ([]*VisitEntry, error) {
+		return saveAuditLog(tx, AuditCreate, "visit", entry.ID, nil, entry)
+	})
 
-	var entries []*VisitEntry
-	if err := r.db.Model(&VisitEntry{}).
-		Preload("Treatments").
-		Find(&entries).Error; err != nil {
+	if err != nil {
 		return nil, err
 	}
 
-	return entries, nil
+	return entry, nil
+}
+
+func (r *visitEntryRepository) FindAll(query VisitQuery, page Page) ([]*VisitEntry, *PageInfo, error) {
+	return findPage[VisitEntry](r.db.Model(&VisitEntry{}).Scopes(query.Scope), page, "Treatments")
 }
 
 func (r *visitEntryRepository) FindById(id int) (*VisitEntry, error) {
@@ -68,82 +100,166 @@ func (r *visitEntryRepository) FindById(id int) (*VisitEntry, error) {
 	return entries, nil
 }
 
-func (r *visitEntryRepository) FindByReason(reason string) ([]*VisitEntry, error) {
+func (r *visitEntryRepository) FindLastVisitId(id int) bool {
 
-	var entries []*VisitEntry
-	if err := r.db.Model(&VisitEntry{}).
-		Preload("Treatments").
-		Where("reason LIKE ?", "%"+reason+"%").
-		Find(&entries).Error; err != nil {
-		return nil, err
-	}
+	var count int64
+	r.db.Model(&VisitEntry{}).Where("id = ?", id).Count(&count)
 
-	return entries, nil
+	return count > 0
 }
 
-func (r *visitEntryRepository) FindByVet(vet string) ([]*VisitEntry, error) {
+func (r *visitEntryRepository) Update(id int, entry *VisitEntry) (*VisitEntry, error) {
 
-	var entries []*VisitEntry
-	if err := r.db.Model(&VisitEntry{}).
-		Preload("Treatments").
-		Where("vet LIKE ?", "%"+vet+"%").
-		Find(&entries).Error; err != nil {
+	if _, err := r.Patch(id, map[string]interface{}{
+		"cat_id":    entry.CatId,
+		"starts_at": entry.StartsAt,
+		"ends_at":   entry.EndsAt,
+		"reason":    entry.Reason,
+		"vet":       entry.Vet,
+	}); err != nil {
 		return nil, err
 	}
 
-	return entries, nil
+	return entry, nil
 }
 
-func (r *visitEntryRepository) FindByDate(date string) ([]*VisitEntry, error) {
+// Update only the given columns, for the partial updates
+func (r *visitEntryRepository) Patch(id int, columns map[string]interface{}) (*VisitEntry, error) {
 
-	var entries []*VisitEntry
-	if err := r.db.Model(&VisitEntry{}).
-		Preload("Treatments").
-		Where("DATE(date) = ?", date).
-		Find(&entries).Error; err != nil {
+	var current *VisitEntry
+
+	// The new values are saved in a revision, the old ones stay in the previous revisions
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var before *VisitEntry
+		if err := tx.First(&before, id).Error; err != nil {
+			return err
+		}
+
+		result := tx.Model(&VisitEntry{}).
+			Preload("Treatments").
+			Where("id = ?", id).
+			Updates(columns)
+
+		if result.Error != nil {
+			return result.Error
+		}
+
+		// Check if something has been update
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.First(&current, id).Error; err != nil {
+			return err
+		}
+
+		if err := saveRevision(tx, VisitRevision, current.ID, &current.CatId, RevisionUpdate, current); err != nil {
+			return err
+		}
+
+		return saveAuditLog(tx, AuditUpdate, "visit", current.ID, before, current)
+	})
+
+	if err != nil {
 		return nil, err
 	}
 
-	return entries, nil
+	return current, nil
 }
 
-func (r *visitEntryRepository) FindLastVisitId(id int) bool {
+func (r *visitEntryRepository) DeleteById(id int) error {
 
-	var count int64
-	r.db.Model(&VisitEntry{}).Where("id = ?", id).Count(&count)
+	// The deleted record is kept in its last revision, its treatments are deleted with it
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var current *VisitEntry
+		if err := tx.First(&current, id).Error; err != nil {
+			return err
+		}
 
-	return count > 0
+		deletedAt := time.Now().UTC()
+		if err := softDelete(tx, &VisitEntry{}, current.ID, deletedAt); err != nil {
+			return err
+		}
+
+		if err := saveRevision(tx, VisitRevision, current.ID, &current.CatId, RevisionDelete, current); err != nil {
+			return err
+		}
+
+		if err := saveAuditLog(tx, AuditDelete, "visit", current.ID, current, nil); err != nil {
+			return err
+		}
+
+		return deleteTreatmentsOf(tx, []uint{current.ID}, deletedAt)
+	})
 }
 
-func (r *visitEntryRepository) Update(id int, entry *VisitEntry) (*VisitEntry, error) {
+// Take a visit out of the trash with the treatments deleted with it
+func (r *visitEntryRepository) Restore(id int) (*VisitEntry, error) {
 
-	result := r.db.Model(&VisitEntry{}).
-		Preload("Treatments").
-		Where("id = ?", id).
-		Updates(map[string]interface{}{
-			"cat_id": entry.CatId,
-			"date":   entry.Date,
-			"reason": entry.Reason,
-			"vet":    entry.Vet,
-		})
-
-	if result.Error != nil {
-		return nil, result.Error
-	}
+	var entry *VisitEntry
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("deleted_at IS NOT NULL").First(&entry, id).Error; err != nil {
+			return err
+		}
+
+		// The cat of the visit must be restored first
+		if err := tx.First(&CatEntry{}, entry.CatId).Error; err != nil {
+			return ErrParentDeleted
+		}
+
+		deletedAt := entry.DeletedAt.Time
+		if err := restore(tx, entry, entry.ID); err != nil {
+			return err
+		}
+
+		if err := saveRevision(tx, VisitRevision, entry.ID, &entry.CatId, RevisionRestore, entry); err != nil {
+			return err
+		}
+
+		if err := saveAuditLog(tx, AuditRestore, "visit", entry.ID, nil, entry); err != nil {
+			return err
+		}
+
+		return restoreTreatmentsOf(tx, entry.ID, deletedAt)
+	})
 
-	// Check if something has been update
-	if result.RowsAffected == 0 {
-		return nil, gorm.ErrRecordNotFound
+	if err != nil {
+		return nil, err
 	}
 
 	return entry, nil
 }
 
-func (r *visitEntryRepository) DeleteById(id int) error {
+// Permanently delete the visits in the trash since before a given time with their treatments, return their ids
+func (r *visitEntryRepository) PurgeDeleted(before time.Time) ([]uint, error) {
+
+	var ids []uint
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if ids, err = deletedBefore(tx, &VisitEntry{}, before); err != nil || len(ids) == 0 {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("visit_id IN ?", ids).Delete(&TreatmentEntry{}).Error; err != nil {
+			return err
+		}
 
-	if err := r.db.Delete(&VisitEntry{}, id).Error; err != nil {
-		return err
+		if err := tx.Unscoped().Delete(&VisitEntry{}, ids).Error; err != nil {
+			return err
+		}
+
+		for _, id := range ids {
+			if err := saveAuditLog(tx, AuditPurge, "visit", id, nil, nil); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return ids, nil
 }
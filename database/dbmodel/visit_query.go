@@ -0,0 +1,75 @@
+package dbmodel
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Search of the visits, the filters are combined with AND and the zero values are ignored.
+// It is applied as a scope, so any query on the visits can reuse it, like a count for a report:
+//
+//	db.Model(&VisitEntry{}).Scopes(query.Scope).Count(&count)
+type VisitQuery struct {
+	CatId  uint
+	Vet    string
+	Reason string
+
+	// Visits starting in the range, the end is excluded
+	StartsFrom   time.Time
+	StartsBefore time.Time
+
+	// Visits with or without a treatment, and visits with a treatment by its name
+	HasTreatment  *bool
+	TreatmentName string
+
+	// Visits of the cats of an owner, an owner id 0 matches no visit
+	OwnerId *uint
+}
+
+// Add the filters of the search to a query on the visits
+func (q VisitQuery) Scope(db *gorm.DB) *gorm.DB {
+
+	// The subqueries don't share the conditions of the query
+	newDB := db.Session(&gorm.Session{NewDB: true})
+
+	if q.CatId != 0 {
+		db = db.Where("visit_entries.cat_id = ?", q.CatId)
+	}
+	if q.Vet != "" {
+		db = db.Where("visit_entries.vet LIKE ?", "%"+q.Vet+"%")
+	}
+	if q.Reason != "" {
+		db = db.Where("visit_entries.reason LIKE ?", "%"+q.Reason+"%")
+	}
+
+	// The times are saved in UTC as text, they are only ordered when compared in UTC
+	if !q.StartsFrom.IsZero() {
+		db = db.Where("visit_entries.starts_at >= ?", q.StartsFrom.UTC())
+	}
+	if !q.StartsBefore.IsZero() {
+		db = db.Where("visit_entries.starts_at < ?", q.StartsBefore.UTC())
+	}
+
+	if q.HasTreatment != nil {
+		treatments := newDB.Model(&TreatmentEntry{}).Select("1").Where("treatment_entries.visit_id = visit_entries.id")
+		if *q.HasTreatment {
+			db = db.Where("EXISTS (?)", treatments)
+		} else {
+			db = db.Where("NOT EXISTS (?)", treatments)
+		}
+	}
+
+	if q.TreatmentName != "" {
+		db = db.Where("EXISTS (?)", newDB.Model(&TreatmentEntry{}).
+			Select("1").
+			Where("treatment_entries.visit_id = visit_entries.id").
+			Where("treatment_entries.name LIKE ?", "%"+q.TreatmentName+"%"))
+	}
+
+	if q.OwnerId != nil {
+		db = db.Where("visit_entries.cat_id IN (?)", newDB.Model(&CatEntry{}).Select("id").Where("owner_id = ?", *q.OwnerId))
+	}
+
+	return db
+}
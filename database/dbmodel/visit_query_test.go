@@ -0,0 +1,91 @@
+package dbmodel_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+	"vet-clinic-api/database/databasetest"
+	"vet-clinic-api/database/dbmodel"
+)
+
+// The filters of a visit search are combined
+func TestVisitQuery(t *testing.T) {
+
+	db := databasetest.Open(t)
+	cats := dbmodel.NewCatEntryRepository(db)
+	visits := dbmodel.NewVisitEntryRepository(db)
+	treatments := dbmodel.NewTreatmentEntryRepository(db)
+
+	tom, err := cats.Create(&dbmodel.CatEntry{Name: "Tom"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	felix, err := cats.Create(&dbmodel.CatEntry{Name: "Felix"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Visits in the Paris time zone, saved in UTC
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := func(d int, hour int) time.Time { return time.Date(2024, 3, d, hour, 0, 0, 0, paris) }
+
+	for _, visit := range []struct {
+		cat        *dbmodel.CatEntry
+		startsAt   time.Time
+		reason     string
+		vet        string
+		treatments []string
+	}{
+		{tom, day(1, 9), "Checkup", "Dr Martin", []string{"Vaccine"}},
+		{tom, day(2, 0), "Injury", "Dr Martin", []string{"Bandage", "Painkiller"}},
+		{tom, day(3, 9), "Checkup", "Dr Durand", nil},
+		{felix, day(2, 14), "Checkup", "Dr Martin", []string{"Deworming"}},
+	} {
+		entry, err := visits.Create(&dbmodel.VisitEntry{CatId: visit.cat.ID, StartsAt: visit.startsAt.UTC(), Reason: visit.reason, Vet: visit.vet})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range visit.treatments {
+			if _, err := treatments.Create(&dbmodel.TreatmentEntry{VisitId: entry.ID, Name: name}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	yes, no := true, false
+	tests := []struct {
+		name  string
+		query dbmodel.VisitQuery
+		want  string
+	}{
+		{"no filter", dbmodel.VisitQuery{}, "[Checkup Injury Checkup Checkup]"},
+		{"cat and reason", dbmodel.VisitQuery{CatId: tom.ID, Reason: "check"}, "[Checkup Checkup]"},
+		{"vet and reason", dbmodel.VisitQuery{Vet: "Martin", Reason: "Checkup"}, "[Checkup Checkup]"},
+		{"day in local time", dbmodel.VisitQuery{StartsFrom: day(2, 0), StartsBefore: day(3, 0)}, "[Injury Checkup]"},
+		{"range end excluded", dbmodel.VisitQuery{CatId: tom.ID, StartsBefore: day(3, 9)}, "[Checkup Injury]"},
+		{"with a treatment", dbmodel.VisitQuery{CatId: tom.ID, HasTreatment: &yes}, "[Checkup Injury]"},
+		{"without treatment", dbmodel.VisitQuery{HasTreatment: &no}, "[Checkup]"},
+		{"treatment name", dbmodel.VisitQuery{TreatmentName: "pain"}, "[Injury]"},
+		{"treatment name and vet", dbmodel.VisitQuery{TreatmentName: "pain", Vet: "Durand"}, "[]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, info, err := visits.FindAll(tt.query, dbmodel.Page{Limit: 50})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			reasons := []string{}
+			for _, entry := range entries {
+				reasons = append(reasons, entry.Reason)
+			}
+			if got := fmt.Sprint(reasons); got != tt.want || info.Total != int64(len(entries)) {
+				t.Errorf("got %s with total %d, want %s", got, info.Total, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"log"
+	"vet-clinic-api/database/dbmodel"
+
+	"gorm.io/gorm"
+)
+
+// Save a first revision of the cats, visits and treatments created before the revisions existed.
+// It is dated of their last update, the older values are lost.
+func BackfillRevisions(db *gorm.DB) {
+
+	var cats []*dbmodel.CatEntry
+	db.Unscoped().Where("id NOT IN (?)", revisedIds(db, dbmodel.CatRevision)).Find(&cats)
+	for _, cat := range cats {
+		backfillRevision(db, dbmodel.CatRevision, cat.ID, nil, cat.Model, cat.UpdatedBy, cat)
+	}
+
+	var visits []*dbmodel.VisitEntry
+	db.Unscoped().Where("id NOT IN (?)", revisedIds(db, dbmodel.VisitRevision)).Find(&visits)
+	for _, visit := range visits {
+		backfillRevision(db, dbmodel.VisitRevision, visit.ID, &visit.CatId, visit.Model, visit.UpdatedBy, visit)
+	}
+
+	var treatments []*dbmodel.TreatmentEntry
+	db.Unscoped().Where("id NOT IN (?)", revisedIds(db, dbmodel.TreatmentRevision)).Find(&treatments)
+	for _, treatment := range treatments {
+		backfillRevision(db, dbmodel.TreatmentRevision, treatment.ID, &treatment.VisitId, treatment.Model, treatment.UpdatedBy, treatment)
+	}
+}
+
+// Ids of the records of an entity already having revisions
+func revisedIds(db *gorm.DB, entity string) *gorm.DB {
+	return db.Model(&dbmodel.RevisionEntry{}).Select("entity_id").Where("entity = ?", entity)
+}
+
+func backfillRevision(db *gorm.DB, entity string, id uint, parentId *uint, record gorm.Model, updatedBy *uint, snapshot interface{}) {
+
+	// A record never updated since its creation gets a creation revision
+	action := dbmodel.RevisionUpdate
+	if record.UpdatedAt.Equal(record.CreatedAt) {
+		action = dbmodel.RevisionCreate
+	}
+
+	revision, err := dbmodel.NewRevision(entity, id, parentId, action, snapshot)
+	if err != nil {
+		log.Println("Failed to backfill the revision of", entity, id, err)
+		return
+	}
+
+	revision.CreatedAt = record.UpdatedAt.UTC()
+	revision.CreatedBy = updatedBy
+	revisions := []*dbmodel.RevisionEntry{revision}
+
+	// A deleted record is left out of the revisions after its deletion
+	if record.DeletedAt.Valid {
+		deletion := *revision
+		deletion.CreatedAt = record.DeletedAt.Time.UTC()
+		deletion.Action = dbmodel.RevisionDelete
+		deletion.CreatedBy = nil
+		revisions = append(revisions, &deletion)
+	}
+
+	if err := db.Create(&revisions).Error; err != nil {
+		log.Println("Failed to backfill the revision of", entity, id, err)
+	}
+}
@@ -0,0 +1,88 @@
+package database
+
+import (
+	"log"
+	"strings"
+	"vet-clinic-api/database/dbmodel"
+
+	"gorm.io/gorm"
+)
+
+// Create the full-text indexes of the search with the triggers maintaining them on every write of the records.
+// Without FTS5 the triggers are removed, the search reads the records and the indexes are rebuilt once FTS5 is back.
+func MigrateSearch(db *gorm.DB) {
+
+	available := dbmodel.FullTextAvailable(db)
+	rebuild := false
+
+	for _, table := range dbmodel.SearchTables {
+		triggers := searchTriggers(table)
+
+		if !available {
+			for _, trigger := range triggers {
+				if err := db.Exec("DROP TRIGGER IF EXISTS " + trigger.name).Error; err != nil {
+					log.Println("Failed to drop the search trigger", trigger.name, err)
+				}
+			}
+			continue
+		}
+
+		// The words are stemmed, "limping" matches "limp", and the accents are ignored
+		if err := db.Exec("CREATE VIRTUAL TABLE IF NOT EXISTS " + table.Index + " USING fts5(" + strings.Join(table.Columns, ", ") +
+			", tokenize = 'porter unicode61 remove_diacritics 2')").Error; err != nil {
+			log.Println("Failed to create the search index", table.Index, err)
+			continue
+		}
+
+		for _, trigger := range triggers {
+			var count int64
+			db.Raw("SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name = ?", trigger.name).Scan(&count)
+			if count > 0 {
+				continue
+			}
+
+			// The records written without the trigger are missing from the index
+			rebuild = true
+			if err := db.Exec(trigger.statement).Error; err != nil {
+				log.Println("Failed to create the search trigger", trigger.name, err)
+			}
+		}
+	}
+
+	if !available {
+		log.Println("SQLite is built without FTS5, the search uses LIKE")
+		return
+	}
+
+	if rebuild {
+		if err := dbmodel.NewSearchEntryRepository(db).Rebuild(); err != nil {
+			log.Println("Failed to rebuild the search index", err)
+		}
+	}
+}
+
+type searchTrigger struct {
+	name      string
+	statement string
+}
+
+// Triggers copying the indexed columns of a record to its index, a record in the trash is removed from the index
+func searchTriggers(table dbmodel.SearchTable) []searchTrigger {
+
+	columns := strings.Join(table.Columns, ", ")
+
+	newValues := make([]string, 0, len(table.Columns))
+	for _, column := range table.Columns {
+		newValues = append(newValues, "new."+column)
+	}
+	values := strings.Join(newValues, ", ")
+
+	insert := "INSERT INTO " + table.Index + "(rowid, " + columns + ") SELECT new.id, " + values + " WHERE new.deleted_at IS NULL;"
+	remove := "DELETE FROM " + table.Index + " WHERE rowid = old.id;"
+
+	return []searchTrigger{
+		{table.Table + "_search_insert", "CREATE TRIGGER " + table.Table + "_search_insert AFTER INSERT ON " + table.Table + " BEGIN " + insert + " END"},
+		{table.Table + "_search_update", "CREATE TRIGGER " + table.Table + "_search_update AFTER UPDATE ON " + table.Table + " BEGIN " + remove + " " + insert + " END"},
+		{table.Table + "_search_delete", "CREATE TRIGGER " + table.Table + "_search_delete AFTER DELETE ON " + table.Table + " BEGIN " + remove + " END"},
+	}
+}
@@ -0,0 +1,130 @@
+package database
+
+import (
+	"errors"
+	"log"
+	"slices"
+	"sort"
+	"vet-clinic-api/database/dbmodel"
+
+	"gorm.io/gorm"
+)
+
+// Every permission checked by the routes
+var Permissions = map[string]string{
+	"owners:read":          "Read the owners",
+	"owners:write":         "Create, update and delete the owners",
+	"cats:read":            "Read the cats and their history",
+	"cats:write":           "Create, update and delete the cats",
+	"visits:read":          "Read the visits",
+	"visits:write":         "Create, update and delete the visits",
+	"appointments:read":    "Read the appointments, the free slots and the calendars of the vets",
+	"appointments:write":   "Book, reschedule, cancel and close the appointments",
+	"calendars:write":      "Edit the working hours and holidays of the vets and the durations of the appointments",
+	"treatments:read":      "Read the treatments",
+	"treatments:prescribe": "Prescribe, update and delete the treatments",
+	"users:read":           "Read the users",
+	"users:write":          "Create, update and delete the users and their sessions",
+	"roles:read":           "Read the roles and the permissions",
+	"roles:write":          "Create, update and delete the roles",
+	"audit:read":           "Read the audit log",
+	"trash:read":           "Read the deleted cats, visits and treatments",
+	"trash:purge":          "Permanently delete the cats, visits and treatments of the trash",
+}
+
+// Roles created on the first launch, they can be edited through the API afterwards
+var DefaultRoles = []struct {
+	Name        string
+	Permissions []string
+}{
+	{"admin", []string{
+		"owners:read", "owners:write", "cats:read", "cats:write", "visits:read", "visits:write",
+		"appointments:read", "appointments:write", "calendars:write",
+		"treatments:read", "treatments:prescribe", "users:read", "users:write", "roles:read", "roles:write",
+		"audit:read", "trash:read", "trash:purge",
+	}},
+	{"vet", []string{
+		"owners:read", "cats:read", "cats:write", "visits:read", "visits:write",
+		"appointments:read", "appointments:write",
+		"treatments:read", "treatments:prescribe", "trash:read",
+	}},
+	{"technician", []string{
+		"owners:read", "cats:read", "cats:write", "visits:read", "appointments:read", "treatments:read",
+	}},
+	{"receptionist", []string{
+		"owners:read", "owners:write", "cats:read", "cats:write", "visits:read", "visits:write",
+		"appointments:read", "appointments:write", "calendars:write", "trash:read",
+	}},
+	{"owner", []string{
+		"owners:read", "cats:read", "visits:read", "appointments:read", "treatments:read",
+	}},
+}
+
+// Create the missing permissions, and the default roles on the first launch.
+// A permission in the DB has been granted by a previous seed, so only the permissions created now are granted to the existing default roles:
+// an upgraded DB grants the permissions of the new routes, and the roles edited or deleted through the API are left alone.
+func Seed(db *gorm.DB) {
+
+	// Sorted names keep the same ids from one database to another
+	names := make([]string, 0, len(Permissions))
+	for name := range Permissions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var existing []string
+	if err := db.Model(&dbmodel.PermissionEntry{}).Pluck("name", &existing).Error; err != nil {
+		log.Println("Failed to seed the permissions", err)
+		return
+	}
+
+	var created []string
+	for _, name := range names {
+		if slices.Contains(existing, name) {
+			continue
+		}
+
+		if err := db.Create(&dbmodel.PermissionEntry{Name: name, Description: Permissions[name]}).Error; err != nil {
+			log.Println("Failed to seed permission", name, err)
+			continue
+		}
+		created = append(created, name)
+	}
+
+	for _, role := range DefaultRoles {
+
+		// The admin role gets every new permission, the other roles their new default permissions
+		var grantedNames []string
+		for _, name := range created {
+			if role.Name == "admin" || slices.Contains(role.Permissions, name) {
+				grantedNames = append(grantedNames, name)
+			}
+		}
+		if len(grantedNames) == 0 {
+			continue
+		}
+
+		var granted []dbmodel.PermissionEntry
+		db.Where("name IN ?", grantedNames).Find(&granted)
+
+		// The roles are created with the permissions on the first launch only
+		if len(existing) == 0 {
+			if err := db.Create(&dbmodel.RoleEntry{Name: role.Name, Permissions: granted}).Error; err != nil {
+				log.Println("Failed to seed role", role.Name, err)
+			}
+			continue
+		}
+
+		var current dbmodel.RoleEntry
+		if err := db.Where("name = ?", role.Name).First(&current).Error; err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Println("Failed to seed role", role.Name, err)
+			}
+			continue
+		}
+
+		if err := db.Model(&current).Association("Permissions").Append(granted); err != nil {
+			log.Println("Failed to seed the permissions of role", role.Name, err)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package database_test
+
+import (
+	"slices"
+	"testing"
+	"vet-clinic-api/database"
+	"vet-clinic-api/database/databasetest"
+	"vet-clinic-api/database/dbmodel"
+)
+
+// Permissions granted to a role, by name
+func rolePermissions(t *testing.T, roles dbmodel.RoleEntryRepository, name string) []string {
+
+	t.Helper()
+
+	role, err := roles.FindByName(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, permission := range role.Permissions {
+		names = append(names, permission.Name)
+	}
+
+	return names
+}
+
+// A DB seeded by an older version gets the new permissions, the permissions removed from a role stay removed
+func TestSeedUpgrade(t *testing.T) {
+
+	db := databasetest.Open(t)
+	roles := dbmodel.NewRoleEntryRepository(db)
+
+	// DB of an older version without the appointments:read and trash:purge permissions
+	var missing []dbmodel.PermissionEntry
+	if err := db.Where("name IN ?", []string{"appointments:read", "trash:purge"}).Find(&missing).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, permission := range missing {
+		if err := db.Exec("DELETE FROM role_permissions WHERE permission_entry_id = ?", permission.ID).Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Unscoped().Delete(&permission).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Vet role edited by an admin, a default permission removed and another one added
+	vet, err := roles.FindByName("vet")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var removed, added []dbmodel.PermissionEntry
+	db.Where("name = ?", "visits:write").Find(&removed)
+	db.Where("name = ?", "users:read").Find(&added)
+
+	if err := db.Model(vet).Association("Permissions").Delete(removed); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(vet).Association("Permissions").Append(added); err != nil {
+		t.Fatal(err)
+	}
+
+	// Technician role deleted by an admin
+	technician, err := roles.FindByName("technician")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := roles.DeleteById(int(technician.ID)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The seed is run on every launch
+	for i := 0; i < 2; i++ {
+		database.Seed(db)
+	}
+
+	tests := []struct {
+		name       string
+		role       string
+		permission string
+		granted    bool
+	}{
+		{"new permission granted to the admin", "admin", "trash:purge", true},
+		{"new default permission granted", "vet", "appointments:read", true},
+		{"new permission not granted to the other roles", "vet", "trash:purge", false},
+		{"removed permission stays removed", "vet", "visits:write", false},
+		{"added permission kept", "vet", "users:read", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rolePermissions(t, roles, tt.role)
+			if slices.Contains(got, tt.permission) != tt.granted {
+				t.Errorf("%s permissions %v, want %s granted %t", tt.role, got, tt.permission, tt.granted)
+			}
+		})
+	}
+
+	t.Run("deleted role not created again", func(t *testing.T) {
+		if _, err := roles.FindByName("technician"); err == nil {
+			t.Error("got the technician role, want it deleted")
+		}
+	})
+
+	t.Run("admin permissions not duplicated", func(t *testing.T) {
+		if admin := rolePermissions(t, roles, "admin"); len(admin) != len(database.Permissions) {
+			t.Errorf("got %d admin permissions, want %d", len(admin), len(database.Permissions))
+		}
+	})
+}
+
+// Every permission of the default roles exists, a typo would leave the role without it
+func TestDefaultRoles(t *testing.T) {
+
+	for _, role := range database.DefaultRoles {
+		for _, permission := range role.Permissions {
+			if _, ok := database.Permissions[permission]; !ok {
+				t.Errorf("role %s has the unknown permission %s", role.Name, permission)
+			}
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"slices"
+	"time"
+	"vet-clinic-api/database/dbmodel"
+
+	"gorm.io/gorm"
+)
+
+// Layouts of the dates saved as text before the visits had a start time, the API only accepted the first one
+var visitDateLayouts = []string{"2006-01-02", time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04"}
+
+// Move the dates saved as text before the visits had a start and an end to the start of the visits, then remove the date column.
+// A date without a time is the start of the day in the timezone of the clinic. The snapshots of the revisions get their start too.
+// The column is kept while a date can't be read, the visit is then migrated again on the next start.
+func MigrateVisitDates(db *gorm.DB, location *time.Location) {
+
+	// The columns are read one by one, HasColumn of SQLite searches the name in the SQL of the table and finds it in "ON UPDATE CASCADE"
+	columns, err := db.Migrator().ColumnTypes(&dbmodel.VisitEntry{})
+	if err != nil {
+		log.Println("Failed to read the columns of the visits", err)
+		return
+	}
+	if !slices.ContainsFunc(columns, func(column gorm.ColumnType) bool { return column.Name() == "date" }) {
+		return
+	}
+
+	failed := false
+
+	var visits []struct {
+		ID   uint
+		Date string
+	}
+	if err := db.Table("visit_entries").
+		Select("id, COALESCE(date, '') AS date").
+		Where("starts_at IS NULL").
+		Scan(&visits).Error; err != nil {
+		log.Println("Failed to read the dates of the visits", err)
+		return
+	}
+
+	for _, visit := range visits {
+		startsAt, err := parseVisitDate(visit.Date, location)
+		if err != nil {
+			log.Println("Failed to migrate the date of the visit", visit.ID, err)
+			failed = true
+			continue
+		}
+
+		if err := db.Table("visit_entries").Where("id = ?", visit.ID).UpdateColumn("starts_at", startsAt).Error; err != nil {
+			log.Println("Failed to migrate the date of the visit", visit.ID, err)
+			failed = true
+		}
+	}
+
+	var revisions []*dbmodel.RevisionEntry
+	if err := db.Where("entity = ? AND snapshot NOT LIKE ?", dbmodel.VisitRevision, `%"visit_starts_at"%`).
+		Find(&revisions).Error; err != nil {
+		log.Println("Failed to read the revisions of the visits", err)
+		return
+	}
+
+	for _, revision := range revisions {
+		if err := migrateVisitSnapshot(db, revision, location); err != nil {
+			log.Println("Failed to migrate the date of the visit revision", revision.ID, err)
+			failed = true
+		}
+	}
+
+	if failed {
+		log.Println("Some dates of the visits can't be read, the date column is kept")
+		return
+	}
+
+	if err := db.Exec("ALTER TABLE visit_entries DROP COLUMN date").Error; err != nil {
+		log.Println("Failed to drop the date column of the visits", err)
+	}
+}
+
+// Replace the date of a visit in the snapshot of a revision with its start
+func migrateVisitSnapshot(db *gorm.DB, revision *dbmodel.RevisionEntry, location *time.Location) error {
+
+	var snapshot map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(revision.Snapshot), &snapshot); err != nil {
+		return err
+	}
+
+	var date string
+	if err := json.Unmarshal(snapshot["visit_date"], &date); err != nil {
+		return err
+	}
+
+	startsAt, err := parseVisitDate(date, location)
+	if err != nil {
+		return err
+	}
+
+	if snapshot["visit_starts_at"], err = json.Marshal(startsAt); err != nil {
+		return err
+	}
+	snapshot["visit_ends_at"] = json.RawMessage("null")
+	delete(snapshot, "visit_date")
+
+	encoded, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	return db.Model(revision).UpdateColumn("snapshot", string(encoded)).Error
+}
+
+// Read a date saved as text in the timezone of the clinic, unless it gives its offset, and return it in UTC
+func parseVisitDate(date string, location *time.Location) (time.Time, error) {
+
+	for _, layout := range visitDateLayouts {
+		if startsAt, err := time.ParseInLocation(layout, date, location); err == nil {
+			return startsAt.UTC(), nil
+		}
+	}
+
+	return time.Time{}, errors.New("unknown date format: " + date)
+}
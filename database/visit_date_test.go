@@ -0,0 +1,122 @@
+package database_test
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+	"vet-clinic-api/database"
+	"vet-clinic-api/database/databasetest"
+	"vet-clinic-api/database/dbmodel"
+
+	"gorm.io/gorm"
+)
+
+// Check if the visits still have the date column of the older versions
+func hasDateColumn(t *testing.T, db *gorm.DB) bool {
+
+	t.Helper()
+
+	columns, err := db.Migrator().ColumnTypes(&dbmodel.VisitEntry{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return slices.ContainsFunc(columns, func(column gorm.ColumnType) bool { return column.Name() == "date" })
+}
+
+// Create a DB with the visits saved by an older version, with their date as text and without start
+func oldVisits(t *testing.T, dates ...string) *gorm.DB {
+
+	t.Helper()
+
+	db := databasetest.Open(t)
+	if err := db.Exec("ALTER TABLE visit_entries ADD COLUMN date text").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	cat, err := dbmodel.NewCatEntryRepository(db).Create(&dbmodel.CatEntry{Name: "Tom"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, date := range dates {
+		if err := db.Exec("INSERT INTO visit_entries (id, cat_id, date, reason) VALUES (?, ?, ?, 'Checkup')", i+1, cat.ID, date).Error; err != nil {
+			t.Fatal(err)
+		}
+
+		revision := &dbmodel.RevisionEntry{Entity: dbmodel.VisitRevision, EntityId: uint(i + 1), Action: dbmodel.RevisionCreate, Snapshot: `{"id":` + strconv.Itoa(i+1) + `,"visit_date":"` + date + `"}`}
+		if err := db.Create(revision).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return db
+}
+
+func TestMigrateVisitDates(t *testing.T) {
+
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skip("timezone database not available")
+	}
+
+	t.Run("new DB", func(t *testing.T) {
+		db := databasetest.Open(t)
+		database.MigrateVisitDates(db, paris)
+
+		if hasDateColumn(t, db) {
+			t.Error("got a date column, want none")
+		}
+	})
+
+	t.Run("dates of an older version", func(t *testing.T) {
+		db := oldVisits(t, "2024-05-03", "2024-05-03T10:30:00Z", "2024-12-24 18:15")
+		database.MigrateVisitDates(db, paris)
+
+		if hasDateColumn(t, db) {
+			t.Error("got the date column, want it dropped")
+		}
+
+		want := []time.Time{
+			time.Date(2024, 5, 2, 22, 0, 0, 0, time.UTC),
+			time.Date(2024, 5, 3, 10, 30, 0, 0, time.UTC),
+			time.Date(2024, 12, 24, 17, 15, 0, 0, time.UTC),
+		}
+
+		visits := dbmodel.NewVisitEntryRepository(db)
+		revisions := dbmodel.NewRevisionEntryRepository(db)
+		for i, startsAt := range want {
+			visit, err := visits.FindById(i + 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !visit.StartsAt.Equal(startsAt) {
+				t.Errorf("visit %d: got start %s, want %s", i+1, visit.StartsAt, startsAt)
+			}
+
+			entries, err := revisions.FindByEntity(dbmodel.VisitRevision, uint(i+1))
+			if err != nil || len(entries) != 1 {
+				t.Fatalf("visit %d: got %d revisions (%v), want 1", i+1, len(entries), err)
+			}
+			wantSnapshot := `{"id":` + strconv.Itoa(i+1) + `,"visit_ends_at":null,"visit_starts_at":"` + startsAt.Format(time.RFC3339) + `"}`
+			if entries[0].Snapshot != wantSnapshot {
+				t.Errorf("visit %d: got snapshot %s, want %s", i+1, entries[0].Snapshot, wantSnapshot)
+			}
+		}
+	})
+
+	t.Run("unreadable date", func(t *testing.T) {
+		db := oldVisits(t, "2024-05-03", "next tuesday")
+		database.MigrateVisitDates(db, paris)
+
+		if !hasDateColumn(t, db) {
+			t.Error("got no date column, want it kept until every date is read")
+		}
+
+		visit, err := dbmodel.NewVisitEntryRepository(db).FindById(1)
+		if err != nil || visit.StartsAt.IsZero() {
+			t.Errorf("got %+v (%v), want the readable date migrated", visit, err)
+		}
+	})
+}
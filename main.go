@@ -3,8 +3,17 @@ package main
 import (
 	"log"
 	"net/http"
+	"os"
 	"vet-clinic-api/config"
+	"vet-clinic-api/pkg/appointment"
+	"vet-clinic-api/pkg/audit"
+	"vet-clinic-api/pkg/authentication"
 	"vet-clinic-api/pkg/cat"
+	"vet-clinic-api/pkg/owner"
+	"vet-clinic-api/pkg/problem"
+	"vet-clinic-api/pkg/role"
+	"vet-clinic-api/pkg/search"
+	"vet-clinic-api/pkg/trash"
 	"vet-clinic-api/pkg/treatment"
 	"vet-clinic-api/pkg/user"
 	"vet-clinic-api/pkg/visit"
@@ -22,6 +31,7 @@ import (
 func Routes(configuration *config.Config) *chi.Mux {
 	router := chi.NewRouter()
 
+	router.Use(middleware.RequestID)
 	router.Use(middleware.Logger)
 	router.Use(middleware.Recoverer)
 
@@ -32,13 +42,13 @@ func Routes(configuration *config.Config) *chi.Mux {
 		AllowedOrigins: []string{"https://*", "http://*"},
 
 		// Allowed Methods for the requests
-		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE"},
+		AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
 
-		// Allowed headers
+		// Allowed headers, any Content-Type including the ones of the PATCH requests (application/merge-patch+json, application/json-patch+json)
 		AllowedHeaders: []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
 
 		// Exposed Headers
-		ExposedHeaders: []string{"Link"},
+		ExposedHeaders: []string{"Link", "X-Total-Count"},
 
 		// Allow Credentials
 		AllowCredentials: true,
@@ -50,11 +60,28 @@ func Routes(configuration *config.Config) *chi.Mux {
 	// Set up content type
 	router.Use(render.SetContentType(render.ContentTypeJSON))
 
+	// Unknown routes are answered with a problem like the errors of the handlers, set before the mounts to be inherited
+	router.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		problem.NotFound(w, r, "Route not found")
+	})
+	router.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		problem.MethodNotAllowed(w, r, "Method "+r.Method+" not allowed on this route")
+	})
+
 	// Route Set up
+	router.Mount("/api/v1/vet/owners", owner.Routes(configuration))
 	router.Mount("/api/v1/vet/cats", cat.Routes(configuration))
 	router.Mount("/api/v1/vet/treatments", treatment.Routes(configuration))
 	router.Mount("/api/v1/vet/visits", visit.Routes(configuration))
+	router.Mount("/api/v1/vet/appointments", appointment.Routes(configuration))
 	router.Mount("/api/v1/vet/users", user.Routes(configuration))
+	router.Mount("/api/v1/vet/roles", role.Routes(configuration))
+	router.Mount("/api/v1/vet/audit", audit.Routes(configuration))
+	router.Mount("/api/v1/vet/trash", trash.Routes(configuration))
+	router.Mount("/api/v1/vet/search", search.Routes(configuration))
+
+	// Public keys used by other services to verify the access tokens
+	router.Get("/.well-known/jwks.json", authentication.JWKSHandler(configuration.AccessTokenKeys))
 
 	// Load static file for swagger
 	router.Handle("/docs/*", http.StripPrefix("/docs/", http.FileServer(http.Dir("./docs"))))
@@ -66,13 +93,14 @@ func Routes(configuration *config.Config) *chi.Mux {
 
 // @title           Veterinarian API
 // @version         1.0
-// @description    	This is an API for managing a veterinary clinic. You can register cats, consultations and treatments.
+// @description    	This is an API for managing a veterinary clinic. You can register owners, cats, consultations and treatments.
 // @host            localhost:8081
 // @BasePath        /api/v1/vet
 // @schemes         http
 // @securityDefinitions.apikey BearerAuth
 // @in header
 // @name Authorization
+// @description "Bearer <access token>" or "ApiKey <API key>"
 func main() {
 	// Load .env file
 	if err := godotenv.Load(); err != nil {
@@ -85,6 +113,23 @@ func main() {
 		log.Panicln("Configuration error:", err)
 	}
 
+	// Admin command filling the search index again from the records, then exit
+	if len(os.Args) > 1 && os.Args[1] == "rebuild-search-index" {
+		if !configuration.SearchEntryRepository.FullText() {
+			log.Fatalln("SQLite is built without FTS5, build with -tags sqlite_fts5 to use the search index")
+		}
+		if err := configuration.SearchEntryRepository.Rebuild(); err != nil {
+			log.Fatalln("Search index rebuild error:", err)
+		}
+		log.Println("Search index rebuilt")
+		return
+	}
+
+	// Create the first admin when the DB has no user
+	if err := user.BootstrapAdmin(configuration, os.Getenv("BOOTSTRAP_ADMIN_EMAIL"), os.Getenv("BOOTSTRAP_ADMIN_PASSWORD")); err != nil {
+		log.Panicln("Bootstrap admin error:", err)
+	}
+
 	// Init Routes
 	router := Routes(configuration)
 
@@ -0,0 +1,510 @@
+package appointment
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+	"vet-clinic-api/database/dbmodel"
+	"vet-clinic-api/pkg/audit"
+	"vet-clinic-api/pkg/model"
+	"vet-clinic-api/pkg/problem"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// GetSlotsHandler godoc
+// @Summary      Get the free slots
+// @Description  Finds the times of a day an appointment can be booked, in the working hours of the vets, outside their holidays and their scheduled appointments. The slots start every 15 minutes and last the duration of the reason (30 minutes for an unknown reason). The past slots are left out.
+// @Tags         appointments
+// @Produce      json
+// @Param        date    query     string  true   "Day in the timezone of the clinic (format: YYYY-MM-DD)"
+// @Param        vet     query     string  false  "Slots of a vet, of every vet having working hours otherwise"
+// @Param        reason  query     string  false  "Reason of the appointment, giving its duration"
+// @Security     BearerAuth
+// @Success      200  {array}   model.SlotResponse
+// @Failure      400  {object}  problem.Problem  "Invalid date"
+// @Failure      500  {object}  problem.Problem  "Failed to find the free slots"
+// @Router       /appointments/slots [get]
+func (config *AppointmentConfig) GetSlotsHandler(w http.ResponseWriter, r *http.Request) {
+
+	values := r.URL.Query()
+	vet := values.Get("vet")
+
+	day, err := time.ParseInLocation("2006-01-02", values.Get("date"), config.ClinicLocation)
+	if err != nil {
+		problem.BadRequest(w, r, "date is required, expected YYYY-MM-DD")
+		return
+	}
+	nextDay := day.AddDate(0, 0, 1)
+
+	duration := DefaultDuration
+	if reason := values.Get("reason"); reason != "" {
+		if duration, err = config.duration(reason); err != nil {
+			problem.Error(w, r, err, "Failed to Find the duration of the reason")
+			return
+		}
+	}
+
+	// Request the DB to get the calendars of the day
+	hours, err := config.WorkingHoursEntryRepository.FindAll(vet)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find the working hours")
+		return
+	}
+
+	holidays, err := config.HolidayEntryRepository.FindAll(vet, day, nextDay)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find the holidays")
+		return
+	}
+
+	appointments, err := config.AppointmentEntryRepository.FindScheduled(vet, day, nextDay)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find the scheduled appointments")
+		return
+	}
+
+	// The working hours are ordered by vet
+	var vets []string
+	hoursByVet := map[string][]*dbmodel.WorkingHoursEntry{}
+	for _, entrie := range hours {
+		if _, ok := hoursByVet[entrie.Vet]; !ok {
+			vets = append(vets, entrie.Vet)
+		}
+		hoursByVet[entrie.Vet] = append(hoursByVet[entrie.Vet], entrie)
+	}
+
+	res := []*model.SlotResponse{}
+	for _, name := range vets {
+
+		// A vet is busy during the holidays of the clinic, its own holidays and its appointments
+		var busy []period
+		for _, holiday := range holidays {
+			if holiday.Vet == "" || holiday.Vet == name {
+				busy = append(busy, period{holiday.StartsAt, holiday.EndsAt})
+			}
+		}
+		for _, appointment := range appointments {
+			if appointment.Vet == name {
+				busy = append(busy, period{appointment.StartsAt, appointment.EndsAt})
+			}
+		}
+
+		for _, slot := range freeSlots(workingRanges(hoursByVet[name], day), busy, duration, time.Now()) {
+			res = append(res, &model.SlotResponse{Vet: name, StartsAt: slot.start.In(config.ClinicLocation), EndsAt: slot.end.In(config.ClinicLocation)})
+		}
+	}
+
+	render.JSON(w, r, res)
+}
+
+// GetWorkingHoursHandler godoc
+// @Summary      Get the working hours
+// @Description  Retrieves the working hours of the vets, ordered by vet, day of the week and time. The days go from 0 for Sunday to 6 for Saturday, the times are in the timezone of the clinic.
+// @Tags         appointments
+// @Produce      json
+// @Param        vet  query     string  false  "Working hours of a vet"
+// @Security     BearerAuth
+// @Success      200  {array}   model.WorkingHoursResponse
+// @Failure      500  {object}  problem.Problem  "Failed to retrieve the working hours"
+// @Router       /appointments/working-hours [get]
+func (config *AppointmentConfig) GetWorkingHoursHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Request the DB to get the needed informations
+	entries, err := config.WorkingHoursEntryRepository.FindAll(r.URL.Query().Get("vet"))
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find the working hours")
+		return
+	}
+
+	// Set up to a dedicated type for the response
+	res := []*model.WorkingHoursResponse{}
+	for _, entrie := range entries {
+		res = append(res, workingHoursResponse(entrie))
+	}
+
+	render.JSON(w, r, res)
+}
+
+// PostWorkingHoursHandler godoc
+// @Summary      Add working hours
+// @Description  Adds a range of working hours to a vet on a day of the week, from 0 for Sunday to 6 for Saturday. A day can have several ranges which don't overlap, like a morning and an afternoon.
+// @Tags         appointments
+// @Accept       json
+// @Produce      json
+// @Param        hours  body      model.WorkingHoursRequest  true  "Working hours payload"
+// @Security     BearerAuth
+// @Success      200  {object}  model.WorkingHoursResponse
+// @Failure      400  {object}  problem.Problem  "Invalid request payload"
+// @Failure      409  {object}  problem.Problem  "Overlapping other working hours of the vet"
+// @Failure      422  {object}  problem.Problem  "Validation failed"
+// @Failure      500  {object}  problem.Problem  "Failed to add the working hours"
+// @Router       /appointments/working-hours [post]
+func (config *AppointmentConfig) PostWorkingHoursHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the request
+	req := &model.WorkingHoursRequest{}
+	if err := render.Bind(r, req); err != nil {
+		problem.Bind(w, r, err, "Invalid Working Hours Post request payload")
+		return
+	}
+
+	// The ranges of a day of a vet don't overlap, the times are ordered like texts
+	existing, err := config.WorkingHoursEntryRepository.FindAll(*req.Vet)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find the working hours")
+		return
+	}
+
+	for _, entrie := range existing {
+		if entrie.Weekday == *req.Weekday && entrie.StartTime < *req.End && *req.Start < entrie.EndTime {
+			problem.Conflict(w, r, "The working hours overlap "+entrie.StartTime+"-"+entrie.EndTime+" of the same day")
+			return
+		}
+	}
+
+	// Convert the requested data into dbmodel.WorkingHoursEntry type for the "Create" function
+	workingHoursEntry := &dbmodel.WorkingHoursEntry{Vet: *req.Vet, Weekday: *req.Weekday, StartTime: *req.Start, EndTime: *req.End}
+
+	// Request the DB to Create the informations
+	entries, err := config.WorkingHoursEntryRepository.WithContext(audit.Context(r)).Create(workingHoursEntry)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Create the working hours")
+		return
+	}
+
+	// Set up to a dedicated type for the response
+	res := workingHoursResponse(entries)
+
+	render.JSON(w, r, res)
+}
+
+// DeleteWorkingHoursHandler godoc
+// @Summary      Delete working hours
+// @Description  Removes a range of working hours, the appointments already booked in it are kept
+// @Tags         appointments
+// @Produce      json
+// @Param        id   path      int  true  "Working hours ID"
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]string  "Working hours deleted successfully"
+// @Failure      400  {object}  problem.Problem  "Invalid request"
+// @Failure      404  {object}  problem.Problem  "Working hours not found"
+// @Failure      500  {object}  problem.Problem  "Failed to delete the working hours"
+// @Router       /appointments/working-hours/{id} [delete]
+func (config *AppointmentConfig) DeleteWorkingHoursHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the id in the URL
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		problem.NotFound(w, r, "Missing id")
+		return
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		problem.BadRequest(w, r, "id must be an integer")
+		return
+	}
+
+	// Request the DB to Delete the informations
+	if err := config.WorkingHoursEntryRepository.WithContext(audit.Context(r)).DeleteById(id); err != nil {
+		problem.Error(w, r, err, "Failed to Delete the working hours")
+		return
+	}
+
+	render.JSON(w, r, map[string]string{"message": "Working hours deleted successfully"})
+}
+
+// GetHolidaysHandler godoc
+// @Summary      Get the holidays
+// @Description  Retrieves the holidays of the vets and of the whole clinic, a holiday without a vet closes the clinic. With a vet, its holidays are given with the ones of the clinic.
+// @Tags         appointments
+// @Produce      json
+// @Param        vet  query     string  false  "Holidays of a vet"
+// @Security     BearerAuth
+// @Success      200  {array}   model.HolidayResponse
+// @Failure      500  {object}  problem.Problem  "Failed to retrieve the holidays"
+// @Router       /appointments/holidays [get]
+func (config *AppointmentConfig) GetHolidaysHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Request the DB to get the needed informations
+	entries, err := config.HolidayEntryRepository.FindAll(r.URL.Query().Get("vet"), time.Time{}, time.Time{})
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find the holidays")
+		return
+	}
+
+	// Set up to a dedicated type for the response
+	res := []*model.HolidayResponse{}
+	for _, entrie := range entries {
+		res = append(res, holidayResponse(entrie, config.ClinicLocation))
+	}
+
+	render.JSON(w, r, res)
+}
+
+// PostHolidayHandler godoc
+// @Summary      Add a holiday
+// @Description  Adds a time off to a vet, or to the whole clinic without a vet. No appointment can be booked during it, the appointments already booked are kept. The times are in RFC 3339 with their offset.
+// @Tags         appointments
+// @Accept       json
+// @Produce      json
+// @Param        holiday  body      model.HolidayRequest  true  "Holiday payload"
+// @Security     BearerAuth
+// @Success      200  {object}  model.HolidayResponse
+// @Failure      400  {object}  problem.Problem  "Invalid request payload"
+// @Failure      422  {object}  problem.Problem  "Validation failed"
+// @Failure      500  {object}  problem.Problem  "Failed to add the holiday"
+// @Router       /appointments/holidays [post]
+func (config *AppointmentConfig) PostHolidayHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the request
+	req := &model.HolidayRequest{}
+	if err := render.Bind(r, req); err != nil {
+		problem.Bind(w, r, err, "Invalid Holiday Post request payload")
+		return
+	}
+
+	// Convert the requested data into dbmodel.HolidayEntry type for the "Create" function, the times are saved in UTC
+	holidayEntry := &dbmodel.HolidayEntry{Vet: *req.Vet, Name: *req.Name, StartsAt: req.StartsAt.UTC(), EndsAt: req.EndsAt.UTC()}
+
+	// Request the DB to Create the informations
+	entries, err := config.HolidayEntryRepository.WithContext(audit.Context(r)).Create(holidayEntry)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Create the holiday")
+		return
+	}
+
+	// Set up to a dedicated type for the response
+	res := holidayResponse(entries, config.ClinicLocation)
+
+	render.JSON(w, r, res)
+}
+
+// DeleteHolidayHandler godoc
+// @Summary      Delete a holiday
+// @Description  Removes a holiday, its time can be booked again
+// @Tags         appointments
+// @Produce      json
+// @Param        id   path      int  true  "Holiday ID"
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]string  "Holiday deleted successfully"
+// @Failure      400  {object}  problem.Problem  "Invalid request"
+// @Failure      404  {object}  problem.Problem  "Holiday not found"
+// @Failure      500  {object}  problem.Problem  "Failed to delete the holiday"
+// @Router       /appointments/holidays/{id} [delete]
+func (config *AppointmentConfig) DeleteHolidayHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the id in the URL
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		problem.NotFound(w, r, "Missing id")
+		return
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		problem.BadRequest(w, r, "id must be an integer")
+		return
+	}
+
+	// Request the DB to Delete the informations
+	if err := config.HolidayEntryRepository.WithContext(audit.Context(r)).DeleteById(id); err != nil {
+		problem.Error(w, r, err, "Failed to Delete the holiday")
+		return
+	}
+
+	render.JSON(w, r, map[string]string{"message": "Holiday deleted successfully"})
+}
+
+// GetReasonsHandler godoc
+// @Summary      Get the durations of the reasons
+// @Description  Retrieves the durations in minutes of the appointments by reason of visit, the other reasons last 30 minutes
+// @Tags         appointments
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   model.AppointmentReasonResponse
+// @Failure      500  {object}  problem.Problem  "Failed to retrieve the reasons"
+// @Router       /appointments/reasons [get]
+func (config *AppointmentConfig) GetReasonsHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Request the DB to get the needed informations
+	entries, err := config.AppointmentReasonEntryRepository.FindAll()
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find the reasons")
+		return
+	}
+
+	// Set up to a dedicated type for the response
+	res := []*model.AppointmentReasonResponse{}
+	for _, entrie := range entries {
+		res = append(res, &model.AppointmentReasonResponse{Id: entrie.ID, Name: entrie.Name, Duration: entrie.Duration})
+	}
+
+	render.JSON(w, r, res)
+}
+
+// PostReasonHandler godoc
+// @Summary      Add the duration of a reason
+// @Description  Sets the duration in minutes of the appointments booked for a reason of visit, the reasons are matched without case
+// @Tags         appointments
+// @Accept       json
+// @Produce      json
+// @Param        reason  body      model.AppointmentReasonRequest  true  "Reason payload"
+// @Security     BearerAuth
+// @Success      200  {object}  model.AppointmentReasonResponse
+// @Failure      400  {object}  problem.Problem  "Invalid request payload"
+// @Failure      409  {object}  problem.Problem  "Reason already exists"
+// @Failure      422  {object}  problem.Problem  "Validation failed"
+// @Failure      500  {object}  problem.Problem  "Failed to add the reason"
+// @Router       /appointments/reasons [post]
+func (config *AppointmentConfig) PostReasonHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the request
+	req := &model.AppointmentReasonRequest{}
+	if err := render.Bind(r, req); err != nil {
+		problem.Bind(w, r, err, "Invalid Reason Post request payload")
+		return
+	}
+
+	// The names are unique without case
+	if _, err := config.AppointmentReasonEntryRepository.FindByName(*req.Name); err == nil {
+		problem.AlreadyExists(w, r, "Reason already exists")
+		return
+	}
+
+	// Request the DB to Create the informations
+	entries, err := config.AppointmentReasonEntryRepository.WithContext(audit.Context(r)).Create(&dbmodel.AppointmentReasonEntry{Name: *req.Name, Duration: *req.Duration})
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Create the reason")
+		return
+	}
+
+	// Set up to a dedicated type for the response
+	res := &model.AppointmentReasonResponse{Id: entries.ID, Name: entries.Name, Duration: entries.Duration}
+
+	render.JSON(w, r, res)
+}
+
+// UpdateReasonHandler godoc
+// @Summary      Update the duration of a reason
+// @Description  Changes the name or the duration of a reason, the appointments already booked keep their duration
+// @Tags         appointments
+// @Accept       json
+// @Produce      json
+// @Param        id      path      int                             true  "Reason ID"
+// @Param        reason  body      model.AppointmentReasonRequest  true  "Reason payload"
+// @Security     BearerAuth
+// @Success      200  {object}  model.AppointmentReasonResponse
+// @Failure      400  {object}  problem.Problem  "Invalid request payload"
+// @Failure      404  {object}  problem.Problem  "Reason not found"
+// @Failure      409  {object}  problem.Problem  "Reason already exists"
+// @Failure      422  {object}  problem.Problem  "Validation failed"
+// @Failure      500  {object}  problem.Problem  "Failed to update the reason"
+// @Router       /appointments/reasons/{id} [put]
+func (config *AppointmentConfig) UpdateReasonHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the id in the URL
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		problem.NotFound(w, r, "Missing id")
+		return
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		problem.BadRequest(w, r, "id must be an integer")
+		return
+	}
+
+	// Get the request
+	req := &model.AppointmentReasonRequest{}
+	if err := render.Bind(r, req); err != nil {
+		problem.Bind(w, r, err, "Invalid Reason Update request payload")
+		return
+	}
+
+	// Check if the reason existe
+	before, err := config.AppointmentReasonEntryRepository.FindById(id)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find the reason")
+		return
+	}
+
+	// The names are unique without case
+	if other, err := config.AppointmentReasonEntryRepository.FindByName(*req.Name); err == nil && other.ID != before.ID {
+		problem.AlreadyExists(w, r, "Reason already exists")
+		return
+	}
+
+	// Request the DB to Update the informations
+	entries, err := config.AppointmentReasonEntryRepository.WithContext(audit.Context(r)).Update(id, &dbmodel.AppointmentReasonEntry{Name: *req.Name, Duration: *req.Duration})
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Update the reason")
+		return
+	}
+
+	// Set up to a dedicated type for the response
+	res := &model.AppointmentReasonResponse{Id: before.ID, Name: entries.Name, Duration: entries.Duration}
+
+	render.JSON(w, r, res)
+}
+
+// DeleteReasonHandler godoc
+// @Summary      Delete the duration of a reason
+// @Description  Removes the duration of a reason, its appointments last 30 minutes again
+// @Tags         appointments
+// @Produce      json
+// @Param        id   path      int  true  "Reason ID"
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]string  "Reason deleted successfully"
+// @Failure      400  {object}  problem.Problem  "Invalid request"
+// @Failure      404  {object}  problem.Problem  "Reason not found"
+// @Failure      500  {object}  problem.Problem  "Failed to delete the reason"
+// @Router       /appointments/reasons/{id} [delete]
+func (config *AppointmentConfig) DeleteReasonHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the id in the URL
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		problem.NotFound(w, r, "Missing id")
+		return
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		problem.BadRequest(w, r, "id must be an integer")
+		return
+	}
+
+	// Request the DB to Delete the informations
+	if err := config.AppointmentReasonEntryRepository.WithContext(audit.Context(r)).DeleteById(id); err != nil {
+		problem.Error(w, r, err, "Failed to Delete the reason")
+		return
+	}
+
+	render.JSON(w, r, map[string]string{"message": "Reason deleted successfully"})
+}
+
+// Set up working hours to a dedicated type for the responses
+func workingHoursResponse(entries *dbmodel.WorkingHoursEntry) *model.WorkingHoursResponse {
+
+	return &model.WorkingHoursResponse{
+		Id:      entries.ID,
+		Vet:     entries.Vet,
+		Weekday: entries.Weekday,
+		Start:   entries.StartTime,
+		End:     entries.EndTime}
+}
+
+// Set up a holiday to a dedicated type for the responses, the times are given in the timezone of the clinic
+func holidayResponse(entries *dbmodel.HolidayEntry, location *time.Location) *model.HolidayResponse {
+
+	return &model.HolidayResponse{
+		Id:       entries.ID,
+		Vet:      entries.Vet,
+		Name:     entries.Name,
+		StartsAt: entries.StartsAt.In(location),
+		EndsAt:   entries.EndsAt.In(location)}
+}
@@ -0,0 +1,519 @@
+package appointment
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+	"vet-clinic-api/config"
+	"vet-clinic-api/database/dbmodel"
+	"vet-clinic-api/pkg/audit"
+	"vet-clinic-api/pkg/authentication"
+	"vet-clinic-api/pkg/model"
+	"vet-clinic-api/pkg/pagination"
+	"vet-clinic-api/pkg/problem"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// Fields of the response used to sort the appointments, with their column
+var sortFields = map[string]string{
+	"id":                    "id",
+	"appointment_starts_at": "starts_at",
+	"appointment_vet":       "vet",
+	"appointment_status":    "status",
+}
+
+// Statuses accepted by the status filter
+var statuses = []string{
+	dbmodel.AppointmentScheduled,
+	dbmodel.AppointmentCompleted,
+	dbmodel.AppointmentCancelled,
+	dbmodel.AppointmentRescheduled,
+	dbmodel.AppointmentNoShow,
+}
+
+type AppointmentConfig struct {
+	*config.Config
+}
+
+func New(configuration *config.Config) *AppointmentConfig {
+	return &AppointmentConfig{configuration}
+}
+
+// PostHandler godoc
+// @Summary      Book an appointment
+// @Description  Books an appointment of a cat with a vet. Without an end, the appointment lasts the duration of its reason (30 minutes for an unknown reason). It must fit in the working hours of the vet, outside the holidays, and the vet and the cat must not have another scheduled appointment at this time. The times are in RFC 3339 with their offset, they are given back in the timezone of the clinic.
+// @Tags         appointments
+// @Accept       json
+// @Produce      json
+// @Param        appointment  body      model.AppointmentRequest  true  "Appointment booking payload"
+// @Security     BearerAuth
+// @Success      200  {object}  model.AppointmentResponse
+// @Failure      400  {object}  problem.Problem  "Invalid request payload"
+// @Failure      409  {object}  problem.Problem  "Time in the past, not available in the calendar of the vet, or already booked"
+// @Failure      422  {object}  problem.Problem  "Validation failed"
+// @Failure      500  {object}  problem.Problem  "Failed to book appointment"
+// @Router       /appointments [post]
+func (config *AppointmentConfig) PostHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the request
+	req := &model.AppointmentRequest{}
+	if err := render.Bind(r, req); err != nil {
+		problem.Bind(w, r, err, "Invalid Appointment Post request payload")
+		return
+	}
+
+	// Check if the linked cat id existe
+	if !config.CatEntryRepository.FindLastCatId(int(*req.CatId)) {
+		problem.InvalidReference(w, r, "CatId not found in the DB")
+		return
+	}
+
+	// Convert the requested data into dbmodel.AppointmentEntry type for the "Book" function
+	appointmentEntry, err := config.appointmentEntry(*req.CatId, *req.Vet, *req.Reason, *req.StartsAt, req.EndsAt)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find the duration of the reason")
+		return
+	}
+
+	// Request the DB to Book the appointment in the calendar of the vet
+	entries, ok := config.book(w, r, appointmentEntry, 0)
+	if !ok {
+		return
+	}
+
+	// Set up to a dedicated type for the response
+	res := appointmentResponse(entries, config.ClinicLocation)
+
+	render.JSON(w, r, res)
+}
+
+// GetAllHandler godoc
+// @Summary      Get all appointments
+// @Description  Retrieves the appointments by page, optionally filtered. The total count is in the X-Total-Count header and the other pages in the Link header.
+// @Tags         appointments
+// @Produce      json
+// @Param        cat_id     query     int     false  "Filter by cat id"
+// @Param        vet        query     string  false  "Filter by veterinarian name"
+// @Param        status     query     string  false  "Filter by status (scheduled, completed, cancelled, rescheduled, no_show)"
+// @Param        date_from  query     string  false  "Appointments starting from this day included, in the timezone of the clinic (format: YYYY-MM-DD)"
+// @Param        date_to    query     string  false  "Appointments starting until this day included (format: YYYY-MM-DD)"
+// @Param        limit      query     int     false  "Number of appointments of the page (default 50, max 200)"
+// @Param        offset     query     int     false  "Number of appointments skipped, instead of the cursor"
+// @Param        cursor     query     string  false  "Cursor of the next page, given in the Link header"
+// @Param        sort       query     string  false  "Fields separated by commas, - for descending (id, appointment_starts_at, appointment_vet, appointment_status)"
+// @Security     BearerAuth
+// @Success      200  {array}   model.AppointmentResponse
+// @Header       200  {integer}  X-Total-Count  "Number of appointments matching the filters"
+// @Header       200  {string}   Link           "Links to the first, previous, next and last pages"
+// @Failure      400  {object}  problem.Problem  "Invalid filter or page"
+// @Failure      500  {object}  problem.Problem  "Failed to retrieve appointments"
+// @Router       /appointments [get]
+func (config *AppointmentConfig) GetAllHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Set up the filters
+	values := r.URL.Query()
+	filter := dbmodel.AppointmentFilter{Vet: values.Get("vet"), Status: values.Get("status")}
+
+	if value := values.Get("cat_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			problem.BadRequest(w, r, "cat_id must be a positive integer")
+			return
+		}
+		filter.CatId = uint(id)
+	}
+
+	if filter.Status != "" && !slices.Contains(statuses, filter.Status) {
+		problem.BadRequest(w, r, "status must be scheduled, completed, cancelled, rescheduled or no_show")
+		return
+	}
+
+	for _, item := range []struct {
+		name  string
+		value *time.Time
+		days  int
+	}{
+		{"date_from", &filter.StartsFrom, 0},
+		{"date_to", &filter.StartsBefore, 1},
+	} {
+		if value := values.Get(item.name); value != "" {
+			day, err := time.ParseInLocation("2006-01-02", value, config.ClinicLocation)
+			if err != nil {
+				problem.BadRequest(w, r, item.name+" wrong format, expected YYYY-MM-DD")
+				return
+			}
+
+			// The last day is included until the start of the next day
+			*item.value = day.AddDate(0, 0, item.days)
+		}
+	}
+
+	// Owners only get the appointments of their own cats, none without owner id
+	if ownerId, ok := authentication.OwnerIdFromContext(r.Context()); ok {
+		filter.OwnerId = &ownerId
+	}
+
+	page, err := pagination.Parse(r, sortFields)
+	if err != nil {
+		problem.BadRequest(w, r, err.Error())
+		return
+	}
+
+	// Request the DB to get the needed informations
+	entries, info, err := config.AppointmentEntryRepository.FindAll(filter, page)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find All Appointments")
+		return
+	}
+
+	// Set up to a dedicated type for the response
+	res := []*model.AppointmentResponse{}
+	for _, entrie := range entries {
+		res = append(res, appointmentResponse(entrie, config.ClinicLocation))
+	}
+
+	pagination.Write(w, r, page, info)
+	render.JSON(w, r, res)
+}
+
+// GetByIdHandler godoc
+// @Summary      Get appointment by ID
+// @Description  Retrieves a specific appointment from the database by its ID
+// @Tags         appointments
+// @Produce      json
+// @Param        id   path      int  true  "Appointment ID"
+// @Security     BearerAuth
+// @Success      200  {object}  model.AppointmentResponse
+// @Failure      400  {object}  problem.Problem  "Invalid request"
+// @Failure      403  {object}  problem.Problem  "Forbidden for an owner account"
+// @Failure      404  {object}  problem.Problem  "Appointment not found"
+// @Failure      500  {object}  problem.Problem  "Failed to find specific appointment"
+// @Router       /appointments/{id} [get]
+func (config *AppointmentConfig) GetByIdHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the id in the URL
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		problem.NotFound(w, r, "Missing id")
+		return
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		problem.BadRequest(w, r, "id must be an integer")
+		return
+	}
+
+	// Request the DB to Get the needed informations
+	entries, err := config.AppointmentEntryRepository.FindById(id)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find specific Appointment")
+		return
+	}
+
+	// Owners can only read the appointments of their own cats
+	if ownerId, ok := authentication.OwnerIdFromContext(r.Context()); ok && !config.CatEntryRepository.BelongsToOwner(int(entries.CatId), ownerId) {
+		problem.Forbidden(w, r, "Appointment not owned by the user")
+		return
+	}
+
+	render.JSON(w, r, appointmentResponse(entries, config.ClinicLocation))
+}
+
+// RescheduleHandler godoc
+// @Summary      Reschedule an appointment
+// @Description  Replaces a scheduled appointment with a new one at another time, optionally with another vet. The old appointment is kept with the rescheduled status and the new one gives its id in appointment_rescheduled_from_id. The new time is checked like a booking.
+// @Tags         appointments
+// @Accept       json
+// @Produce      json
+// @Param        id           path      int                      true  "Appointment ID"
+// @Param        appointment  body      model.RescheduleRequest  true  "New time of the appointment"
+// @Security     BearerAuth
+// @Success      200  {object}  model.AppointmentResponse  "The new appointment"
+// @Failure      400  {object}  problem.Problem  "Invalid request payload"
+// @Failure      404  {object}  problem.Problem  "Appointment not found"
+// @Failure      409  {object}  problem.Problem  "Appointment no longer scheduled, or time in the past or not available"
+// @Failure      422  {object}  problem.Problem  "Validation failed"
+// @Failure      500  {object}  problem.Problem  "Failed to reschedule appointment"
+// @Router       /appointments/{id}/reschedule [post]
+func (config *AppointmentConfig) RescheduleHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the id in the URL
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		problem.NotFound(w, r, "Missing id")
+		return
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		problem.BadRequest(w, r, "id must be an integer")
+		return
+	}
+
+	// Get the request
+	req := &model.RescheduleRequest{}
+	if err := render.Bind(r, req); err != nil {
+		problem.Bind(w, r, err, "Invalid Appointment Reschedule request payload")
+		return
+	}
+
+	// Request the DB to get the appointment, its status and time are checked before the change
+	before, err := config.AppointmentEntryRepository.FindById(id)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find specific Appointment")
+		return
+	}
+
+	if before.Status != dbmodel.AppointmentScheduled {
+		problem.Conflict(w, r, "The appointment is "+before.Status+", only a scheduled appointment can be changed")
+		return
+	}
+
+	// The cat and the reason are kept, the vet too unless another one is requested
+	vet := before.Vet
+	if req.Vet != nil && *req.Vet != "" {
+		vet = *req.Vet
+	}
+
+	appointmentEntry, err := config.appointmentEntry(before.CatId, vet, before.Reason, *req.StartsAt, req.EndsAt)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find the duration of the reason")
+		return
+	}
+
+	// Request the DB to replace the appointment
+	entries, ok := config.book(w, r, appointmentEntry, id)
+	if !ok {
+		return
+	}
+
+	// Set up to a dedicated type for the response
+	res := appointmentResponse(entries, config.ClinicLocation)
+
+	render.JSON(w, r, res)
+}
+
+// CancelHandler godoc
+// @Summary      Cancel an appointment
+// @Description  Cancels a scheduled appointment, its time is free again
+// @Tags         appointments
+// @Produce      json
+// @Param        id   path      int  true  "Appointment ID"
+// @Security     BearerAuth
+// @Success      200  {object}  model.AppointmentResponse
+// @Failure      400  {object}  problem.Problem  "Invalid request"
+// @Failure      404  {object}  problem.Problem  "Appointment not found"
+// @Failure      409  {object}  problem.Problem  "Appointment no longer scheduled"
+// @Failure      500  {object}  problem.Problem  "Failed to cancel appointment"
+// @Router       /appointments/{id}/cancel [post]
+func (config *AppointmentConfig) CancelHandler(w http.ResponseWriter, r *http.Request) {
+	config.close(w, r, dbmodel.AppointmentCancelled)
+}
+
+// NoShowHandler godoc
+// @Summary      Mark an appointment as a no-show
+// @Description  Closes a scheduled appointment the cat didn't come to, once the appointment has started
+// @Tags         appointments
+// @Produce      json
+// @Param        id   path      int  true  "Appointment ID"
+// @Security     BearerAuth
+// @Success      200  {object}  model.AppointmentResponse
+// @Failure      400  {object}  problem.Problem  "Invalid request"
+// @Failure      404  {object}  problem.Problem  "Appointment not found"
+// @Failure      409  {object}  problem.Problem  "Appointment no longer scheduled or not started yet"
+// @Failure      500  {object}  problem.Problem  "Failed to close appointment"
+// @Router       /appointments/{id}/no-show [post]
+func (config *AppointmentConfig) NoShowHandler(w http.ResponseWriter, r *http.Request) {
+	config.close(w, r, dbmodel.AppointmentNoShow)
+}
+
+// CompleteHandler godoc
+// @Summary      Complete an appointment
+// @Description  Records the visit of a scheduled appointment once it has started, with the cat, vet, reason and times of the appointment. The appointment is closed as completed and gives the id of the visit in appointment_visit_id. Needs the visits:write permission too.
+// @Tags         appointments
+// @Produce      json
+// @Param        id   path      int  true  "Appointment ID"
+// @Security     BearerAuth
+// @Success      200  {object}  model.AppointmentResponse
+// @Failure      400  {object}  problem.Problem  "Invalid request"
+// @Failure      404  {object}  problem.Problem  "Appointment not found"
+// @Failure      409  {object}  problem.Problem  "Appointment no longer scheduled or not started yet, or cat in the trash"
+// @Failure      500  {object}  problem.Problem  "Failed to complete appointment"
+// @Router       /appointments/{id}/complete [post]
+func (config *AppointmentConfig) CompleteHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the id in the URL
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		problem.NotFound(w, r, "Missing id")
+		return
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		problem.BadRequest(w, r, "id must be an integer")
+		return
+	}
+
+	// Request the DB to get the appointment, its status and time are checked before the change
+	before, err := config.AppointmentEntryRepository.FindById(id)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find specific Appointment")
+		return
+	}
+
+	if before.StartsAt.After(time.Now()) {
+		problem.Conflict(w, r, "The appointment has not started yet")
+		return
+	}
+
+	// Request the DB to record the visit and close the appointment
+	entries, _, err := config.AppointmentEntryRepository.WithContext(audit.Context(r)).Complete(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, dbmodel.ErrAppointmentClosed):
+			problem.Conflict(w, r, "The appointment is "+before.Status+", only a scheduled appointment can be changed")
+		case errors.Is(err, dbmodel.ErrParentDeleted):
+			problem.Conflict(w, r, "The cat of the appointment is in the trash, restore it first")
+		default:
+			problem.Error(w, r, err, "Failed to Complete Appointment")
+		}
+		return
+	}
+
+	// Set up to a dedicated type for the response
+	res := appointmentResponse(entries, config.ClinicLocation)
+
+	render.JSON(w, r, res)
+}
+
+// Close a scheduled appointment with a status, a no-show is only known once the appointment has started
+func (config *AppointmentConfig) close(w http.ResponseWriter, r *http.Request, status string) {
+
+	// Get the id in the URL
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		problem.NotFound(w, r, "Missing id")
+		return
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		problem.BadRequest(w, r, "id must be an integer")
+		return
+	}
+
+	// Request the DB to get the appointment, its status and time are checked before the change
+	before, err := config.AppointmentEntryRepository.FindById(id)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find specific Appointment")
+		return
+	}
+
+	if status == dbmodel.AppointmentNoShow && before.StartsAt.After(time.Now()) {
+		problem.Conflict(w, r, "The appointment has not started yet")
+		return
+	}
+
+	// Request the DB to close the appointment
+	entries, err := config.AppointmentEntryRepository.WithContext(audit.Context(r)).Close(id, status)
+	if err != nil {
+		if errors.Is(err, dbmodel.ErrAppointmentClosed) {
+			problem.Conflict(w, r, "The appointment is "+before.Status+", only a scheduled appointment can be changed")
+			return
+		}
+		problem.Error(w, r, err, "Failed to Close Appointment")
+		return
+	}
+
+	// Set up to a dedicated type for the response
+	res := appointmentResponse(entries, config.ClinicLocation)
+
+	render.JSON(w, r, res)
+}
+
+// Book an appointment after checking the calendar of its vet, or replace the rescheduled appointment with it.
+// The problem is written when the appointment can't be booked.
+func (config *AppointmentConfig) book(w http.ResponseWriter, r *http.Request, entry *dbmodel.AppointmentEntry, rescheduledId int) (*dbmodel.AppointmentEntry, bool) {
+
+	// Checked again here, the time may have passed since the validation of the request
+	if !entry.StartsAt.After(time.Now()) {
+		problem.Conflict(w, r, "The appointment must start in the future")
+		return nil, false
+	}
+
+	reason, err := config.unavailable(entry)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find the calendar of the vet")
+		return nil, false
+	}
+	if reason != "" {
+		problem.Conflict(w, r, reason)
+		return nil, false
+	}
+
+	repository := config.AppointmentEntryRepository.WithContext(audit.Context(r))
+
+	var entries *dbmodel.AppointmentEntry
+	if rescheduledId != 0 {
+		entries, err = repository.Reschedule(rescheduledId, entry)
+	} else {
+		entries, err = repository.Book(entry)
+	}
+
+	if err != nil {
+		switch {
+		case errors.Is(err, dbmodel.ErrAppointmentConflict):
+			problem.Conflict(w, r, "The vet or the cat already has an appointment at this time")
+		case errors.Is(err, dbmodel.ErrAppointmentClosed):
+			problem.Conflict(w, r, "Only a scheduled appointment can be changed")
+		default:
+			problem.Error(w, r, err, "Failed to Book Appointment")
+		}
+		return nil, false
+	}
+
+	return entries, true
+}
+
+// Convert the requested data into dbmodel.AppointmentEntry type, without an end the appointment lasts the duration of its reason.
+// The times are saved in UTC to be ordered.
+func (config *AppointmentConfig) appointmentEntry(catId uint, vet string, reason string, startsAt time.Time, endsAt *time.Time) (*dbmodel.AppointmentEntry, error) {
+
+	entry := &dbmodel.AppointmentEntry{CatId: catId, Vet: vet, Reason: reason, StartsAt: startsAt.UTC()}
+
+	if endsAt != nil {
+		entry.EndsAt = endsAt.UTC()
+		return entry, nil
+	}
+
+	duration, err := config.duration(reason)
+	if err != nil {
+		return nil, err
+	}
+	entry.EndsAt = entry.StartsAt.Add(duration)
+
+	return entry, nil
+}
+
+// Set up an appointment to a dedicated type for the responses, the times are given in the timezone of the clinic
+func appointmentResponse(entries *dbmodel.AppointmentEntry, location *time.Location) *model.AppointmentResponse {
+
+	return &model.AppointmentResponse{
+		Id:                entries.ID,
+		CatId:             entries.CatId,
+		Vet:               entries.Vet,
+		Reason:            entries.Reason,
+		StartsAt:          entries.StartsAt.In(location),
+		EndsAt:            entries.EndsAt.In(location),
+		Status:            entries.Status,
+		RescheduledFromId: entries.RescheduledFromId,
+		VisitId:           entries.VisitId,
+		CreatedBy:         entries.CreatedBy,
+		UpdatedBy:         entries.UpdatedBy}
+}
@@ -0,0 +1,54 @@
+package appointment
+
+import (
+	"vet-clinic-api/config"
+	"vet-clinic-api/pkg/authentication"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func Routes(configuration *config.Config) chi.Router {
+
+	// Init Router
+	appointmentConfig := New(configuration)
+	router := chi.NewRouter()
+
+	// Routes protected by authentication
+	router.Group(func(router chi.Router) {
+		router.Use(authentication.AuthMiddleware(appointmentConfig.AccessTokenKeys, appointmentConfig.RevocationStore, appointmentConfig.ApiKeyStore, appointmentConfig.RoleEntryRepository))
+
+		// Routes protected by authentication and accessible with the "appointments:read" permission
+		router.With(authentication.RequirePermission("appointments:read")).Group(func(r chi.Router) {
+			r.Get("/", appointmentConfig.GetAllHandler)
+			r.Get("/slots", appointmentConfig.GetSlotsHandler)
+			r.Get("/working-hours", appointmentConfig.GetWorkingHoursHandler)
+			r.Get("/holidays", appointmentConfig.GetHolidaysHandler)
+			r.Get("/reasons", appointmentConfig.GetReasonsHandler)
+			r.Get("/{id}", appointmentConfig.GetByIdHandler)
+		})
+
+		// Routes protected by authentication and accessible with the "appointments:write" permission
+		router.With(authentication.RequirePermission("appointments:write")).Group(func(r chi.Router) {
+			r.Post("/", appointmentConfig.PostHandler)
+			r.Post("/{id}/reschedule", appointmentConfig.RescheduleHandler)
+			r.Post("/{id}/cancel", appointmentConfig.CancelHandler)
+			r.Post("/{id}/no-show", appointmentConfig.NoShowHandler)
+
+			// The completion records a visit
+			r.With(authentication.RequirePermission("visits:write")).Post("/{id}/complete", appointmentConfig.CompleteHandler)
+		})
+
+		// Routes protected by authentication and accessible with the "calendars:write" permission
+		router.With(authentication.RequirePermission("calendars:write")).Group(func(r chi.Router) {
+			r.Post("/working-hours", appointmentConfig.PostWorkingHoursHandler)
+			r.Delete("/working-hours/{id}", appointmentConfig.DeleteWorkingHoursHandler)
+			r.Post("/holidays", appointmentConfig.PostHolidayHandler)
+			r.Delete("/holidays/{id}", appointmentConfig.DeleteHolidayHandler)
+			r.Post("/reasons", appointmentConfig.PostReasonHandler)
+			r.Put("/reasons/{id}", appointmentConfig.UpdateReasonHandler)
+			r.Delete("/reasons/{id}", appointmentConfig.DeleteReasonHandler)
+		})
+	})
+
+	return router
+}
@@ -0,0 +1,129 @@
+package appointment
+
+import (
+	"errors"
+	"time"
+	"vet-clinic-api/database/dbmodel"
+
+	"gorm.io/gorm"
+)
+
+// Step between the start times of the free slots
+const SlotStep = 15 * time.Minute
+
+// Duration of the appointments for a reason without a configured duration
+const DefaultDuration = 30 * time.Minute
+
+// Time range, the end is excluded
+type period struct {
+	start time.Time
+	end   time.Time
+}
+
+func (p period) overlaps(other period) bool {
+	return p.start.Before(other.end) && other.start.Before(p.end)
+}
+
+func (p period) contains(other period) bool {
+	return !other.start.Before(p.start) && !other.end.After(p.end)
+}
+
+// Duration of an appointment for its reason
+func (config *AppointmentConfig) duration(reason string) (time.Duration, error) {
+
+	entry, err := config.AppointmentReasonEntryRepository.FindByName(reason)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return DefaultDuration, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(entry.Duration) * time.Minute, nil
+}
+
+// Reason why an appointment can't be booked in the calendar of its vet, empty when the time is free.
+// The appointment must fit in a range of working hours and not overlap a holiday, the other appointments are checked when it is booked.
+func (config *AppointmentConfig) unavailable(entry *dbmodel.AppointmentEntry) (string, error) {
+
+	hours, err := config.WorkingHoursEntryRepository.FindAll(entry.Vet)
+	if err != nil {
+		return "", err
+	}
+
+	appointment := period{entry.StartsAt, entry.EndsAt}
+	day := startOfDay(entry.StartsAt.In(config.ClinicLocation))
+
+	working := false
+	for _, hoursRange := range workingRanges(hours, day) {
+		if hoursRange.contains(appointment) {
+			working = true
+		}
+	}
+	if !working {
+		return "The appointment is outside the working hours of " + entry.Vet, nil
+	}
+
+	holidays, err := config.HolidayEntryRepository.FindAll(entry.Vet, entry.StartsAt, entry.EndsAt)
+	if err != nil {
+		return "", err
+	}
+	if len(holidays) > 0 {
+		return "The appointment is during the holiday " + holidays[0].Name, nil
+	}
+
+	return "", nil
+}
+
+// Ranges of the working hours on a day, the day is at midnight in the timezone of the clinic
+func workingRanges(hours []*dbmodel.WorkingHoursEntry, day time.Time) []period {
+
+	var ranges []period
+	for _, entrie := range hours {
+		if entrie.Weekday == int(day.Weekday()) {
+			ranges = append(ranges, period{clock(day, entrie.StartTime), clock(day, entrie.EndTime)})
+		}
+	}
+
+	return ranges
+}
+
+// Free slots in the working hours, an appointment of the duration fits in each of them without overlapping the busy times
+func freeSlots(ranges []period, busy []period, duration time.Duration, now time.Time) []period {
+
+	var slots []period
+	for _, hoursRange := range ranges {
+		for start := hoursRange.start; !start.Add(duration).After(hoursRange.end); start = start.Add(SlotStep) {
+			slot := period{start, start.Add(duration)}
+			if start.Before(now) {
+				continue
+			}
+
+			free := true
+			for _, taken := range busy {
+				if slot.overlaps(taken) {
+					free = false
+					break
+				}
+			}
+
+			if free {
+				slots = append(slots, slot)
+			}
+		}
+	}
+
+	return slots
+}
+
+// Time of a day for a time of the day with the format HH:MM, in the timezone of the day
+func clock(day time.Time, value string) time.Time {
+
+	parsed, _ := time.Parse("15:04", value)
+	return time.Date(day.Year(), day.Month(), day.Day(), parsed.Hour(), parsed.Minute(), 0, 0, day.Location())
+}
+
+// Midnight of the day of a time, in the timezone of the time
+func startOfDay(value time.Time) time.Time {
+	return time.Date(value.Year(), value.Month(), value.Day(), 0, 0, 0, 0, value.Location())
+}
@@ -0,0 +1,146 @@
+package appointment
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"vet-clinic-api/config"
+	"vet-clinic-api/database/dbmodel"
+)
+
+func TestPeriod(t *testing.T) {
+
+	at := func(hour int, minute int) time.Time {
+		return time.Date(2030, 3, 18, hour, minute, 0, 0, time.UTC)
+	}
+	morning := period{at(9, 0), at(12, 0)}
+
+	tests := []struct {
+		name     string
+		other    period
+		overlaps bool
+		contains bool
+	}{
+		{"inside", period{at(10, 0), at(10, 30)}, true, true},
+		{"same range", morning, true, true},
+		{"across the start", period{at(8, 30), at(9, 30)}, true, false},
+		{"across the end", period{at(11, 45), at(12, 15)}, true, false},
+		{"ending at the start", period{at(8, 30), at(9, 0)}, false, false},
+		{"starting at the end", period{at(12, 0), at(12, 30)}, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := morning.overlaps(tt.other); got != tt.overlaps {
+				t.Errorf("overlaps: got %t, want %t", got, tt.overlaps)
+			}
+			if got := morning.contains(tt.other); got != tt.contains {
+				t.Errorf("contains: got %t, want %t", got, tt.contains)
+			}
+		})
+	}
+}
+
+func TestWorkingRanges(t *testing.T) {
+
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skip("timezone database not available")
+	}
+
+	hours := []*dbmodel.WorkingHoursEntry{
+		{Weekday: int(time.Monday), StartTime: "09:00", EndTime: "12:00"},
+		{Weekday: int(time.Monday), StartTime: "14:00", EndTime: "18:00"},
+		{Weekday: int(time.Tuesday), StartTime: "08:00", EndTime: "16:00"},
+	}
+
+	tests := []struct {
+		name string
+		day  time.Time
+		want string
+	}{
+		{"two ranges", time.Date(2030, 3, 18, 0, 0, 0, 0, paris), "[09:00-12:00 14:00-18:00]"},
+		{"one range", time.Date(2030, 3, 19, 0, 0, 0, 0, paris), "[08:00-16:00]"},
+		{"day off", time.Date(2030, 3, 20, 0, 0, 0, 0, paris), "[]"},
+		{"summer time", time.Date(2030, 4, 1, 0, 0, 0, 0, paris), "[09:00-12:00 14:00-18:00]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranges := workingRanges(hours, tt.day)
+
+			var got []string
+			for _, hoursRange := range ranges {
+				if hoursRange.start.Location() != paris {
+					t.Errorf("got %s, want the timezone of the day", hoursRange.start.Location())
+				}
+				got = append(got, hoursRange.start.Format("15:04")+"-"+hoursRange.end.Format("15:04"))
+			}
+
+			if fmt.Sprint(got) != tt.want {
+				t.Errorf("got %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFreeSlots(t *testing.T) {
+
+	at := func(hour int, minute int) time.Time {
+		return time.Date(2030, 3, 18, hour, minute, 0, 0, time.UTC)
+	}
+	ranges := []period{{at(9, 0), at(10, 0)}, {at(14, 0), at(14, 45)}}
+
+	tests := []struct {
+		name     string
+		busy     []period
+		duration time.Duration
+		now      time.Time
+		want     string
+	}{
+		{"every 15 minutes", nil, 30 * time.Minute, at(0, 0), "[09:00 09:15 09:30 14:00 14:15]"},
+		{"longer than a range", nil, 75 * time.Minute, at(0, 0), "[]"},
+		{"around an appointment", []period{{at(9, 15), at(9, 30)}}, 15 * time.Minute, at(0, 0), "[09:00 09:30 09:45 14:00 14:15 14:30]"},
+		{"already passed", nil, 30 * time.Minute, at(9, 10), "[09:15 09:30 14:00 14:15]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, slot := range freeSlots(ranges, tt.busy, tt.duration, tt.now) {
+				got = append(got, slot.start.Format("15:04"))
+			}
+
+			if fmt.Sprint(got) != tt.want {
+				t.Errorf("got %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// A past time is refused before the calendar of the vet is read
+func TestBookInThePast(t *testing.T) {
+
+	appointmentConfig := New(&config.Config{ClinicLocation: time.UTC})
+
+	tests := []struct {
+		name     string
+		startsAt time.Time
+	}{
+		{"past", time.Now().Add(-time.Hour)},
+		{"now", time.Now()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/api/v1/vet/appointments", nil)
+
+			entry := &dbmodel.AppointmentEntry{CatId: 1, Vet: "Dr A", StartsAt: tt.startsAt, EndsAt: tt.startsAt.Add(30 * time.Minute)}
+			if _, ok := appointmentConfig.book(w, r, entry, 0); ok || w.Code != 409 {
+				t.Errorf("got booked %t with status %d, want 409", ok, w.Code)
+			}
+		})
+	}
+}
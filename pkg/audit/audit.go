@@ -0,0 +1,59 @@
+package audit
+
+import (
+	"context"
+	"net/http"
+	"vet-clinic-api/database/dbmodel"
+	"vet-clinic-api/pkg/authentication"
+
+	"github.com/go-chi/chi/middleware"
+)
+
+// Actions recorded in the audit log
+const (
+	Create = dbmodel.AuditCreate
+	Update = dbmodel.AuditUpdate
+	Delete = dbmodel.AuditDelete
+
+	// Actions on the trash
+	Restore = dbmodel.AuditRestore
+	Purge   = dbmodel.AuditPurge
+
+	// Actions on the accounts of the users
+	Invite                  = "invite"
+	AcceptInvitation        = "accept_invitation"
+	ResetPassword           = "reset_password"
+	RevokeSessions          = "revoke_sessions"
+	Unlock                  = "unlock"
+	EnableMfa               = "enable_mfa"
+	DisableMfa              = "disable_mfa"
+	ResetMfa                = "reset_mfa"
+	RegenerateRecoveryCodes = "regenerate_recovery_codes"
+	CreateApiKey            = "create_api_key"
+	DeleteApiKey            = "delete_api_key"
+)
+
+// Context of a request given to the repositories with WithContext, so they record their changes in the audit log
+// in the transaction of the change, with the user, the request id and the IP address of the request.
+func Context(r *http.Request) context.Context {
+	return ActionContext(r, "")
+}
+
+// Context of a request recording its changes with the given action instead of the one of the repositories,
+// like a password reset made with an update of the user
+func ActionContext(r *http.Request, action string) context.Context {
+
+	actor := &dbmodel.AuditActor{
+		RequestId: middleware.GetReqID(r.Context()),
+		IP:        authentication.ClientIP(r),
+		Action:    action,
+	}
+
+	// The public routes, like the password reset, have no principal
+	if principal, ok := authentication.FromContext(r.Context()); ok {
+		actor.ActorId = &principal.UserId
+		actor.ActorEmail = principal.Email
+	}
+
+	return dbmodel.NewAuditContext(r.Context(), actor)
+}
@@ -0,0 +1,103 @@
+package audit
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"vet-clinic-api/database/databasetest"
+	"vet-clinic-api/database/dbmodel"
+	"vet-clinic-api/pkg/authentication"
+
+	"github.com/go-chi/chi/middleware"
+)
+
+// A change is recorded with the user and the request id of the request, and the changed fields only
+func TestContext(t *testing.T) {
+
+	db := databasetest.Open(t)
+	cats := dbmodel.NewCatEntryRepository(db)
+	repository := dbmodel.NewAuditLogEntryRepository(db)
+
+	r := httptest.NewRequest("PATCH", "/api/v1/vet/cats/1", nil)
+	ctx := authentication.NewContext(r.Context(), &authentication.Principal{UserId: 7, Email: "vet@example.com"})
+	r = r.WithContext(context.WithValue(ctx, middleware.RequestIDKey, "request-1"))
+
+	cat, err := cats.WithContext(Context(r)).Create(&dbmodel.CatEntry{Name: "Tom", Age: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cats.WithContext(Context(r)).Patch(int(cat.ID), map[string]interface{}{"age": 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A change made outside of a request is not recorded
+	if _, err := cats.Patch(int(cat.ID), map[string]interface{}{"age": 4}); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, _, err := repository.FindAll(dbmodel.AuditLogFilter{Entity: "cat"}, dbmodel.Page{Limit: 50})
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("got %d entries (%v), want 2", len(entries), err)
+	}
+
+	entry := entries[1]
+	if entry.ActorId == nil || *entry.ActorId != 7 || entry.ActorEmail != "vet@example.com" || entry.RequestId != "request-1" || entry.Action != Update {
+		t.Errorf("got %+v, want the update of user 7 in request-1", entry)
+	}
+	if entry.Diff != `{"cat_age":{"after":3,"before":2}}` {
+		t.Errorf("got diff %s", entry.Diff)
+	}
+}
+
+// The account changes are recorded with their own action, the public routes without user
+func TestActionContext(t *testing.T) {
+
+	db := databasetest.Open(t)
+	users := dbmodel.NewUserEntryRepository(db)
+	repository := dbmodel.NewAuditLogEntryRepository(db)
+
+	user, err := users.Create(&dbmodel.UserEntry{Email: "vet@example.com", Role: "vet"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest("POST", "/api/v1/vet/users/password-reset/confirm", nil)
+	if err := users.WithContext(ActionContext(r, ResetPassword)).UpdatePassword(int(user.ID), "hash"); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, _, err := repository.FindAll(dbmodel.AuditLogFilter{Entity: "user"}, dbmodel.Page{Limit: 50})
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("got %d entries (%v), want 1", len(entries), err)
+	}
+
+	// The password itself is never recorded
+	entry := entries[0]
+	if entry.Action != ResetPassword || entry.ActorId != nil || entry.EntityId != user.ID || entry.Diff != `{}` {
+		t.Errorf("got %+v, want the password reset of user %d without actor and diff", entry, user.ID)
+	}
+}
+
+// The change and its audit entry are saved in the same transaction, a change is undone when it can't be recorded
+func TestContextTransaction(t *testing.T) {
+
+	db := databasetest.Open(t)
+	cats := dbmodel.NewCatEntryRepository(db)
+
+	if err := db.Migrator().DropTable(&dbmodel.AuditLogEntry{}); err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest("POST", "/api/v1/vet/cats", nil)
+	if _, err := cats.WithContext(Context(r)).Create(&dbmodel.CatEntry{Name: "Tom", Age: 2}); err == nil {
+		t.Fatal("got no error, want the creation refused without audit log")
+	}
+
+	var count int64
+	if err := db.Unscoped().Model(&dbmodel.CatEntry{}).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("got %d cats, want the creation undone", count)
+	}
+}
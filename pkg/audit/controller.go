@@ -0,0 +1,132 @@
+package audit
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+	"vet-clinic-api/config"
+	"vet-clinic-api/database/dbmodel"
+	"vet-clinic-api/pkg/model"
+	"vet-clinic-api/pkg/pagination"
+	"vet-clinic-api/pkg/problem"
+
+	"github.com/go-chi/render"
+)
+
+// Fields of the response used to sort the audit log, with their column
+var sortFields = map[string]string{
+	"id":               "id",
+	"audit_created_at": "created_at",
+}
+
+type AuditConfig struct {
+	*config.Config
+}
+
+func New(configuration *config.Config) *AuditConfig {
+	return &AuditConfig{configuration}
+}
+
+// GetAllHandler godoc
+// @Summary      Get the audit log
+// @Description  Find the changes made through the API by page, oldest first, optionally filtered by entity, actor and time range. The total count is in the X-Total-Count header and the other pages in the Link header.
+// @Tags         audit
+// @Produce      json
+// @Param        entity     query     string  false  "Filter by entity (cat, visit, treatment, user)"
+// @Param        entity_id  query     int     false  "Filter by entity id"
+// @Param        actor      query     int     false  "Filter by id of the user who made the change"
+// @Param        from       query     string  false  "Changes made from this time (RFC 3339)"
+// @Param        to         query     string  false  "Changes made until this time (RFC 3339)"
+// @Param        limit      query     int     false  "Number of changes of the page (default 50, max 200)"
+// @Param        offset     query     int     false  "Number of changes skipped, instead of the cursor"
+// @Param        cursor     query     string  false  "Cursor of the next page, given in the Link header"
+// @Param        sort       query     string  false  "Fields separated by commas, - for descending (id, audit_created_at)"
+// @Security     BearerAuth
+// @Success      200  {array}   model.AuditLogResponse
+// @Header       200  {integer}  X-Total-Count  "Number of changes matching the filters"
+// @Header       200  {string}   Link           "Links to the first, previous, next and last pages"
+// @Failure      400  {object}  problem.Problem  "Invalid filter or page"
+// @Failure      500  {object}  problem.Problem  "Failed to retrieve the audit log"
+// @Router       /audit [get]
+func (config *AuditConfig) GetAllHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Set up the filters
+	filter := dbmodel.AuditLogFilter{Entity: r.URL.Query().Get("entity")}
+
+	for name, target := range map[string]*uint{"entity_id": &filter.EntityId, "actor": &filter.ActorId} {
+		if value := r.URL.Query().Get(name); value != "" {
+			id, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				problem.BadRequest(w, r, name+" must be a positive integer")
+				return
+			}
+			*target = uint(id)
+		}
+	}
+
+	for name, target := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := r.URL.Query().Get(name); value != "" {
+			date, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				problem.BadRequest(w, r, name+" wrong format, expected RFC 3339 like 2006-01-02T15:04:05Z")
+				return
+			}
+			*target = date
+		}
+	}
+
+	page, err := pagination.Parse(r, sortFields)
+	if err != nil {
+		problem.BadRequest(w, r, err.Error())
+		return
+	}
+
+	// Request the DB to get the needed informations
+	entries, info, err := config.AuditLogEntryRepository.FindAll(filter, page)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find the audit log")
+		return
+	}
+
+	// Set up to a dedicated type for the response
+	res := []*model.AuditLogResponse{}
+	for _, entrie := range entries {
+		res = append(res, &model.AuditLogResponse{
+			Id:         entrie.ID,
+			CreatedAt:  entrie.CreatedAt,
+			ActorId:    entrie.ActorId,
+			ActorEmail: entrie.ActorEmail,
+			Action:     entrie.Action,
+			Entity:     entrie.Entity,
+			EntityId:   entrie.EntityId,
+			Diff:       json.RawMessage(entrie.Diff),
+			RequestId:  entrie.RequestId,
+			IP:         entrie.IP,
+			PrevHash:   entrie.PrevHash,
+			Hash:       entrie.Hash})
+	}
+
+	pagination.Write(w, r, page, info)
+	render.JSON(w, r, res)
+}
+
+// VerifyHandler godoc
+// @Summary      Verify the audit log
+// @Description  Checks the chain of hashes of the audit log, an altered, removed or inserted entry breaks the chain
+// @Tags         audit
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  model.AuditVerifyResponse
+// @Failure      500  {object}  problem.Problem  "Failed to verify the audit log"
+// @Router       /audit/verify [get]
+func (config *AuditConfig) VerifyHandler(w http.ResponseWriter, r *http.Request) {
+
+	count, brokenId, err := config.AuditLogEntryRepository.Verify()
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Verify the audit log")
+		return
+	}
+
+	render.JSON(w, r, &model.AuditVerifyResponse{Valid: brokenId == 0, Entries: count, BrokenId: brokenId})
+}
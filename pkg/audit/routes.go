@@ -0,0 +1,26 @@
+package audit
+
+import (
+	"vet-clinic-api/config"
+	"vet-clinic-api/pkg/authentication"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func Routes(configuration *config.Config) chi.Router {
+
+	// Init router
+	auditConfig := New(configuration)
+	router := chi.NewRouter()
+
+	// Routes protected by authentication and accessible with the "audit:read" permission
+	router.Group(func(router chi.Router) {
+		router.Use(authentication.AuthMiddleware(auditConfig.AccessTokenKeys, auditConfig.RevocationStore, auditConfig.ApiKeyStore, auditConfig.RoleEntryRepository))
+		router.Use(authentication.RequirePermission("audit:read"))
+
+		router.Get("/", auditConfig.GetAllHandler)
+		router.Get("/verify", auditConfig.VerifyHandler)
+	})
+
+	return router
+}
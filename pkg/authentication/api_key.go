@@ -0,0 +1,127 @@
+package authentication
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+	"vet-clinic-api/database/dbmodel"
+)
+
+// Start of every API key, followed by the public id of the key and its secret
+const ApiKeyPrefix = "vetk_"
+
+// The last use of a key is saved at most once by interval, to avoid a write on every request
+const apiKeyLastUsedInterval = time.Minute
+
+// Store of the API keys checked by the AuthMiddleware
+type ApiKeyStore interface {
+
+	// Find the user of a valid API key, the permissions of the principal are the scopes of the key
+	Authenticate(key string) (*Principal, error)
+}
+
+type sqliteApiKeyStore struct {
+	apiKeys dbmodel.ApiKeyEntryRepository
+	users   dbmodel.UserEntryRepository
+}
+
+// Create an API key store checking the keys saved in the DB
+func NewSQLiteApiKeyStore(apiKeys dbmodel.ApiKeyEntryRepository, users dbmodel.UserEntryRepository) ApiKeyStore {
+	return &sqliteApiKeyStore{apiKeys: apiKeys, users: users}
+}
+
+func (s *sqliteApiKeyStore) Authenticate(key string) (*Principal, error) {
+
+	prefix, ok := apiKeyPrefix(key)
+	if !ok {
+		return nil, errors.New("invalid API key")
+	}
+
+	entry, err := s.apiKeys.FindByPrefix(prefix)
+	if err != nil {
+		return nil, errors.New("invalid API key")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(HashApiKey(key)), []byte(entry.KeyHash)) != 1 {
+		return nil, errors.New("invalid API key")
+	}
+
+	now := time.Now()
+	if entry.ExpiresAt != nil && !entry.ExpiresAt.After(now) {
+		return nil, errors.New("expired API key")
+	}
+
+	// The key follows the current role of its user and stops working once the user is deleted
+	user, err := s.users.FindById(int(entry.UserId))
+	if err != nil {
+		return nil, errors.New("invalid API key")
+	}
+
+	if entry.LastUsedAt == nil || now.Sub(*entry.LastUsedAt) >= apiKeyLastUsedInterval {
+		s.apiKeys.UpdateLastUsed(entry.ID, now)
+	}
+
+	principal := &Principal{
+		UserId:      user.ID,
+		Email:       user.Email,
+		Role:        user.Role,
+		Permissions: []string{},
+		TokenId:     entry.Prefix,
+		ApiKeyId:    entry.ID,
+	}
+
+	if user.Role == "owner" {
+		principal.OwnerId = user.OwnerId
+	}
+
+	for _, scope := range entry.Scopes {
+		principal.Permissions = append(principal.Permissions, scope.Name)
+	}
+
+	return principal, nil
+}
+
+// Generate a new API key, the prefix is saved with the hash to find the key and to show it in the lists
+func GenerateApiKey() (string, string, error) {
+
+	id := make([]byte, 4)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+
+	prefix := ApiKeyPrefix + hex.EncodeToString(id)
+
+	return prefix + "_" + hex.EncodeToString(secret), prefix, nil
+}
+
+// Hash of an API key, the key itself is only shown once at its creation
+func HashApiKey(key string) string {
+
+	sum := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// Public part of an API key, like "vetk_1a2b3c4d"
+func apiKeyPrefix(key string) (string, bool) {
+
+	if !strings.HasPrefix(key, ApiKeyPrefix) {
+		return "", false
+	}
+
+	id, _, ok := strings.Cut(strings.TrimPrefix(key, ApiKeyPrefix), "_")
+	if !ok || len(id) != 8 {
+		return "", false
+	}
+
+	return ApiKeyPrefix + id, true
+}
@@ -0,0 +1,90 @@
+package authentication_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"vet-clinic-api/database/databasetest"
+	"vet-clinic-api/database/dbmodel"
+	"vet-clinic-api/pkg/authentication"
+)
+
+// An API key authenticates its user with the scopes of the key still granted to the role of the user
+func TestApiKey(t *testing.T) {
+
+	db := databasetest.Open(t)
+	users := dbmodel.NewUserEntryRepository(db)
+	apiKeys := dbmodel.NewApiKeyEntryRepository(db)
+	roles := dbmodel.NewRoleEntryRepository(db)
+	middleware := authentication.AuthMiddleware(authentication.NewHMACKeySet("access secret"), nil, authentication.NewSQLiteApiKeyStore(apiKeys, users), roles)
+
+	user, err := users.Create(&dbmodel.UserEntry{Email: "lab@example.com", Role: "technician"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a key of the user with scopes, the key itself is returned
+	newKey := func(expiresAt *time.Time, scopes ...string) string {
+		key, prefix, err := authentication.GenerateApiKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var permissions []dbmodel.PermissionEntry
+		if err := db.Where("name IN ?", scopes).Find(&permissions).Error; err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := apiKeys.Create(&dbmodel.ApiKeyEntry{Name: "Lab", Prefix: prefix, KeyHash: authentication.HashApiKey(key), UserId: user.ID, ExpiresAt: expiresAt, Scopes: permissions}); err != nil {
+			t.Fatal(err)
+		}
+
+		return key
+	}
+
+	// "cats:write" is granted to the technicians, "visits:write" is not
+	key := newKey(nil, "cats:read", "cats:write", "visits:write")
+	prefix := key[:strings.LastIndex(key, "_")]
+	expired := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name          string
+		authorization string
+		status        int
+		permissions   string
+	}{
+		{"scopes granted to the role", "ApiKey " + key, 200, "[cats:read cats:write]"},
+		{"expired key", "ApiKey " + newKey(&expired, "cats:read"), 401, ""},
+		{"wrong secret", "ApiKey " + prefix + "_" + strings.Repeat("0", 64), 401, ""},
+		{"unknown prefix", "ApiKey " + authentication.ApiKeyPrefix + "00000000" + strings.TrimPrefix(key, prefix), 401, ""},
+		{"not an API key", "ApiKey abc", 401, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, principal := authenticate(t, middleware, tt.authorization)
+			if status != tt.status {
+				t.Fatalf("got status %d, want %d", status, tt.status)
+			}
+			if status != 200 {
+				return
+			}
+
+			if !principal.IsApiKey() || principal.UserId != user.ID || principal.Role != "technician" {
+				t.Errorf("got %+v, want the API key of the user", principal)
+			}
+			if got := strings.Join(principal.Permissions, " "); "["+got+"]" != tt.permissions {
+				t.Errorf("got permissions [%s], want %s", got, tt.permissions)
+			}
+		})
+	}
+
+	t.Run("key of a deleted user", func(t *testing.T) {
+		if err := users.DeleteById(int(user.ID)); err != nil {
+			t.Fatal(err)
+		}
+		if status, _ := authenticate(t, middleware, "ApiKey "+key); status != 401 {
+			t.Errorf("got status %d, want 401", status)
+		}
+	})
+}
@@ -1,6 +1,8 @@
 package authentication
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"strings"
 	"time"
@@ -8,8 +10,8 @@ import (
 	"github.com/golang-jwt/jwt"
 )
 
-// Create token with specific email, role and expiration time
-func GenerateToken(secret string, claims map[string]interface{}, duration time.Duration) (string, error) {
+// Create token with specific claims, a unique id and expiration time, signed by the active key of the set
+func GenerateToken(keys *KeySet, claims map[string]interface{}, duration time.Duration) (string, error) {
 
 	jwtClaims := jwt.MapClaims{}
 
@@ -17,14 +19,43 @@ func GenerateToken(secret string, claims map[string]interface{}, duration time.D
 		jwtClaims[k] = v
 	}
 
-	jwtClaims["exp"] = time.Now().Add(time.Hour * duration).Unix()
+	// Every token has an id allowing to revoke it
+	if _, ok := jwtClaims["jti"]; !ok {
+		jti, err := GenerateTokenId()
+		if err != nil {
+			return "", err
+		}
+		jwtClaims["jti"] = jti
+	}
+
+	// Issue time is kept with milliseconds to compare it with the revocation time of a user
+	now := time.Now()
+	jwtClaims["iat"] = float64(now.UnixMilli()) / 1000
+	jwtClaims["exp"] = now.Add(duration).Unix()
+
+	token := jwt.NewWithClaims(keys.active.Method, jwtClaims)
+
+	// The "kid" header tells which key of the set verifies the token
+	if keys.active.Id != "" {
+		token.Header["kid"] = keys.active.Id
+	}
+
+	return token.SignedString(keys.active.PrivateKey)
+}
+
+// Create a random token id used for the "jti" claim
+func GenerateTokenId() (string, error) {
+
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwtClaims)
-	return token.SignedString([]byte(secret))
+	return hex.EncodeToString(bytes), nil
 }
 
-// Check token signature and validity return claims
-func ParseTokenClaims(secret, tokenString string) (jwt.MapClaims, error) {
+// Check token signature with the key set and validity return claims
+func ParseTokenClaims(keys *KeySet, tokenString string) (jwt.MapClaims, error) {
 	tokenString = strings.TrimPrefix(tokenString, "Bearer ")
 
 	claims := jwt.MapClaims{}
@@ -32,12 +63,7 @@ func ParseTokenClaims(secret, tokenString string) (jwt.MapClaims, error) {
 	token, err := jwt.ParseWithClaims(
 		tokenString,
 		claims,
-		func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, errors.New("unexpected signing method")
-			}
-			return []byte(secret), nil
-		},
+		keys.verificationKey,
 	)
 
 	if err != nil {
@@ -0,0 +1,204 @@
+package authentication
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"os"
+	"sort"
+
+	"github.com/go-chi/render"
+	"github.com/golang-jwt/jwt"
+)
+
+// Key used to sign and verify the tokens, a key without private part can only verify
+type SigningKey struct {
+	Id         string
+	Method     jwt.SigningMethod
+	PrivateKey interface{}
+	PublicKey  interface{}
+}
+
+// Set of keys identified by their "kid", the active key signs the new tokens
+// and every key of the set verifies the tokens, which allows key rotation
+type KeySet struct {
+	active *SigningKey
+	keys   map[string]*SigningKey
+}
+
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// Create a key set signing with HS256 and a shared secret, tokens are issued without "kid"
+func NewHMACKeySet(secret string) *KeySet {
+
+	key := &SigningKey{
+		Method:     jwt.SigningMethodHS256,
+		PrivateKey: []byte(secret),
+		PublicKey:  []byte(secret),
+	}
+
+	return &KeySet{active: key, keys: map[string]*SigningKey{"": key}}
+}
+
+// Create a key set from PEM files by "kid", RSA keys sign with RS256 and Ed25519 keys with EdDSA.
+// Files with a public key only are kept to verify the tokens signed before a rotation.
+func LoadKeySet(activeKeyId string, files map[string]string) (*KeySet, error) {
+
+	keySet := &KeySet{keys: map[string]*SigningKey{}}
+
+	for kid, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := parsePEMKey(kid, content)
+		if err != nil {
+			return nil, errors.New(file + ": " + err.Error())
+		}
+
+		keySet.keys[kid] = key
+	}
+
+	active, ok := keySet.keys[activeKeyId]
+	if !ok {
+		return nil, errors.New("active key " + activeKeyId + " not found")
+	}
+
+	if active.PrivateKey == nil {
+		return nil, errors.New("active key " + activeKeyId + " has no private key")
+	}
+
+	keySet.active = active
+
+	return keySet, nil
+}
+
+// Add the keys of another set to verify its tokens, used to accept the HS256 tokens issued before
+// the switch to asymmetric keys
+func (k *KeySet) Accept(other *KeySet) {
+
+	for kid, key := range other.keys {
+		if _, ok := k.keys[kid]; !ok {
+			k.keys[kid] = key
+		}
+	}
+}
+
+// Find the key used to verify a token, the signing method of the token must match the key
+func (k *KeySet) verificationKey(token *jwt.Token) (interface{}, error) {
+
+	kid, _ := token.Header["kid"].(string)
+
+	key, ok := k.keys[kid]
+	if !ok {
+		return nil, errors.New("unknown signing key")
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, errors.New("unexpected signing method")
+	}
+
+	return key.PublicKey, nil
+}
+
+// Public keys of the set in the JSON Web Key Set format, shared secrets are never exposed
+func (k *KeySet) JWKS() JSONWebKeySet {
+
+	keySet := JSONWebKeySet{Keys: []JSONWebKey{}}
+
+	for kid, key := range k.keys {
+		switch publicKey := key.PublicKey.(type) {
+		case *rsa.PublicKey:
+			keySet.Keys = append(keySet.Keys, JSONWebKey{
+				Kty: "RSA",
+				Kid: kid,
+				Use: "sig",
+				Alg: key.Method.Alg(),
+				N:   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			keySet.Keys = append(keySet.Keys, JSONWebKey{
+				Kty: "OKP",
+				Kid: kid,
+				Use: "sig",
+				Alg: key.Method.Alg(),
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(publicKey),
+			})
+		}
+	}
+
+	// Keep a stable order for the clients caching the keys
+	sort.Slice(keySet.Keys, func(i, j int) bool { return keySet.Keys[i].Kid < keySet.Keys[j].Kid })
+
+	return keySet
+}
+
+// Handler serving the public keys to let other services verify the tokens
+func JWKSHandler(keys *KeySet) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		render.JSON(w, r, keys.JWKS())
+	}
+}
+
+// Read a private or public RSA / Ed25519 key from a PEM file content
+func parsePEMKey(kid string, content []byte) (*SigningKey, error) {
+
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	var parsed interface{}
+	var err error
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, errors.New("unsupported PEM type " + block.Type)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch key := parsed.(type) {
+	case *rsa.PrivateKey:
+		return &SigningKey{Id: kid, Method: jwt.SigningMethodRS256, PrivateKey: key, PublicKey: &key.PublicKey}, nil
+	case *rsa.PublicKey:
+		return &SigningKey{Id: kid, Method: jwt.SigningMethodRS256, PublicKey: key}, nil
+	case ed25519.PrivateKey:
+		return &SigningKey{Id: kid, Method: jwt.SigningMethodEdDSA, PrivateKey: key, PublicKey: key.Public()}, nil
+	case ed25519.PublicKey:
+		return &SigningKey{Id: kid, Method: jwt.SigningMethodEdDSA, PublicKey: key}, nil
+	}
+
+	return nil, errors.New("unsupported key type, expected RSA or Ed25519")
+}
@@ -0,0 +1,146 @@
+package authentication
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Write a key in a PEM file of a temporary directory and return its path
+func pemFile(t *testing.T, name string, blockType string, der []byte) string {
+
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return file
+}
+
+func TestKeySet(t *testing.T) {
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPrivateDER, err := x509.MarshalPKCS8PrivateKey(edPrivate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPublicDER, err := x509.MarshalPKIXPublicKey(edPublic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		"rsa-1":   pemFile(t, "rsa-1.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey)),
+		"ed-1":    pemFile(t, "ed-1.pem", "PRIVATE KEY", edPrivateDER),
+		"ed-0":    pemFile(t, "ed-0.pem", "PUBLIC KEY", edPublicDER),
+		"garbage": pemFile(t, "garbage.pem", "CERTIFICATE", []byte("abc")),
+	}
+
+	t.Run("load", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			active string
+			kids   []string
+			err    bool
+		}{
+			{"RSA key", "rsa-1", []string{"rsa-1"}, false},
+			{"Ed25519 key with a previous public key", "ed-1", []string{"ed-1", "ed-0"}, false},
+			{"active key not found", "rsa-2", []string{"rsa-1"}, true},
+			{"active key without private key", "ed-0", []string{"ed-0"}, true},
+			{"unsupported PEM type", "rsa-1", []string{"rsa-1", "garbage"}, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				selected := map[string]string{}
+				for _, kid := range tt.kids {
+					selected[kid] = files[kid]
+				}
+
+				keySet, err := LoadKeySet(tt.active, selected)
+				if tt.err {
+					if err == nil {
+						t.Error("got a key set, want an error")
+					}
+					return
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				if keySet.active.Id != tt.active || len(keySet.keys) != len(tt.kids) {
+					t.Errorf("got active key %s and %d keys, want %s and %d", keySet.active.Id, len(keySet.keys), tt.active, len(tt.kids))
+				}
+			})
+		}
+	})
+
+	t.Run("rotation", func(t *testing.T) {
+		previous, err := LoadKeySet("ed-0", map[string]string{"ed-0": files["ed-1"]})
+		if err != nil {
+			t.Fatal(err)
+		}
+		current, err := LoadKeySet("rsa-1", map[string]string{"rsa-1": files["rsa-1"], "ed-0": files["ed-0"]})
+		if err != nil {
+			t.Fatal(err)
+		}
+		hmac := NewHMACKeySet("access secret")
+		current.Accept(hmac)
+
+		tests := []struct {
+			name   string
+			signer *KeySet
+			valid  bool
+		}{
+			{"active key", current, true},
+			{"public key kept after a rotation", previous, true},
+			{"accepted HS256 token", hmac, true},
+			{"other HS256 secret", NewHMACKeySet("other secret"), false},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				token, err := GenerateToken(tt.signer, map[string]interface{}{"user_id": 1}, time.Hour)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, err := ParseTokenClaims(current, token); (err == nil) != tt.valid {
+					t.Errorf("got error %v, want valid %t", err, tt.valid)
+				}
+			})
+		}
+	})
+
+	t.Run("JWKS", func(t *testing.T) {
+		keySet, err := LoadKeySet("rsa-1", map[string]string{"rsa-1": files["rsa-1"], "ed-0": files["ed-0"]})
+		if err != nil {
+			t.Fatal(err)
+		}
+		keySet.Accept(NewHMACKeySet("access secret"))
+
+		keys := keySet.JWKS().Keys
+		if len(keys) != 2 {
+			t.Fatalf("got %d keys, want the 2 public keys without the shared secret", len(keys))
+		}
+		if keys[0].Kid != "ed-0" || keys[0].Kty != "OKP" || keys[0].Alg != "EdDSA" || keys[0].X == "" {
+			t.Errorf("got %+v, want the Ed25519 key", keys[0])
+		}
+		if keys[1].Kid != "rsa-1" || keys[1].Kty != "RSA" || keys[1].Alg != "RS256" || keys[1].E != "AQAB" {
+			t.Errorf("got %+v, want the RSA key", keys[1])
+		}
+	})
+}
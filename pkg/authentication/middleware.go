@@ -3,10 +3,16 @@ package authentication
 import (
 	"context"
 	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"vet-clinic-api/database/dbmodel"
+	"vet-clinic-api/pkg/problem"
 )
 
-// Middleware to secure routes with a JWT
-func AuthMiddleware(secret string) func(http.Handler) http.Handler {
+// Middleware to secure routes with a JWT or an API key, revoked tokens are rejected.
+// The principal of the request is added to the context with the permissions of its role.
+func AuthMiddleware(keys *KeySet, revocations RevocationStore, apiKeys ApiKeyStore, roles dbmodel.RoleEntryRepository) func(http.Handler) http.Handler {
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -14,33 +20,92 @@ func AuthMiddleware(secret string) func(http.Handler) http.Handler {
 			// Check Header content
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" {
-				http.Error(w, "Missing token", http.StatusUnauthorized)
+				problem.Unauthorized(w, r, "Missing token")
+				return
+			}
+
+			// API keys are sent as "ApiKey <key>" instead of "Bearer <token>"
+			if key, ok := strings.CutPrefix(authHeader, "ApiKey "); ok {
+				if apiKeys == nil {
+					problem.Unauthorized(w, r, "Invalid API key")
+					return
+				}
+
+				principal, err := apiKeys.Authenticate(key)
+				if err != nil {
+					problem.Unauthorized(w, r, "Invalid API key")
+					return
+				}
+
+				// An API key is limited to its scopes still granted to the role
+				scopes := principal.Permissions
+				principal.Permissions = []string{}
+				for _, permission := range rolePermissions(roles, principal.Role) {
+					if slices.Contains(scopes, permission) {
+						principal.Permissions = append(principal.Permissions, permission)
+					}
+				}
+
+				next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), principal)))
 				return
 			}
 
 			// Check token validity
-			claims, err := ParseTokenClaims(secret, authHeader)
+			claims, err := ParseTokenClaims(keys, authHeader)
 			if err != nil {
-				http.Error(w, "Invalid token", http.StatusUnauthorized)
+				problem.Unauthorized(w, r, "Invalid token")
 				return
 			}
 
-			ctx := context.WithValue(r.Context(), "email", claims["email"])
-			ctx = context.WithValue(ctx, "role", claims["role"])
-			next.ServeHTTP(w, r.WithContext(ctx))
+			// Tokens with a type, like the mfa pending tokens, can't be used as access tokens
+			if _, ok := claims["typ"]; ok {
+				problem.Unauthorized(w, r, "Invalid token")
+				return
+			}
+
+			// Check if the token or the tokens of the user have been revoked
+			jti, _ := claims["jti"].(string)
+			userId, _ := claims["user_id"].(float64)
+			issuedAt, _ := claims["iat"].(float64)
+
+			if revocations != nil && revocations.IsRevoked(jti, uint(userId), time.UnixMilli(int64(issuedAt*1000))) {
+				problem.Unauthorized(w, r, "Revoked token")
+				return
+			}
+
+			email, _ := claims["email"].(string)
+			role, _ := claims["role"].(string)
+			sessionId, _ := claims["sid"].(string)
+
+			principal := &Principal{
+				UserId:      uint(userId),
+				Email:       email,
+				Role:        role,
+				Permissions: rolePermissions(roles, role),
+				TokenId:     jti,
+				SessionId:   sessionId,
+			}
+
+			// Owner tokens carry the id of the owner linked to the account
+			if ownerId, ok := claims["owner_id"].(float64); ok {
+				id := uint(ownerId)
+				principal.OwnerId = &id
+			}
+
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), principal)))
 		})
 	}
 }
 
-// Check if the required Role is match by the user token
-func RoleMiddleware(requiredRole string) func(http.Handler) http.Handler {
+// Check if the principal of the request is granted the required permission
+func RequirePermission(permission string) func(http.Handler) http.Handler {
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
-			role, ok := r.Context().Value("role").(string)
-			if !ok || role != requiredRole {
-				http.Error(w, "Forbidden: insufficient privileges", http.StatusForbidden)
+			principal, ok := FromContext(r.Context())
+			if !ok || !principal.HasPermission(permission) {
+				problem.Forbidden(w, r, "Missing permission "+permission)
 				return
 			}
 
@@ -48,3 +113,50 @@ func RoleMiddleware(requiredRole string) func(http.Handler) http.Handler {
 		})
 	}
 }
+
+// Reject the requests made with an API key, for the routes managing the account itself
+func RequireAccessToken(next http.Handler) http.Handler {
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		if principal, ok := FromContext(r.Context()); !ok || principal.IsApiKey() {
+			problem.Forbidden(w, r, "Not allowed with an API key")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Return the owner id of the principal when the request is made with the "owner" role
+func OwnerIdFromContext(ctx context.Context) (uint, bool) {
+
+	principal, ok := FromContext(ctx)
+	if !ok || principal.Role != "owner" {
+		return 0, false
+	}
+
+	// An owner token without owner id can't access any animal
+	if principal.OwnerId == nil {
+		return 0, true
+	}
+
+	return *principal.OwnerId, true
+}
+
+// Names of the permissions granted to a role, none when the role doesn't exist
+func rolePermissions(roles dbmodel.RoleEntryRepository, role string) []string {
+
+	permissions := []string{}
+
+	entry, err := roles.FindByName(role)
+	if err != nil {
+		return permissions
+	}
+
+	for _, permission := range entry.Permissions {
+		permissions = append(permissions, permission.Name)
+	}
+
+	return permissions
+}
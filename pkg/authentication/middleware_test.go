@@ -0,0 +1,140 @@
+package authentication_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"vet-clinic-api/database/databasetest"
+	"vet-clinic-api/database/dbmodel"
+	"vet-clinic-api/pkg/authentication"
+)
+
+// Serve a request through the AuthMiddleware, the principal given to the route is returned with the status
+func authenticate(t *testing.T, middleware func(http.Handler) http.Handler, authorization string) (int, *authentication.Principal) {
+
+	t.Helper()
+
+	var principal *authentication.Principal
+	handler := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, _ = authentication.FromContext(r.Context())
+	}))
+
+	r := httptest.NewRequest("GET", "/api/v1/vet/cats", nil)
+	if authorization != "" {
+		r.Header.Set("Authorization", authorization)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	return w.Code, principal
+}
+
+func TestAuthMiddleware(t *testing.T) {
+
+	keys := authentication.NewHMACKeySet("access secret")
+	revocations := authentication.NewMemoryRevocationStore()
+	roles := dbmodel.NewRoleEntryRepository(databasetest.Open(t))
+	middleware := authentication.AuthMiddleware(keys, revocations, nil, roles)
+
+	token := func(keys *authentication.KeySet, claims map[string]interface{}) string {
+		signed, err := authentication.GenerateToken(keys, claims, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return "Bearer " + signed
+	}
+
+	revoked := token(keys, map[string]interface{}{"user_id": 3, "role": "vet", "jti": "revoked"})
+	if err := revocations.Revoke("revoked", time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	ownerId := uint(5)
+	tests := []struct {
+		name          string
+		authorization string
+		status        int
+		role          string
+		ownerId       *uint
+		scoped        bool
+	}{
+		{"missing token", "", 401, "", nil, false},
+		{"malformed token", "Bearer abc", 401, "", nil, false},
+		{"token of another key", token(authentication.NewHMACKeySet("other secret"), map[string]interface{}{"user_id": 1, "role": "admin"}), 401, "", nil, false},
+		{"mfa token", token(keys, map[string]interface{}{"user_id": 1, "role": "admin", "typ": "mfa"}), 401, "", nil, false},
+		{"revoked token", revoked, 401, "", nil, false},
+		{"API key without store", "ApiKey vetk_12345678_abc", 401, "", nil, false},
+		{"vet", token(keys, map[string]interface{}{"user_id": 3, "email": "vet@example.com", "role": "vet"}), 200, "vet", nil, false},
+		{"owner", token(keys, map[string]interface{}{"user_id": 4, "role": "owner", "owner_id": 5}), 200, "owner", &ownerId, true},
+		{"owner without owner id", token(keys, map[string]interface{}{"user_id": 4, "role": "owner"}), 200, "owner", nil, true},
+		{"unknown role", token(keys, map[string]interface{}{"user_id": 6, "role": "janitor"}), 200, "janitor", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, principal := authenticate(t, middleware, tt.authorization)
+			if status != tt.status {
+				t.Fatalf("got status %d, want %d", status, tt.status)
+			}
+			if status != 200 {
+				return
+			}
+
+			if principal.Role != tt.role || principal.TokenId == "" || principal.IsApiKey() {
+				t.Errorf("got %+v, want a token of role %s", principal, tt.role)
+			}
+			if (principal.OwnerId == nil) != (tt.ownerId == nil) || (tt.ownerId != nil && *principal.OwnerId != *tt.ownerId) {
+				t.Errorf("got owner id %v, want %v", principal.OwnerId, tt.ownerId)
+			}
+
+			// The owner scope of the lists, an owner without owner id is scoped to no owner
+			ctx := authentication.NewContext(t.Context(), principal)
+			if id, scoped := authentication.OwnerIdFromContext(ctx); scoped != tt.scoped || (tt.ownerId == nil && id != 0) {
+				t.Errorf("got owner scope %d %t, want %t", id, scoped, tt.scoped)
+			}
+		})
+	}
+
+	t.Run("permissions of the role", func(t *testing.T) {
+		_, vet := authenticate(t, middleware, token(keys, map[string]interface{}{"user_id": 3, "role": "vet"}))
+		if !vet.HasPermission("cats:write") || vet.HasPermission("users:read") {
+			t.Errorf("got permissions %v, want the ones of the vet role", vet.Permissions)
+		}
+
+		_, unknown := authenticate(t, middleware, token(keys, map[string]interface{}{"user_id": 6, "role": "janitor"}))
+		if len(unknown.Permissions) != 0 {
+			t.Errorf("got permissions %v, want none", unknown.Permissions)
+		}
+	})
+}
+
+func TestRequirePermission(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		principal *authentication.Principal
+		status    int
+	}{
+		{"granted", &authentication.Principal{Permissions: []string{"cats:read", "cats:write"}}, 200},
+		{"missing permission", &authentication.Principal{Permissions: []string{"cats:read"}}, 403},
+		{"no principal", nil, 403},
+	}
+
+	handler := authentication.RequirePermission("cats:write")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/api/v1/vet/cats", nil)
+			if tt.principal != nil {
+				r = r.WithContext(authentication.NewContext(r.Context(), tt.principal))
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != tt.status {
+				t.Errorf("got status %d, want %d", w.Code, tt.status)
+			}
+		})
+	}
+}
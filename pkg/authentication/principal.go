@@ -0,0 +1,51 @@
+package authentication
+
+import (
+	"context"
+	"slices"
+)
+
+// User making the request, set in the context by the AuthMiddleware
+type Principal struct {
+	UserId uint
+	Email  string
+	Role   string
+
+	// Owner linked to the account when the role is "owner"
+	OwnerId *uint
+
+	// Permissions of the role, limited to the scopes of the key for an API key
+	Permissions []string
+
+	// Id of the access token, or prefix of the API key
+	TokenId string
+
+	// Session of the access token, the family of its refresh tokens
+	SessionId string
+
+	// Set when the request is made with an API key
+	ApiKeyId uint
+}
+
+type principalKey struct{}
+
+// Return a copy of the context carrying the principal
+func NewContext(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// Return the principal set by the AuthMiddleware, false on the public routes
+func FromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
+
+// Check if the principal is granted a permission
+func (p *Principal) HasPermission(permission string) bool {
+	return slices.Contains(p.Permissions, permission)
+}
+
+// Check if the request is made with an API key instead of an access token
+func (p *Principal) IsApiKey() bool {
+	return p.ApiKeyId != 0
+}
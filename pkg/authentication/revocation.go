@@ -0,0 +1,140 @@
+package authentication
+
+import (
+	"sync"
+	"time"
+	"vet-clinic-api/database/dbmodel"
+)
+
+// Store of the revoked access tokens checked by the AuthMiddleware
+type RevocationStore interface {
+
+	// Revoke a single token until its expiration
+	Revoke(jti string, expiresAt time.Time) error
+
+	// Revoke every token of a user issued until now, expiresAt must cover the lifetime of the tokens
+	RevokeUser(userId uint, expiresAt time.Time) error
+
+	// Check if a token has been revoked by its id or by its user
+	IsRevoked(jti string, userId uint, issuedAt time.Time) bool
+}
+
+type memoryRevocationStore struct {
+	mutex     sync.Mutex
+	tokens    map[string]time.Time
+	users     map[uint]memoryUserRevocation
+	lastPrune time.Time
+}
+
+type memoryUserRevocation struct {
+	revokedAt time.Time
+	expiresAt time.Time
+}
+
+// Create a revocation store kept in memory, revocations are dropped once expired and lost on restart
+func NewMemoryRevocationStore() RevocationStore {
+	return &memoryRevocationStore{
+		tokens: map[string]time.Time{},
+		users:  map[uint]memoryUserRevocation{},
+	}
+}
+
+func (s *memoryRevocationStore) Revoke(jti string, expiresAt time.Time) error {
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.tokens[jti] = expiresAt
+	s.prune(time.Now())
+
+	return nil
+}
+
+func (s *memoryRevocationStore) RevokeUser(userId uint, expiresAt time.Time) error {
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.users[userId] = memoryUserRevocation{revokedAt: time.Now(), expiresAt: expiresAt}
+	s.prune(time.Now())
+
+	return nil
+}
+
+func (s *memoryRevocationStore) IsRevoked(jti string, userId uint, issuedAt time.Time) bool {
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+
+	if expiresAt, ok := s.tokens[jti]; ok && jti != "" && expiresAt.After(now) {
+		return true
+	}
+
+	if revocation, ok := s.users[userId]; ok && revocation.expiresAt.After(now) && !issuedAt.After(revocation.revokedAt) {
+		return true
+	}
+
+	return false
+}
+
+// Drop the expired revocations at most once per minute
+func (s *memoryRevocationStore) prune(now time.Time) {
+
+	if now.Sub(s.lastPrune) < time.Minute {
+		return
+	}
+	s.lastPrune = now
+
+	for jti, expiresAt := range s.tokens {
+		if !expiresAt.After(now) {
+			delete(s.tokens, jti)
+		}
+	}
+
+	for userId, revocation := range s.users {
+		if !revocation.expiresAt.After(now) {
+			delete(s.users, userId)
+		}
+	}
+}
+
+type sqliteRevocationStore struct {
+	repository dbmodel.RevokedTokenEntryRepository
+}
+
+// Create a revocation store saved in the SQLite DB, revocations survive a restart
+func NewSQLiteRevocationStore(repository dbmodel.RevokedTokenEntryRepository) RevocationStore {
+	return &sqliteRevocationStore{repository: repository}
+}
+
+func (s *sqliteRevocationStore) Revoke(jti string, expiresAt time.Time) error {
+
+	if _, err := s.repository.Create(&dbmodel.RevokedTokenEntry{
+		Jti:       jti,
+		RevokedAt: time.Now().UTC(),
+		ExpiresAt: expiresAt.UTC(),
+	}); err != nil {
+		return err
+	}
+
+	return s.repository.DeleteExpired(time.Now().UTC())
+}
+
+func (s *sqliteRevocationStore) RevokeUser(userId uint, expiresAt time.Time) error {
+
+	if _, err := s.repository.Create(&dbmodel.RevokedTokenEntry{
+		UserId:    userId,
+		RevokedAt: time.Now().UTC(),
+		ExpiresAt: expiresAt.UTC(),
+	}); err != nil {
+		return err
+	}
+
+	return s.repository.DeleteExpired(time.Now().UTC())
+}
+
+func (s *sqliteRevocationStore) IsRevoked(jti string, userId uint, issuedAt time.Time) bool {
+	return s.repository.IsRevoked(jti, userId, issuedAt.UTC())
+}
@@ -0,0 +1,63 @@
+package authentication_test
+
+import (
+	"testing"
+	"time"
+	"vet-clinic-api/database/databasetest"
+	"vet-clinic-api/database/dbmodel"
+	"vet-clinic-api/pkg/authentication"
+)
+
+// Both stores must answer the same, the SQLite store keeps the revocations across restarts
+func TestRevocationStore(t *testing.T) {
+
+	stores := map[string]func(t *testing.T) authentication.RevocationStore{
+		"memory": func(t *testing.T) authentication.RevocationStore {
+			return authentication.NewMemoryRevocationStore()
+		},
+		"sqlite": func(t *testing.T) authentication.RevocationStore {
+			return authentication.NewSQLiteRevocationStore(dbmodel.NewRevokedTokenEntryRepository(databasetest.Open(t)))
+		},
+	}
+
+	now := time.Now()
+	tests := []struct {
+		name     string
+		jti      string
+		userId   uint
+		issuedAt time.Time
+		want     bool
+	}{
+		{"revoked token", "revoked", 1, now.Add(-time.Minute), true},
+		{"revoked token of another user", "revoked", 3, now.Add(-time.Minute), true},
+		{"expired revocation", "expired", 1, now.Add(-2 * time.Hour), false},
+		{"other token", "other", 1, now.Add(-time.Minute), false},
+		{"token issued before the user revocation", "other", 2, now.Add(-time.Minute), true},
+		{"token issued after the user revocation", "other", 2, now.Add(time.Minute), false},
+		{"token without id", "", 1, now.Add(-time.Minute), false},
+	}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+
+			store := newStore(t)
+			if err := store.Revoke("revoked", now.Add(time.Hour)); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.Revoke("expired", now.Add(-time.Hour)); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.RevokeUser(2, now.Add(time.Hour)); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					if got := store.IsRevoked(tt.jti, tt.userId, tt.issuedAt); got != tt.want {
+						t.Errorf("got revoked %t, want %t", got, tt.want)
+					}
+				})
+			}
+		})
+	}
+}
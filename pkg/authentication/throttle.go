@@ -0,0 +1,131 @@
+package authentication
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Failed logins allowed before a lock, then the lock duration doubles at each new failure
+const (
+	AccountFreeAttempts = 5
+	IPFreeAttempts      = 20
+	LockBaseDelay       = time.Minute
+	LockMaxDelay        = time.Hour
+)
+
+// Failures are forgotten after this time without a new failure
+const throttleRetention = 24 * time.Hour
+
+// Failed login counter kept in memory by key, like an IP address or an email
+type LoginThrottle struct {
+	mutex        sync.Mutex
+	attempts     map[string]*loginAttempts
+	freeAttempts int
+	lastPrune    time.Time
+}
+
+type loginAttempts struct {
+	failures     int
+	blockedUntil time.Time
+	lastFailure  time.Time
+}
+
+// Create a throttle blocking a key after the given number of failures
+func NewLoginThrottle(freeAttempts int) *LoginThrottle {
+	return &LoginThrottle{
+		attempts:     map[string]*loginAttempts{},
+		freeAttempts: freeAttempts,
+	}
+}
+
+// Return the remaining blocking time of a key
+func (t *LoginThrottle) Blocked(key string) (time.Duration, bool) {
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	attempts, ok := t.attempts[key]
+	if !ok {
+		return 0, false
+	}
+
+	wait := time.Until(attempts.blockedUntil)
+
+	return wait, wait > 0
+}
+
+// Count a failure and block the key once the free attempts are used
+func (t *LoginThrottle) Failure(key string) {
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := time.Now()
+	t.prune(now)
+
+	attempts, ok := t.attempts[key]
+	if !ok {
+		attempts = &loginAttempts{}
+		t.attempts[key] = attempts
+	}
+
+	attempts.failures++
+	attempts.lastFailure = now
+
+	if delay := BackoffDelay(attempts.failures, t.freeAttempts); delay > 0 {
+		attempts.blockedUntil = now.Add(delay)
+	}
+}
+
+// Forget the failures of a key
+func (t *LoginThrottle) Reset(key string) {
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	delete(t.attempts, key)
+}
+
+// Drop the keys without recent failure, at most once per hour
+func (t *LoginThrottle) prune(now time.Time) {
+
+	if now.Sub(t.lastPrune) < time.Hour {
+		return
+	}
+
+	for key, attempts := range t.attempts {
+		if now.Sub(attempts.lastFailure) > throttleRetention {
+			delete(t.attempts, key)
+		}
+	}
+
+	t.lastPrune = now
+}
+
+// Lock duration after a number of failures, no lock until the free attempts are used
+func BackoffDelay(failures int, freeAttempts int) time.Duration {
+
+	if failures < freeAttempts {
+		return 0
+	}
+
+	delay := LockBaseDelay
+	for i := freeAttempts; i < failures && delay < LockMaxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, LockMaxDelay)
+}
+
+// IP address of the client, without the port
+func ClientIP(r *http.Request) string {
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
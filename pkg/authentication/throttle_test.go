@@ -0,0 +1,94 @@
+package authentication
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBackoffDelay(t *testing.T) {
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{4, 0},
+		{5, time.Minute},
+		{6, 2 * time.Minute},
+		{10, 32 * time.Minute},
+		{11, time.Hour},
+		{1000, time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.failures, " failures"), func(t *testing.T) {
+			if got := BackoffDelay(tt.failures, AccountFreeAttempts); got != tt.want {
+				t.Errorf("got %s after %d failures, want %s", got, tt.failures, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoginThrottle(t *testing.T) {
+
+	throttle := NewLoginThrottle(3)
+
+	// Each step counts the given failures of a key, then checks the lock
+	steps := []struct {
+		name     string
+		key      string
+		failures int
+		reset    bool
+		blocked  bool
+	}{
+		{"free attempts", "10.0.0.1", 2, false, false},
+		{"last free attempt used", "10.0.0.1", 1, false, true},
+		{"other key", "10.0.0.2", 1, false, false},
+		{"reset", "10.0.0.1", 0, true, false},
+		{"free attempts again after a reset", "10.0.0.1", 2, false, false},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			if step.reset {
+				throttle.Reset(step.key)
+			}
+			for i := 0; i < step.failures; i++ {
+				throttle.Failure(step.key)
+			}
+
+			wait, blocked := throttle.Blocked(step.key)
+			if blocked != step.blocked {
+				t.Fatalf("got blocked %t, want %t", blocked, step.blocked)
+			}
+			if blocked && (wait <= 0 || wait > LockBaseDelay) {
+				t.Errorf("got a wait of %s, want at most %s", wait, LockBaseDelay)
+			}
+		})
+	}
+}
+
+func TestClientIP(t *testing.T) {
+
+	tests := []struct {
+		remoteAddr string
+		want       string
+	}{
+		{"192.0.2.1:1234", "192.0.2.1"},
+		{"[2001:db8::1]:1234", "2001:db8::1"},
+		{"192.0.2.1", "192.0.2.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.remoteAddr, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/api/v1/vet/users/login", nil)
+			r.RemoteAddr = tt.remoteAddr
+
+			if got := ClientIP(r); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
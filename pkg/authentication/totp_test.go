@@ -0,0 +1,78 @@
+package authentication
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+// Secret of the test vectors of RFC 6238, "12345678901234567890" encoded in base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestValidateTotp(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		secret string
+		code   string
+		now    time.Time
+		want   bool
+		step   int64
+	}{
+		// Last 6 digits of the SHA1 test vectors of RFC 6238
+		{"vector 59", rfcSecret, "287082", time.Unix(59, 0), true, 1},
+		{"vector 1111111109", rfcSecret, "081804", time.Unix(1111111109, 0), true, 37037036},
+		{"vector 1234567890", rfcSecret, "005924", time.Unix(1234567890, 0), true, 41152263},
+		{"vector 2000000000", rfcSecret, "279037", time.Unix(2000000000, 0), true, 66666666},
+		{"lowercase secret", "gezdgnbvgy3tqojqgezdgnbvgy3tqojq", "005924", time.Unix(1234567890, 0), true, 41152263},
+		{"previous step allowed", rfcSecret, "005924", time.Unix(1234567890+30, 0), true, 41152263},
+		{"next step allowed", rfcSecret, "005924", time.Unix(1234567890-30, 0), true, 41152263},
+		{"too old", rfcSecret, "005924", time.Unix(1234567890+60, 0), false, 0},
+		{"wrong code", rfcSecret, "005925", time.Unix(1234567890, 0), false, 0},
+		{"too short", rfcSecret, "05924", time.Unix(1234567890, 0), false, 0},
+		{"invalid secret", "not base32!", "005924", time.Unix(1234567890, 0), false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := ValidateTotp(tt.secret, tt.code, tt.now)
+			if ok != tt.want || step != tt.step {
+				t.Errorf("got %t at step %d, want %t at step %d", ok, step, tt.want, tt.step)
+			}
+		})
+	}
+}
+
+func TestGenerateTotpSecret(t *testing.T) {
+
+	secret, err := GenerateTotpSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A new secret must give codes its own validation accepts
+	code := totpCode(mustDecode(t, secret), time.Now().Unix()/totpPeriod)
+	if _, ok := ValidateTotp(secret, code, time.Now()); !ok {
+		t.Errorf("code %s of secret %s refused", code, secret)
+	}
+
+	uri, err := url.Parse(TotpURI("Vet Clinic", "vet@example.com", secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Query().Get("secret") != secret || uri.Query().Get("issuer") != "Vet Clinic" {
+		t.Errorf("got URI %s", uri)
+	}
+}
+
+func mustDecode(t *testing.T, secret string) []byte {
+
+	t.Helper()
+
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key
+}
@@ -1,17 +1,31 @@
 package cat
 
 import (
-	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 	"vet-clinic-api/config"
 	"vet-clinic-api/database/dbmodel"
+	"vet-clinic-api/pkg/audit"
+	"vet-clinic-api/pkg/authentication"
 	"vet-clinic-api/pkg/model"
+	"vet-clinic-api/pkg/pagination"
+	"vet-clinic-api/pkg/patch"
+	"vet-clinic-api/pkg/problem"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
 )
 
+// Fields of the response used to sort the cats, with their column
+var sortFields = map[string]string{
+	"id":         "id",
+	"cat_name":   "name",
+	"cat_age":    "age",
+	"cat_breed":  "breed",
+	"cat_weight": "weight",
+}
+
 type CatConfig struct {
 	*config.Config
 }
@@ -29,115 +43,245 @@ func New(configuration *config.Config) *CatConfig {
 // @Param        cat  body      model.CatRequest  true  "Cat creation payload"
 // @Security     BearerAuth
 // @Success      200  {object}  model.CatResponse
-// @Failure      400  {object}  map[string]string  "Invalid Cat Post request payload"
-// @Failure      500  {object}  map[string]string  "Failed to Create specific Cat"
+// @Failure      400  {object}  problem.Problem  "Invalid Cat Post request payload"
+// @Failure      422  {object}  problem.Problem  "Validation failed"
+// @Failure      500  {object}  problem.Problem  "Failed to Create specific Cat"
 // @Router       /cats [post]
 func (config *CatConfig) PostHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Get the request
 	req := &model.CatRequest{}
 	if err := render.Bind(r, req); err != nil {
-		render.JSON(w, r, map[string]string{"error": "Invalid Cat Post request payload. " + err.Error()})
+		problem.Bind(w, r, err, "Invalid Cat Post request payload")
+		return
+	}
+
+	// Check if the linked owner id existe
+	if req.OwnerId != nil && !config.OwnerEntryRepository.FindLastOwnerId(int(*req.OwnerId)) {
+		problem.InvalidReference(w, r, "OwnerId not found in the DB")
 		return
 	}
 
 	// Convert the requested data into dbmodel.CatEntry type for the "Create" function
 	catEntry := &dbmodel.CatEntry{
-		Name:   *req.Name,
-		Age:    *req.Age,
-		Breed:  *req.Breed,
-		Weight: *req.Weight}
+		Name:    *req.Name,
+		Age:     *req.Age,
+		Breed:   *req.Breed,
+		Weight:  *req.Weight,
+		OwnerId: req.OwnerId}
 
 	// Request the DB to Create the informations
-	entries, err := config.CatEntryRepository.Create(catEntry)
+	created, err := config.CatEntryRepository.WithContext(audit.Context(r)).Create(catEntry)
 	if err != nil {
-		render.JSON(w, r, map[string]string{"error": "Failed to Create specific Cat"})
+		problem.Error(w, r, err, "Failed to Create specific Cat")
+		return
+	}
+
+	// Request the DB to get the created cat with its owner
+	entries, err := config.CatEntryRepository.FindById(int(created.ID))
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find created Cat")
 		return
 	}
 
 	// Set up to a dedicated type for the response
-	res := &model.CatResponse{
-		Id:     entries.ID,
-		Name:   entries.Name,
-		Age:    entries.Age,
-		Breed:  entries.Breed,
-		Weight: entries.Weight}
+	res := catResponse(entries)
 
 	render.JSON(w, r, res)
 }
 
 // GetAllHandler godoc
 // @Summary      Get all Cats
-// @Description  Find all the cats in the database
+// @Description  Find the cats in the database by page, optionally filtered by name, breed and owner. The total count is in the X-Total-Count header and the other pages in the Link header.
 // @Tags         cats
 // @Produce      json
+// @Param        name      query     string  false  "Filter by name"
+// @Param        breed     query     string  false  "Filter by breed"
+// @Param        owner_id  query     int     false  "Filter by owner id"
+// @Param        limit     query     int     false  "Number of cats of the page (default 50, max 200)"
+// @Param        offset    query     int     false  "Number of cats skipped, instead of the cursor"
+// @Param        cursor    query     string  false  "Cursor of the next page, given in the Link header"
+// @Param        sort      query     string  false  "Fields separated by commas, - for descending (id, cat_name, cat_age, cat_breed, cat_weight)"
 // @Security     BearerAuth
-// @Success      200  {array}  model.CatResponse
-// @Failure      500  {object}  map[string]string  "Failed to retrieve cats"
+// @Success      200  {array}   model.CatResponse
+// @Header       200  {integer}  X-Total-Count  "Number of cats matching the filters"
+// @Header       200  {string}   Link           "Links to the first, previous, next and last pages"
+// @Failure      400  {object}  problem.Problem  "Invalid filter or page"
+// @Failure      500  {object}  problem.Problem  "Failed to retrieve cats"
 // @Router       /cats [get]
 func (config *CatConfig) GetAllHandler(w http.ResponseWriter, r *http.Request) {
 
+	// Set up the filters
+	filter := dbmodel.CatFilter{Name: r.URL.Query().Get("name"), Breed: r.URL.Query().Get("breed")}
+
+	if value := r.URL.Query().Get("owner_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			problem.BadRequest(w, r, "owner_id must be a positive integer")
+			return
+		}
+		ownerId := uint(id)
+		filter.OwnerId = &ownerId
+	}
+
+	// Owners only get their own cats, none without owner id
+	if ownerId, ok := authentication.OwnerIdFromContext(r.Context()); ok {
+		filter.OwnerId = &ownerId
+	}
+
+	page, err := pagination.Parse(r, sortFields)
+	if err != nil {
+		problem.BadRequest(w, r, err.Error())
+		return
+	}
+
 	// Request the DB to get the needed informations
-	entries, err := config.CatEntryRepository.FindAll()
+	entries, info, err := config.CatEntryRepository.FindAll(filter, page)
 	if err != nil {
-		render.JSON(w, r, map[string]string{"error": "Invalid Find All Cats request payload"})
+		problem.Error(w, r, err, "Invalid Find All Cats request payload")
 		return
 	}
 
 	// Set up to a dedicated type for the response
-	var result []*model.CatResponse
+	result := []*model.CatResponse{}
 	for _, entrie := range entries {
-		result = append(result,
-			&model.CatResponse{
-				Id:     entrie.ID,
-				Name:   entrie.Name,
-				Age:    entrie.Age,
-				Breed:  entrie.Breed,
-				Weight: entrie.Weight})
+		result = append(result, catResponse(entrie))
 	}
 
+	pagination.Write(w, r, page, info)
 	render.JSON(w, r, result)
 }
 
 // GetByIdHandler godoc
 // @Summary      Get cat by ID
-// @Description  Retrieves a specific cat from the database by its ID
+// @Description  Retrieves a specific cat from the database by its ID. With as_of, the cat is rebuilt from its revisions as it was at this time, with its visits and treatments like model.CatHistoryResponse.
 // @Tags         cats
 // @Produce      json
-// @Param        id   path      int  true  "Cat ID"
+// @Param        id     path      int     true   "Cat ID"
+// @Param        as_of  query     string  false  "Time of the record (RFC 3339)"
 // @Security     BearerAuth
 // @Success      200  {object}  model.CatResponse
-// @Failure      404  {object}  map[string]string  "Cat not found"
-// @Failure      500  {object}  map[string]string  "Failed to find specific cat"
+// @Failure      400  {object}  problem.Problem  "Invalid as_of"
+// @Failure      403  {object}  problem.Problem  "Forbidden for an owner account"
+// @Failure      404  {object}  problem.Problem  "Cat not found"
+// @Failure      500  {object}  problem.Problem  "Failed to find specific cat"
 // @Router       /cats/{id} [get]
 func (config *CatConfig) GetByIdHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Get the id in the URL
 	idStr := chi.URLParam(r, "id")
 	if idStr == "" {
-		w.WriteHeader(http.StatusNotFound)
+		problem.NotFound(w, r, "Missing id")
 		return
 	}
 
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		fmt.Println("Error during id convertion")
+		problem.BadRequest(w, r, "id must be an integer")
+		return
+	}
+
+	// Owners can only read their own cats
+	if ownerId, ok := authentication.OwnerIdFromContext(r.Context()); ok && !config.CatEntryRepository.BelongsToOwner(id, ownerId) {
+		problem.Forbidden(w, r, "Cat not owned by the user")
+		return
+	}
+
+	// Rebuild the cat from its revisions when a time is given
+	if asOfStr := r.URL.Query().Get("as_of"); asOfStr != "" {
+		asOf, err := time.Parse(time.RFC3339, asOfStr)
+		if err != nil {
+			problem.BadRequest(w, r, "as_of wrong format, expected RFC 3339 like 2006-01-02T15:04:05Z")
+			return
+		}
+
+		entries, err := config.RevisionEntryRepository.FindCatAsOf(uint(id), asOf)
+		if err != nil {
+			problem.Error(w, r, err, "Failed to Find specific Cat at this time")
+			return
+		}
+
+		render.JSON(w, r, catHistoryResponse(entries, config.ClinicLocation))
+		return
 	}
 
 	// Request the DB to get the needed informations
 	entries, err := config.CatEntryRepository.FindById(id)
 	if err != nil {
-		render.JSON(w, r, map[string]string{"error": "Failed to Find specific Cat"})
+		problem.Error(w, r, err, "Failed to Find specific Cat")
 		return
 	}
 
 	// Set up to a dedicated type for the response
-	res := &model.CatResponse{
-		Id:     entries.ID,
-		Name:   entries.Name,
-		Age:    entries.Age,
-		Breed:  entries.Breed,
-		Weight: entries.Weight}
+	res := catResponse(entries)
+
+	render.JSON(w, r, res)
+}
+
+// GetRevisionsHandler godoc
+// @Summary      Get the revisions of a cat
+// @Description  Retrieves every saved state of a cat, oldest first, with the user who made the change. A deleted cat keeps its revisions.
+// @Tags         cats
+// @Produce      json
+// @Param        id   path      int  true  "Cat ID"
+// @Security     BearerAuth
+// @Success      200  {array}   model.CatRevisionResponse
+// @Failure      400  {object}  problem.Problem  "Invalid request"
+// @Failure      403  {object}  problem.Problem  "Forbidden for an owner account"
+// @Failure      500  {object}  problem.Problem  "Failed to find the revisions"
+// @Router       /cats/{id}/revisions [get]
+func (config *CatConfig) GetRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the id in the URL
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		problem.NotFound(w, r, "Missing id")
+		return
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		problem.BadRequest(w, r, "id must be an integer")
+		return
+	}
+
+	// Owners can only read their own cats
+	if ownerId, ok := authentication.OwnerIdFromContext(r.Context()); ok && !config.CatEntryRepository.BelongsToOwner(id, ownerId) {
+		problem.Forbidden(w, r, "Cat not owned by the user")
+		return
+	}
+
+	// Request the DB to get the revisions of the cat
+	entries, err := config.RevisionEntryRepository.FindByEntity(dbmodel.CatRevision, uint(id))
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find Cat revisions")
+		return
+	}
+
+	// Set up to a dedicated type for the response, with the current informations of the owners
+	owners := map[uint]*dbmodel.OwnerEntry{}
+	res := []*model.CatRevisionResponse{}
+	for _, entrie := range entries {
+		var cat dbmodel.CatEntry
+		if err := entrie.Decode(&cat); err != nil {
+			problem.Error(w, r, err, "Failed to Decode Cat revision")
+			return
+		}
+
+		if cat.OwnerId != nil {
+			if _, ok := owners[*cat.OwnerId]; !ok {
+				owners[*cat.OwnerId], _ = config.OwnerEntryRepository.FindById(int(*cat.OwnerId))
+			}
+			cat.Owner = owners[*cat.OwnerId]
+		}
+
+		res = append(res, &model.CatRevisionResponse{
+			Id:        entrie.ID,
+			Action:    entrie.Action,
+			CreatedAt: entrie.CreatedAt,
+			CreatedBy: entrie.CreatedBy,
+			Cat:       catResponse(&cat)})
+	}
 
 	render.JSON(w, r, res)
 }
@@ -150,58 +294,80 @@ func (config *CatConfig) GetByIdHandler(w http.ResponseWriter, r *http.Request)
 // @Param        id   path      int  true  "Cat ID"
 // @Security     BearerAuth
 // @Success      200  {object}  model.CatHistoryResponse
-// @Failure      404  {object}  map[string]string  "Cat not found"
-// @Failure      500  {object}  map[string]string  "Failed to find cat history"
+// @Failure      400  {object}  problem.Problem  "Invalid request"
+// @Failure      403  {object}  problem.Problem  "Forbidden for an owner account"
+// @Failure      404  {object}  problem.Problem  "Cat not found"
+// @Failure      500  {object}  problem.Problem  "Failed to find cat history"
 // @Router       /cats/{id}/history [get]
 func (config *CatConfig) GetCatHistoryHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Get the id in the URL
 	idStr := chi.URLParam(r, "id")
 	if idStr == "" {
-		w.WriteHeader(http.StatusNotFound)
+		problem.NotFound(w, r, "Missing id")
 		return
 	}
 
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		fmt.Println("Error during id convertion")
+		problem.BadRequest(w, r, "id must be an integer")
+		return
+	}
+
+	// Owners can only read their own cats
+	if ownerId, ok := authentication.OwnerIdFromContext(r.Context()); ok && !config.CatEntryRepository.BelongsToOwner(id, ownerId) {
+		problem.Forbidden(w, r, "Cat not owned by the user")
+		return
 	}
 
 	// Request the DB to get the needed informations
 	entries, err := config.CatEntryRepository.FindCatHistory(id)
 	if err != nil {
-		render.JSON(w, r, map[string]string{"error": "Failed to Find specific Cat"})
+		problem.Error(w, r, err, "Failed to Find specific Cat")
 		return
 	}
 
 	// Set up to a dedicated type for the response
+	res := catHistoryResponse(entries, config.ClinicLocation)
+
+	render.JSON(w, r, res)
+}
+
+// Set up a cat with its visits and treatments to a dedicated type for the responses, the times of the visits are given in the timezone of the clinic
+func catHistoryResponse(entries *dbmodel.CatEntry, location *time.Location) *model.CatHistoryResponse {
+
 	var visits []*model.VisitHistoryResponse
 	var treatments []*model.TreatmentHistoryResponse
 
 	for _, visit := range entries.Visits {
 		for _, treatment := range visit.Treatments {
-			treatments = append(treatments, &model.TreatmentHistoryResponse{Id: treatment.ID, Name: treatment.Name})
+			treatments = append(treatments, &model.TreatmentHistoryResponse{Id: treatment.ID, Name: treatment.Name, CreatedBy: treatment.CreatedBy, UpdatedBy: treatment.UpdatedBy})
 		}
 
+		startsAt, endsAt := visit.In(location)
 		visits = append(visits,
 			&model.VisitHistoryResponse{
 				Id:         visit.ID,
-				Date:       visit.Date,
+				StartsAt:   startsAt,
+				EndsAt:     endsAt,
 				Reason:     visit.Reason,
 				Vet:        visit.Vet,
-				Treatments: treatments})
+				Treatments: treatments,
+				CreatedBy:  visit.CreatedBy,
+				UpdatedBy:  visit.UpdatedBy})
 		treatments = nil
 	}
 
-	res := &model.CatHistoryResponse{
-		Id:     entries.ID,
-		Name:   entries.Name,
-		Age:    entries.Age,
-		Breed:  entries.Breed,
-		Weight: entries.Weight,
-		Visits: visits}
-
-	render.JSON(w, r, res)
+	return &model.CatHistoryResponse{
+		Id:        entries.ID,
+		Name:      entries.Name,
+		Age:       entries.Age,
+		Breed:     entries.Breed,
+		Weight:    entries.Weight,
+		Owner:     ownerSummary(entries.Owner),
+		Visits:    visits,
+		CreatedBy: entries.CreatedBy,
+		UpdatedBy: entries.UpdatedBy}
 }
 
 // UpdateHandler godoc
@@ -214,52 +380,151 @@ func (config *CatConfig) GetCatHistoryHandler(w http.ResponseWriter, r *http.Req
 // @Param        cat  body      model.CatRequest   true  "Cat update payload"
 // @Security     BearerAuth
 // @Success      200  {object}  model.CatResponse
-// @Failure      400  {object}  map[string]string  "Invalid request payload"
-// @Failure      404  {object}  map[string]string  "Cat not found"
-// @Failure      500  {object}  map[string]string  "Failed to update cat"
+// @Failure      400  {object}  problem.Problem  "Invalid request payload"
+// @Failure      404  {object}  problem.Problem  "Cat not found"
+// @Failure      422  {object}  problem.Problem  "Validation failed"
+// @Failure      500  {object}  problem.Problem  "Failed to update cat"
 // @Router       /cats/{id} [put]
 func (config *CatConfig) UpdateHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Get the id in the UR
 	idStr := chi.URLParam(r, "id")
 	if idStr == "" {
-		w.WriteHeader(http.StatusNotFound)
+		problem.NotFound(w, r, "Missing id")
 		return
 	}
 
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		fmt.Println("Error during id convertion")
+		problem.BadRequest(w, r, "id must be an integer")
+		return
 	}
 
 	// Get the request
 	req := &model.CatRequest{}
 	if err := render.Bind(r, req); err != nil {
-		render.JSON(w, r, map[string]string{"error": "Invalid Cat Update request payload. " + err.Error()})
+		problem.Bind(w, r, err, "Invalid Cat Update request payload")
+		return
+	}
+
+	// Check if the linked owner id existe
+	if req.OwnerId != nil && !config.OwnerEntryRepository.FindLastOwnerId(int(*req.OwnerId)) {
+		problem.InvalidReference(w, r, "OwnerId not found in the DB")
 		return
 	}
 
 	// Convert the requested data into dbmodel.CatEntry type for the "Update" function
 	catEntry := &dbmodel.CatEntry{
-		Name:   *req.Name,
-		Age:    *req.Age,
-		Breed:  *req.Breed,
-		Weight: *req.Weight}
+		Name:    *req.Name,
+		Age:     *req.Age,
+		Breed:   *req.Breed,
+		Weight:  *req.Weight,
+		OwnerId: req.OwnerId}
 
 	// Request the DB to Update the informations
-	entries, err := config.CatEntryRepository.Update(id, catEntry)
+	if _, err := config.CatEntryRepository.WithContext(audit.Context(r)).Update(id, catEntry); err != nil {
+		problem.Error(w, r, err, "Failed to Update Cat")
+		return
+	}
+
+	// Request the DB to get the updated cat with its owner
+	entries, err := config.CatEntryRepository.FindById(id)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find updated Cat")
+		return
+	}
+
+	// Set up to a dedicated type for the response
+	res := catResponse(entries)
+
+	render.JSON(w, r, res)
+}
+
+// PatchHandler godoc
+// @Summary      Partially update a cat
+// @Description  Updates only the given fields of a cat, with a JSON Merge Patch (Content-Type application/merge-patch+json) or a JSON Patch (Content-Type application/json-patch+json). The patched cat is checked like a full update.
+// @Tags         cats
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int               true  "Cat ID"
+// @Param        cat  body      model.CatRequest  true  "Fields to change (merge patch) or operations (JSON patch)"
+// @Security     BearerAuth
+// @Success      200  {object}  model.CatResponse
+// @Failure      400  {object}  problem.Problem  "Invalid request payload"
+// @Failure      404  {object}  problem.Problem  "Cat not found"
+// @Failure      409  {object}  problem.Problem  "JSON Patch test failed"
+// @Failure      415  {object}  problem.Problem  "Unsupported Content-Type"
+// @Failure      422  {object}  problem.Problem  "Validation failed or operation not applicable"
+// @Failure      500  {object}  problem.Problem  "Failed to update cat"
+// @Router       /cats/{id} [patch]
+func (config *CatConfig) PatchHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the id in the URL
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		problem.NotFound(w, r, "Missing id")
+		return
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		problem.BadRequest(w, r, "id must be an integer")
+		return
+	}
+
+	// Get the current cat, the patch is applied to its informations
+	before, err := config.CatEntryRepository.FindById(id)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find specific Cat")
+		return
+	}
+
+	req := &model.CatRequest{}
+	if err := patch.Apply(r, catRequest(before), req); err != nil {
+		problem.Patch(w, r, err, "Invalid Cat Patch request payload")
+		return
+	}
+
+	// The patched cat is checked like a full update
+	if err := req.Bind(r); err != nil {
+		problem.Bind(w, r, err, "Invalid Cat Patch request payload")
+		return
+	}
+
+	// Check if the linked owner id existe
+	if req.OwnerId != nil && !config.OwnerEntryRepository.FindLastOwnerId(int(*req.OwnerId)) {
+		problem.InvalidReference(w, r, "OwnerId not found in the DB")
+		return
+	}
+
+	// Only the changed columns are updated
+	columns := map[string]interface{}{}
+	patch.Changed(columns, "name", before.Name, *req.Name)
+	patch.Changed(columns, "age", before.Age, *req.Age)
+	patch.Changed(columns, "breed", before.Breed, *req.Breed)
+	patch.Changed(columns, "weight", before.Weight, *req.Weight)
+	patch.Changed(columns, "owner_id", before.OwnerId, req.OwnerId)
+
+	if len(columns) == 0 {
+		render.JSON(w, r, catResponse(before))
+		return
+	}
+
+	// Request the DB to Update the changed informations
+	if _, err := config.CatEntryRepository.WithContext(audit.Context(r)).Patch(id, columns); err != nil {
+		problem.Error(w, r, err, "Failed to Update Cat")
+		return
+	}
+
+	// Request the DB to get the updated cat
+	entries, err := config.CatEntryRepository.FindById(id)
 	if err != nil {
-		render.JSON(w, r, map[string]string{"error": "Failed to Update Cat"})
+		problem.Error(w, r, err, "Failed to Find updated Cat")
 		return
 	}
 
 	// Set up to a dedicated type for the response
-	res := &model.CatResponse{
-		Id:     uint(id),
-		Name:   entries.Name,
-		Age:    entries.Age,
-		Breed:  entries.Breed,
-		Weight: entries.Weight}
+	res := catResponse(entries)
 
 	render.JSON(w, r, res)
 }
@@ -272,29 +537,115 @@ func (config *CatConfig) UpdateHandler(w http.ResponseWriter, r *http.Request) {
 // @Param        id   path      int  true  "Cat ID"
 // @Security     BearerAuth
 // @Success      200  {object}  map[string]string  "Cat deleted successfully"
-// @Failure      404  {object}  map[string]string  "Cat not found"
-// @Failure      500  {object}  map[string]string  "Failed to delete cat"
+// @Failure      400  {object}  problem.Problem  "Invalid request"
+// @Failure      404  {object}  problem.Problem  "Cat not found"
+// @Failure      500  {object}  problem.Problem  "Failed to delete cat"
 // @Router       /cats/{id} [delete]
 func (config *CatConfig) DeleteHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Get the id in the UR
 	idStr := chi.URLParam(r, "id")
 	if idStr == "" {
-		w.WriteHeader(http.StatusNotFound)
+		problem.NotFound(w, r, "Missing id")
 		return
 	}
 
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		fmt.Println("Error during id convertion")
+		problem.BadRequest(w, r, "id must be an integer")
+		return
 	}
 
 	// Request the DB to Delete the informations
-	errDelete := config.CatEntryRepository.DeleteById(id)
+	errDelete := config.CatEntryRepository.WithContext(audit.Context(r)).DeleteById(id)
 	if errDelete != nil {
-		render.JSON(w, r, map[string]string{"error": "Failed to Delete Cat"})
+		problem.Error(w, r, errDelete, "Failed to Delete Cat")
 		return
 	}
 
 	render.JSON(w, r, map[string]string{"message": "Cat deleted successfully"})
 }
+
+// RestoreHandler godoc
+// @Summary      Restore a cat
+// @Description  Takes a cat out of the trash with the visits and treatments deleted with it
+// @Tags         cats
+// @Produce      json
+// @Param        id   path      int  true  "Cat ID"
+// @Security     BearerAuth
+// @Success      200  {object}  model.CatResponse
+// @Failure      400  {object}  problem.Problem  "Invalid request"
+// @Failure      404  {object}  problem.Problem  "Cat not found in the trash"
+// @Router       /cats/{id}/restore [post]
+func (config *CatConfig) RestoreHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the id in the URL
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		problem.NotFound(w, r, "Missing id")
+		return
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		problem.BadRequest(w, r, "id must be an integer")
+		return
+	}
+
+	// Request the DB to take the cat out of the trash
+	if _, err := config.CatEntryRepository.WithContext(audit.Context(r)).Restore(id); err != nil {
+		problem.Error(w, r, err, "Cat not found in the trash")
+		return
+	}
+
+	// Request the DB to get the restored cat
+	entries, err := config.CatEntryRepository.FindById(id)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find restored Cat")
+		return
+	}
+
+	// Set up to a dedicated type for the response
+	res := catResponse(entries)
+
+	render.JSON(w, r, res)
+}
+
+// Set up a cat to the request model, the patches are applied to it
+func catRequest(entries *dbmodel.CatEntry) *model.CatRequest {
+
+	return &model.CatRequest{
+		Name:    &entries.Name,
+		Age:     &entries.Age,
+		Breed:   &entries.Breed,
+		Weight:  &entries.Weight,
+		OwnerId: entries.OwnerId}
+}
+
+// Set up a cat to a dedicated type for the responses
+func catResponse(entries *dbmodel.CatEntry) *model.CatResponse {
+
+	return &model.CatResponse{
+		Id:        entries.ID,
+		Name:      entries.Name,
+		Age:       entries.Age,
+		Breed:     entries.Breed,
+		Weight:    entries.Weight,
+		Owner:     ownerSummary(entries.Owner),
+		CreatedBy: entries.CreatedBy,
+		UpdatedBy: entries.UpdatedBy}
+}
+
+// Set up the owner of a cat to a dedicated type for the responses
+func ownerSummary(owner *dbmodel.OwnerEntry) *model.OwnerSummaryResponse {
+
+	if owner == nil {
+		return nil
+	}
+
+	return &model.OwnerSummaryResponse{
+		Id:    owner.ID,
+		Name:  owner.Name,
+		Phone: owner.Phone,
+		Email: owner.Email}
+}
@@ -0,0 +1,102 @@
+package cat
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"vet-clinic-api/config"
+	"vet-clinic-api/database/databasetest"
+	"vet-clinic-api/database/dbmodel"
+	"vet-clinic-api/pkg/authentication"
+)
+
+// Owners only read their own cats, the staff reads every cat
+func TestOwnerScope(t *testing.T) {
+
+	db := databasetest.Open(t)
+	keys := authentication.NewHMACKeySet("access secret")
+	configuration := &config.Config{
+		CatEntryRepository:      dbmodel.NewCatEntryRepository(db),
+		OwnerEntryRepository:    dbmodel.NewOwnerEntryRepository(db),
+		RevisionEntryRepository: dbmodel.NewRevisionEntryRepository(db),
+		RoleEntryRepository:     dbmodel.NewRoleEntryRepository(db),
+		AccessTokenKeys:         keys,
+	}
+	router := Routes(configuration)
+
+	// Cats of Alice and Bob by name
+	ids := map[string]uint{}
+	for _, name := range []string{"Alice", "Bob"} {
+		owner, err := configuration.OwnerEntryRepository.Create(&dbmodel.OwnerEntry{Name: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		cat, err := configuration.CatEntryRepository.Create(&dbmodel.CatEntry{Name: "Cat of " + name, OwnerId: &owner.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[name], ids["Cat of "+name] = owner.ID, cat.ID
+	}
+
+	token := func(claims map[string]interface{}) string {
+		signed, err := authentication.GenerateToken(keys, claims, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return "Bearer " + signed
+	}
+	alice := token(map[string]interface{}{"user_id": 2, "role": "owner", "owner_id": ids["Alice"]})
+	withoutOwner := token(map[string]interface{}{"user_id": 3, "role": "owner"})
+	vet := token(map[string]interface{}{"user_id": 4, "role": "vet"})
+
+	tests := []struct {
+		name          string
+		authorization string
+		method        string
+		path          string
+		status        int
+		cats          string
+	}{
+		{"owner lists their cats", alice, "GET", "/", 200, "[Cat of Alice]"},
+		{"owner filtering on another owner", alice, "GET", fmt.Sprintf("/?owner_id=%d", ids["Bob"]), 200, "[Cat of Alice]"},
+		{"owner without owner id", withoutOwner, "GET", "/", 200, "[]"},
+		{"vet lists every cat", vet, "GET", "/", 200, "[Cat of Alice Cat of Bob]"},
+		{"owner reads their cat", alice, "GET", fmt.Sprintf("/%d", ids["Cat of Alice"]), 200, ""},
+		{"owner reads another cat", alice, "GET", fmt.Sprintf("/%d", ids["Cat of Bob"]), 403, ""},
+		{"owner reads the history of another cat", alice, "GET", fmt.Sprintf("/%d/history", ids["Cat of Bob"]), 403, ""},
+		{"owner reads the revisions of another cat", alice, "GET", fmt.Sprintf("/%d/revisions", ids["Cat of Bob"]), 403, ""},
+		{"owner creates a cat", alice, "POST", "/", 403, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.path, nil)
+			r.Header.Set("Authorization", tt.authorization)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+
+			if w.Code != tt.status {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if tt.cats == "" {
+				return
+			}
+
+			var cats []struct {
+				Name string `json:"cat_name"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&cats); err != nil {
+				t.Fatal(err)
+			}
+			names := []string{}
+			for _, cat := range cats {
+				names = append(names, cat.Name)
+			}
+			if got := fmt.Sprint(names); got != tt.cats {
+				t.Errorf("got %s, want %s", got, tt.cats)
+			}
+		})
+	}
+}
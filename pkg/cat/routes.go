@@ -15,17 +15,23 @@ func Routes(configuration *config.Config) chi.Router {
 
 	// Routes protected by authentication
 	router.Group(func(router chi.Router) {
-		router.Use(authentication.AuthMiddleware(catConfig.JWTSecret))
-
-		router.Get("/{id}", catConfig.GetByIdHandler)
-		router.Get("/{id}/history", catConfig.GetCatHistoryHandler)
-		router.Get("/", catConfig.GetAllHandler)
+		router.Use(authentication.AuthMiddleware(catConfig.AccessTokenKeys, catConfig.RevocationStore, catConfig.ApiKeyStore, catConfig.RoleEntryRepository))
+
+		// Routes protected by authentication and accessible with the "cats:read" permission
+		router.With(authentication.RequirePermission("cats:read")).Group(func(r chi.Router) {
+			r.Get("/{id}", catConfig.GetByIdHandler)
+			r.Get("/{id}/history", catConfig.GetCatHistoryHandler)
+			r.Get("/{id}/revisions", catConfig.GetRevisionsHandler)
+			r.Get("/", catConfig.GetAllHandler)
+		})
 
-		// Routes protected by authentication and accessible by admin only
-		router.With(authentication.RoleMiddleware("admin")).Group(func(r chi.Router) {
+		// Routes protected by authentication and accessible with the "cats:write" permission
+		router.With(authentication.RequirePermission("cats:write")).Group(func(r chi.Router) {
 			r.Post("/", catConfig.PostHandler)
 			r.Put("/{id}", catConfig.UpdateHandler)
+			r.Patch("/{id}", catConfig.PatchHandler)
 			r.Delete("/{id}", catConfig.DeleteHandler)
+			r.Post("/{id}/restore", catConfig.RestoreHandler)
 		})
 	})
 
@@ -0,0 +1,98 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Send the emails of the API, like the password reset and invitation links
+type Mailer interface {
+	Send(to string, subject string, body string) error
+}
+
+type smtpMailer struct {
+	address string
+	from    string
+	auth    smtp.Auth
+}
+
+type logMailer struct {
+	mutex sync.Mutex
+	dir   string
+}
+
+// Create a mailer sending the emails through an SMTP server, without authentication when no username is given
+func NewSMTPMailer(host string, port string, username string, password string, from string) Mailer {
+
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &smtpMailer{address: net.JoinHostPort(host, port), from: from, auth: auth}
+}
+
+// Create a mailer for local development, the emails are written in a directory or in the logs when no directory is given
+func NewLogMailer(dir string) Mailer {
+	return &logMailer{dir: dir}
+}
+
+func (m *smtpMailer) Send(to string, subject string, body string) error {
+	return smtp.SendMail(m.address, m.auth, m.from, []string{to}, message(m.from, to, subject, body))
+}
+
+func (m *logMailer) Send(to string, subject string, body string) error {
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.dir == "" {
+		log.Printf("Mail to %s\nSubject: %s\n\n%s\n", to, subject, body)
+		return nil
+	}
+
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102-150405.000000"), sanitize(to))
+
+	return os.WriteFile(filepath.Join(m.dir, name), message("", to, subject, body), 0o600)
+}
+
+// Build a plain text email, the line breaks are removed from the headers to prevent header injection
+func message(from string, to string, subject string, body string) []byte {
+
+	headers := ""
+	if from != "" {
+		headers += "From: " + headerValue(from) + "\r\n"
+	}
+
+	headers += "To: " + headerValue(to) + "\r\n" +
+		"Subject: " + headerValue(subject) + "\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n\r\n"
+
+	return []byte(headers + strings.ReplaceAll(body, "\n", "\r\n"))
+}
+
+func headerValue(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
+
+// Keep only the characters allowed in a file name
+func sanitize(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '@' || r == '.' || r == '-' || r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, value)
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"net/http"
+	"time"
+	"vet-clinic-api/pkg/validation"
+)
+
+type ApiKeyRequest struct {
+	Name      *string    `json:"api_key_name"`
+	Scopes    []string   `json:"api_key_scopes"`
+	ExpiresAt *time.Time `json:"api_key_expires_at"`
+}
+
+// Allow to check requested value in the body, a key without expiration date is valid until deleted
+func (a *ApiKeyRequest) Bind(r *http.Request) error {
+
+	return validation.New().
+		Field("api_key_name", a.Name, validation.Required(), validation.MaxLength(100)).
+		Field("api_key_scopes", a.Scopes, validation.Required(), validation.MinItems(1)).
+		Field("api_key_expires_at", a.ExpiresAt, validation.Future()).
+		Err()
+}
+
+type ApiKeyResponse struct {
+	Id         uint       `json:"id"`
+	Name       string     `json:"api_key_name"`
+	Prefix     string     `json:"api_key_prefix"`
+	Scopes     []string   `json:"api_key_scopes"`
+	UserId     uint       `json:"api_key_user_id"`
+	ExpiresAt  *time.Time `json:"api_key_expires_at"`
+	LastUsedAt *time.Time `json:"api_key_last_used_at"`
+	CreatedAt  time.Time  `json:"api_key_created_at"`
+
+	// Only given once, when the key is created
+	Key string `json:"api_key,omitempty"`
+}
@@ -0,0 +1,154 @@
+package model
+
+import (
+	"net/http"
+	"time"
+	"vet-clinic-api/pkg/validation"
+)
+
+type AppointmentRequest struct {
+	CatId    *uint      `json:"appointment_cat_id"`
+	Vet      *string    `json:"appointment_vet"`
+	Reason   *string    `json:"appointment_reason"`
+	StartsAt *time.Time `json:"appointment_starts_at"`
+	EndsAt   *time.Time `json:"appointment_ends_at"`
+}
+
+// Allow to check requested value in the body, without an end the appointment lasts the duration of its reason
+func (a *AppointmentRequest) Bind(r *http.Request) error {
+
+	return validation.New().
+		Field("appointment_cat_id", a.CatId, validation.Required(), validation.Min(1)).
+		Field("appointment_vet", a.Vet, validation.Required(), validation.MaxLength(100)).
+		Field("appointment_reason", a.Reason, validation.Required(), validation.MaxLength(500)).
+		Field("appointment_starts_at", a.StartsAt, validation.Required(), validation.Future()).
+		Field("appointment_ends_at", a.EndsAt, validation.After("appointment_starts_at", a.StartsAt)).
+		Err()
+}
+
+type RescheduleRequest struct {
+	Vet      *string    `json:"appointment_vet"`
+	StartsAt *time.Time `json:"appointment_starts_at"`
+	EndsAt   *time.Time `json:"appointment_ends_at"`
+}
+
+// Allow to check requested value in the body, the vet is kept when it is not given
+func (a *RescheduleRequest) Bind(r *http.Request) error {
+
+	return validation.New().
+		Field("appointment_vet", a.Vet, validation.MaxLength(100)).
+		Field("appointment_starts_at", a.StartsAt, validation.Required(), validation.Future()).
+		Field("appointment_ends_at", a.EndsAt, validation.After("appointment_starts_at", a.StartsAt)).
+		Err()
+}
+
+type AppointmentResponse struct {
+	Id                uint      `json:"id"`
+	CatId             uint      `json:"appointment_cat_id"`
+	Vet               string    `json:"appointment_vet"`
+	Reason            string    `json:"appointment_reason"`
+	StartsAt          time.Time `json:"appointment_starts_at"`
+	EndsAt            time.Time `json:"appointment_ends_at"`
+	Status            string    `json:"appointment_status"`
+	RescheduledFromId *uint     `json:"appointment_rescheduled_from_id"`
+	VisitId           *uint     `json:"appointment_visit_id"`
+	CreatedBy         *uint     `json:"appointment_created_by"`
+	UpdatedBy         *uint     `json:"appointment_updated_by"`
+}
+
+type SlotResponse struct {
+	Vet      string    `json:"slot_vet"`
+	StartsAt time.Time `json:"slot_starts_at"`
+	EndsAt   time.Time `json:"slot_ends_at"`
+}
+
+type WorkingHoursRequest struct {
+	Vet     *string `json:"working_hours_vet"`
+	Weekday *int    `json:"working_hours_weekday"`
+	Start   *string `json:"working_hours_start"`
+	End     *string `json:"working_hours_end"`
+}
+
+// Allow to check requested value in the body, the times of the day are in the timezone of the clinic
+func (a *WorkingHoursRequest) Bind(r *http.Request) error {
+
+	v := validation.New().
+		Field("working_hours_vet", a.Vet, validation.Required(), validation.MaxLength(100)).
+		Field("working_hours_weekday", a.Weekday, validation.Required(), validation.Min(0), validation.Max(6)).
+		Field("working_hours_start", a.Start, validation.Required(), validation.Date("15:04", "HH:MM")).
+		Field("working_hours_end", a.End, validation.Required(), validation.Date("15:04", "HH:MM"))
+
+	if len(v.Errors()) > 0 {
+		return v.Err()
+	}
+
+	// Saved with two digits, so the times are ordered like texts
+	start, _ := time.Parse("15:04", *a.Start)
+	end, _ := time.Parse("15:04", *a.End)
+	*a.Start, *a.End = start.Format("15:04"), end.Format("15:04")
+
+	if !end.After(start) {
+		v.Add("working_hours_end", validation.CodeAfter, "working_hours_end must be after working_hours_start", map[string]interface{}{"field": "working_hours_start"})
+	}
+
+	return v.Err()
+}
+
+type WorkingHoursResponse struct {
+	Id      uint   `json:"id"`
+	Vet     string `json:"working_hours_vet"`
+	Weekday int    `json:"working_hours_weekday"`
+	Start   string `json:"working_hours_start"`
+	End     string `json:"working_hours_end"`
+}
+
+type HolidayRequest struct {
+	Vet      *string    `json:"holiday_vet"`
+	Name     *string    `json:"holiday_name"`
+	StartsAt *time.Time `json:"holiday_starts_at"`
+	EndsAt   *time.Time `json:"holiday_ends_at"`
+}
+
+// Allow to check requested value in the body, a holiday without a vet closes the whole clinic
+func (a *HolidayRequest) Bind(r *http.Request) error {
+
+	v := validation.New().
+		Field("holiday_vet", a.Vet, validation.MaxLength(100)).
+		Field("holiday_name", a.Name, validation.Required(), validation.MaxLength(100)).
+		Field("holiday_starts_at", a.StartsAt, validation.Required()).
+		Field("holiday_ends_at", a.EndsAt, validation.Required(), validation.After("holiday_starts_at", a.StartsAt))
+
+	if a.Vet == nil {
+		a.Vet = new(string)
+	}
+
+	return v.Err()
+}
+
+type HolidayResponse struct {
+	Id       uint      `json:"id"`
+	Vet      string    `json:"holiday_vet"`
+	Name     string    `json:"holiday_name"`
+	StartsAt time.Time `json:"holiday_starts_at"`
+	EndsAt   time.Time `json:"holiday_ends_at"`
+}
+
+type AppointmentReasonRequest struct {
+	Name     *string `json:"reason_name"`
+	Duration *int    `json:"reason_duration"`
+}
+
+// Allow to check requested value in the body, the duration is in minutes
+func (a *AppointmentReasonRequest) Bind(r *http.Request) error {
+
+	return validation.New().
+		Field("reason_name", a.Name, validation.Required(), validation.MaxLength(500)).
+		Field("reason_duration", a.Duration, validation.Required(), validation.Min(5), validation.Max(480)).
+		Err()
+}
+
+type AppointmentReasonResponse struct {
+	Id       uint   `json:"id"`
+	Name     string `json:"reason_name"`
+	Duration int    `json:"reason_duration"`
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditLogResponse struct {
+	Id         uint            `json:"id"`
+	CreatedAt  time.Time       `json:"audit_created_at"`
+	ActorId    *uint           `json:"audit_actor_id"`
+	ActorEmail string          `json:"audit_actor_email"`
+	Action     string          `json:"audit_action"`
+	Entity     string          `json:"audit_entity"`
+	EntityId   uint            `json:"audit_entity_id"`
+	Diff       json.RawMessage `json:"audit_diff" swaggertype:"object"`
+	RequestId  string          `json:"audit_request_id"`
+	IP         string          `json:"audit_ip"`
+	PrevHash   string          `json:"audit_prev_hash"`
+	Hash       string          `json:"audit_hash"`
+}
+
+type AuditVerifyResponse struct {
+	Valid   bool  `json:"audit_valid"`
+	Entries int64 `json:"audit_entries"`
+
+	// Id of the first altered entry, when the chain is broken
+	BrokenId uint `json:"audit_broken_id,omitempty"`
+}
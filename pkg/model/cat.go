@@ -1,52 +1,58 @@
 package model
 
 import (
-	"errors"
 	"net/http"
+	"time"
+	"vet-clinic-api/pkg/validation"
 )
 
 type CatRequest struct {
-	Name   *string `json:"cat_name"`
-	Age    *int    `json:"cat_age"`
-	Breed  *string `json:"cat_breed"`
-	Weight *int    `json:"cat_weight"`
+	Name    *string `json:"cat_name"`
+	Age     *int    `json:"cat_age"`
+	Breed   *string `json:"cat_breed"`
+	Weight  *int    `json:"cat_weight"`
+	OwnerId *uint   `json:"cat_owner_id"`
 }
 
 // Allow to check requested value in the body
 func (a *CatRequest) Bind(r *http.Request) error {
 
-	if a.Name == nil || *a.Name == "" {
-		return errors.New("cat_name is empty")
-	}
-
-	if a.Age == nil || *a.Age <= 0 {
-		return errors.New("cat_age must be a positive integer")
-	}
-
-	if a.Breed == nil || *a.Breed == "" {
-		return errors.New("cat_breed is empty")
-	}
-
-	if a.Weight == nil || *a.Weight <= 0 {
-		return errors.New("cat_weight must be a positive integer")
-	}
-
-	return nil
+	return validation.New().
+		Field("cat_name", a.Name, validation.Required(), validation.MaxLength(100)).
+		Field("cat_age", a.Age, validation.Required(), validation.Min(1), validation.Max(40)).
+		Field("cat_breed", a.Breed, validation.Required(), validation.MaxLength(100)).
+		Field("cat_weight", a.Weight, validation.Required(), validation.Min(1)).
+		Field("cat_owner_id", a.OwnerId, validation.Min(1)).
+		Err()
 }
 
 type CatResponse struct {
-	Id     uint   `json:"id"`
-	Name   string `json:"cat_name"`
-	Age    int    `json:"cat_age"`
-	Breed  string `json:"cat_breed"`
-	Weight int    `json:"cat_weight"`
+	Id        uint                  `json:"id"`
+	Name      string                `json:"cat_name"`
+	Age       int                   `json:"cat_age"`
+	Breed     string                `json:"cat_breed"`
+	Weight    int                   `json:"cat_weight"`
+	Owner     *OwnerSummaryResponse `json:"cat_owner"`
+	CreatedBy *uint                 `json:"cat_created_by"`
+	UpdatedBy *uint                 `json:"cat_updated_by"`
 }
 
 type CatHistoryResponse struct {
-	Id     uint                    `json:"id"`
-	Name   string                  `json:"cat_name"`
-	Age    int                     `json:"cat_age"`
-	Breed  string                  `json:"cat_breed"`
-	Weight int                     `json:"cat_weight"`
-	Visits []*VisitHistoryResponse `json:"cat_visits"`
+	Id        uint                    `json:"id"`
+	Name      string                  `json:"cat_name"`
+	Age       int                     `json:"cat_age"`
+	Breed     string                  `json:"cat_breed"`
+	Weight    int                     `json:"cat_weight"`
+	Owner     *OwnerSummaryResponse   `json:"cat_owner"`
+	Visits    []*VisitHistoryResponse `json:"cat_visits"`
+	CreatedBy *uint                   `json:"cat_created_by"`
+	UpdatedBy *uint                   `json:"cat_updated_by"`
+}
+
+type CatRevisionResponse struct {
+	Id        uint         `json:"id"`
+	Action    string       `json:"revision_action"`
+	CreatedAt time.Time    `json:"revision_created_at"`
+	CreatedBy *uint        `json:"revision_created_by"`
+	Cat       *CatResponse `json:"revision_cat"`
 }
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"net/http"
+	"vet-clinic-api/pkg/validation"
+)
+
+type MfaCodeRequest struct {
+	Code *string `json:"mfa_code"`
+}
+
+type MfaTokenRequest struct {
+	Token *string `json:"mfa_token"`
+}
+
+type MfaLoginRequest struct {
+	Token *string `json:"mfa_token"`
+	Code  *string `json:"mfa_code"`
+}
+
+// Allow to check requested value in the body
+func (a *MfaCodeRequest) Bind(r *http.Request) error {
+
+	return validation.New().
+		Field("mfa_code", a.Code, validation.Required()).
+		Err()
+}
+
+// Allow to check requested value in the body
+func (a *MfaTokenRequest) Bind(r *http.Request) error {
+
+	return validation.New().
+		Field("mfa_token", a.Token, validation.Required()).
+		Err()
+}
+
+// Allow to check requested value in the body
+func (a *MfaLoginRequest) Bind(r *http.Request) error {
+
+	return validation.New().
+		Field("mfa_token", a.Token, validation.Required()).
+		Field("mfa_code", a.Code, validation.Required()).
+		Err()
+}
+
+// Returned by the login when a TOTP code is needed to get the tokens
+type MfaChallengeResponse struct {
+	Token              string `json:"mfa_token"`
+	EnrollmentRequired bool   `json:"mfa_enrollment_required"`
+}
+
+type MfaEnrollResponse struct {
+	Secret     string `json:"mfa_secret"`
+	OtpauthURI string `json:"mfa_otpauth_uri"`
+}
+
+type MfaRecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"mfa_recovery_codes"`
+}
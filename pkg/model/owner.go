@@ -0,0 +1,52 @@
+package model
+
+import (
+	"net/http"
+	"vet-clinic-api/pkg/validation"
+)
+
+type OwnerRequest struct {
+	Name    *string `json:"owner_name"`
+	Phone   *string `json:"owner_phone"`
+	Email   *string `json:"owner_email"`
+	Address *string `json:"owner_address"`
+}
+
+// Allow to check requested value in the body
+func (a *OwnerRequest) Bind(r *http.Request) error {
+
+	v := validation.New().
+		Field("owner_name", a.Name, validation.Required(), validation.MaxLength(100)).
+		Field("owner_phone", a.Phone, validation.Required(), validation.MaxLength(30)).
+		Field("owner_address", a.Address, validation.MaxLength(255))
+
+	// The email of an owner is optional
+	if a.Email != nil && *a.Email != "" {
+		v.Field("owner_email", a.Email, validation.Email())
+	}
+
+	if a.Email == nil {
+		a.Email = new(string)
+	}
+
+	if a.Address == nil {
+		a.Address = new(string)
+	}
+
+	return v.Err()
+}
+
+type OwnerResponse struct {
+	Id      uint   `json:"id"`
+	Name    string `json:"owner_name"`
+	Phone   string `json:"owner_phone"`
+	Email   string `json:"owner_email"`
+	Address string `json:"owner_address"`
+}
+
+type OwnerSummaryResponse struct {
+	Id    uint   `json:"id"`
+	Name  string `json:"owner_name"`
+	Phone string `json:"owner_phone"`
+	Email string `json:"owner_email"`
+}
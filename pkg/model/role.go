@@ -0,0 +1,40 @@
+package model
+
+import (
+	"net/http"
+	"vet-clinic-api/pkg/validation"
+)
+
+type RoleRequest struct {
+	Name        *string  `json:"role_name"`
+	Description *string  `json:"role_description"`
+	Permissions []string `json:"role_permissions"`
+}
+
+// Allow to check requested value in the body
+func (a *RoleRequest) Bind(r *http.Request) error {
+
+	v := validation.New().
+		Field("role_name", a.Name, validation.Required(), validation.MaxLength(50)).
+		Field("role_description", a.Description, validation.MaxLength(255)).
+		Field("role_permissions", a.Permissions, validation.Required())
+
+	if a.Description == nil {
+		a.Description = new(string)
+	}
+
+	return v.Err()
+}
+
+type RoleResponse struct {
+	Id          uint     `json:"id"`
+	Name        string   `json:"role_name"`
+	Description string   `json:"role_description"`
+	Permissions []string `json:"role_permissions"`
+}
+
+type PermissionResponse struct {
+	Id          uint   `json:"id"`
+	Name        string `json:"permission_name"`
+	Description string `json:"permission_description"`
+}
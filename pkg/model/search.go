@@ -0,0 +1,11 @@
+package model
+
+type SearchResultResponse struct {
+	Id     uint    `json:"id"`
+	Entity string  `json:"search_entity"`
+	CatId  uint    `json:"search_cat_id"`
+	Rank   float64 `json:"search_rank"`
+
+	// Searched fields of the record, the matched terms are between <mark> and </mark> in the HTML escaped text
+	Highlights map[string]string `json:"search_highlights"`
+}
@@ -1,8 +1,8 @@
 package model
 
 import (
-	"errors"
 	"net/http"
+	"vet-clinic-api/pkg/validation"
 )
 
 type RefreshTokenRequest struct {
@@ -12,18 +12,15 @@ type RefreshTokenRequest struct {
 // Allow to check requested value in the body
 func (a *RefreshTokenRequest) Bind(r *http.Request) error {
 
-	if a.RefreshToken == nil || *a.RefreshToken == "" {
-		return errors.New("refresh_token is empty")
-	}
-
-	return nil
-}
-
-type AccessTokenResponse struct {
-	AccessToken string `json:"access_token"`
+	return validation.New().
+		Field("refresh_token", a.RefreshToken, validation.Required()).
+		Err()
 }
 
 type TokensResponse struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
+
+	// Only given once, when the TOTP enrollment is confirmed during the login
+	RecoveryCodes []string `json:"mfa_recovery_codes,omitempty"`
 }
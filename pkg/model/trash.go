@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+type TrashEntryResponse struct {
+	Entity   string `json:"trash_entity"`
+	EntityId uint   `json:"trash_entity_id"`
+	Name     string `json:"trash_name"`
+
+	// Cat of a visit or visit of a treatment
+	ParentId *uint `json:"trash_parent_id"`
+
+	DeletedAt time.Time `json:"trash_deleted_at"`
+	DeletedBy *uint     `json:"trash_deleted_by"`
+
+	// Time from which the purge permanently deletes the record
+	PurgeAt time.Time `json:"trash_purge_at"`
+}
+
+type TrashPurgeResponse struct {
+	Cats       int `json:"trash_purged_cats"`
+	Visits     int `json:"trash_purged_visits"`
+	Treatments int `json:"trash_purged_treatments"`
+}
@@ -1,8 +1,8 @@
 package model
 
 import (
-	"errors"
 	"net/http"
+	"vet-clinic-api/pkg/validation"
 )
 
 type TreatmentRequest struct {
@@ -13,24 +13,23 @@ type TreatmentRequest struct {
 // Allow to check requested value in the body
 func (a *TreatmentRequest) Bind(r *http.Request) error {
 
-	if a.Name == nil || *a.Name == "" {
-		return errors.New("treatment_name is empty")
-	}
-
-	if a.VisitId == nil || *a.VisitId <= 0 {
-		return errors.New("treatment_visit_id is empty")
-	}
-
-	return nil
+	return validation.New().
+		Field("treatment_name", a.Name, validation.Required(), validation.MaxLength(100)).
+		Field("treatment_visit_id", a.VisitId, validation.Required(), validation.Min(1)).
+		Err()
 }
 
 type TreatmentResponse struct {
-	Id      uint   `json:"id"`
-	Name    string `json:"treatment_name"`
-	VisitId uint   `json:"treatment_visit_id"`
+	Id        uint   `json:"id"`
+	Name      string `json:"treatment_name"`
+	VisitId   uint   `json:"treatment_visit_id"`
+	CreatedBy *uint  `json:"treatment_created_by"`
+	UpdatedBy *uint  `json:"treatment_updated_by"`
 }
 
 type TreatmentHistoryResponse struct {
-	Id   uint   `json:"id"`
-	Name string `json:"treatment_name"`
+	Id        uint   `json:"id"`
+	Name      string `json:"treatment_name"`
+	CreatedBy *uint  `json:"treatment_created_by"`
+	UpdatedBy *uint  `json:"treatment_updated_by"`
 }
@@ -1,14 +1,32 @@
 package model
 
 import (
-	"errors"
 	"net/http"
+	"time"
+	"vet-clinic-api/pkg/validation"
 )
 
 type UserRequest struct {
 	Email    *string `json:"user_email"`
 	Password *string `json:"user_password"`
 	Role     *string `json:"user_role"`
+	OwnerId  *uint   `json:"user_owner_id"`
+}
+
+type UserMeRequest struct {
+	Email    *string `json:"user_email"`
+	Password *string `json:"user_password"`
+
+	// Required to change the email or the password
+	CurrentPassword *string `json:"current_password"`
+}
+
+// Patched user, the password is only changed when given
+type UserPatchRequest struct {
+	Email    *string `json:"user_email"`
+	Password *string `json:"user_password"`
+	Role     *string `json:"user_role"`
+	OwnerId  *uint   `json:"user_owner_id"`
 }
 
 type UserLoginRequest struct {
@@ -19,37 +37,119 @@ type UserLoginRequest struct {
 // Allow to check requested value in the body
 func (a *UserRequest) Bind(r *http.Request) error {
 
-	if a.Email == nil || *a.Email == "" {
-		return errors.New("user_email is empty")
+	v := validation.New().
+		Field("user_email", a.Email, validation.Required(), validation.Email()).
+		Field("user_password", a.Password, validation.Required()).
+		Field("user_role", a.Role, validation.Required())
+
+	// An owner account must be linked to the owner of the animals
+	if a.Role != nil && *a.Role == "owner" {
+		v.Field("user_owner_id", a.OwnerId, validation.Required(), validation.Min(1))
+	} else {
+		a.OwnerId = nil
 	}
 
-	if a.Password == nil || *a.Password == "" {
-		return errors.New("user_password is empty")
+	return v.Err()
+}
+
+// Allow to check the patched user, the password is only changed when given
+func (a *UserPatchRequest) Bind(r *http.Request) error {
+
+	v := validation.New().
+		Field("user_email", a.Email, validation.Required(), validation.Email()).
+		Field("user_role", a.Role, validation.Required())
+
+	if a.Password != nil {
+		v.Field("user_password", a.Password, validation.Required())
 	}
 
-	if a.Role == nil || *a.Role == "" {
-		return errors.New("user_role is empty")
+	// An owner account must be linked to the owner of the animals
+	if a.Role != nil && *a.Role == "owner" {
+		v.Field("user_owner_id", a.OwnerId, validation.Required(), validation.Min(1))
+	} else {
+		a.OwnerId = nil
 	}
 
-	return nil
+	return v.Err()
+}
+
+// Allow to check requested value in the body, the password is only changed when given
+func (a *UserMeRequest) Bind(r *http.Request) error {
+
+	v := validation.New().
+		Field("user_email", a.Email, validation.Required(), validation.Email())
+
+	if a.Password != nil {
+		v.Field("user_password", a.Password, validation.Required())
+	}
+
+	return v.Err()
 }
 
 // Allow to check requested value in the body
 func (a *UserLoginRequest) Bind(r *http.Request) error {
 
-	if a.Email == nil || *a.Email == "" {
-		return errors.New("user_email is empty")
-	}
+	return validation.New().
+		Field("user_email", a.Email, validation.Required()).
+		Field("user_password", a.Password, validation.Required()).
+		Err()
+}
+
+type UserResponse struct {
+	Id          uint       `json:"id"`
+	Email       string     `json:"user_email"`
+	Role        string     `json:"user_role"`
+	OwnerId     *uint      `json:"user_owner_id,omitempty"`
+	MfaEnabled  bool       `json:"user_mfa_enabled"`
+	LockedUntil *time.Time `json:"user_locked_until,omitempty"`
+}
+
+type PasswordResetRequest struct {
+	Email *string `json:"user_email"`
+}
+
+type UserInviteRequest struct {
+	Email   *string `json:"user_email"`
+	Role    *string `json:"user_role"`
+	OwnerId *uint   `json:"user_owner_id"`
+}
+
+// Password set with a one-time token received by email
+type TokenPasswordRequest struct {
+	Token    *string `json:"token"`
+	Password *string `json:"user_password"`
+}
 
-	if a.Password == nil || *a.Password == "" {
-		return errors.New("user_password is empty")
+// Allow to check requested value in the body
+func (a *PasswordResetRequest) Bind(r *http.Request) error {
+
+	return validation.New().
+		Field("user_email", a.Email, validation.Required(), validation.Email()).
+		Err()
+}
+
+// Allow to check requested value in the body
+func (a *UserInviteRequest) Bind(r *http.Request) error {
+
+	v := validation.New().
+		Field("user_email", a.Email, validation.Required(), validation.Email()).
+		Field("user_role", a.Role, validation.Required())
+
+	// An owner account must be linked to the owner of the animals
+	if a.Role != nil && *a.Role == "owner" {
+		v.Field("user_owner_id", a.OwnerId, validation.Required(), validation.Min(1))
+	} else {
+		a.OwnerId = nil
 	}
 
-	return nil
+	return v.Err()
 }
 
-type UserResponse struct {
-	Id    uint   `json:"id"`
-	Email string `json:"user_email"`
-	Role  string `json:"user_role"`
+// Allow to check requested value in the body
+func (a *TokenPasswordRequest) Bind(r *http.Request) error {
+
+	return validation.New().
+		Field("token", a.Token, validation.Required()).
+		Field("user_password", a.Password, validation.Required()).
+		Err()
 }
@@ -1,59 +1,50 @@
 package model
 
 import (
-	"errors"
 	"net/http"
 	"time"
+	"vet-clinic-api/pkg/validation"
 )
 
 type VisitRequest struct {
-	CatId  *uint   `json:"visit_cat_id"`
-	Date   *string `json:"visit_date"`
-	Reason *string `json:"visit_reason"`
-	Vet    *string `json:"visit_vet"`
+	CatId    *uint      `json:"visit_cat_id"`
+	StartsAt *time.Time `json:"visit_starts_at"`
+	EndsAt   *time.Time `json:"visit_ends_at"`
+	Reason   *string    `json:"visit_reason"`
+	Vet      *string    `json:"visit_vet"`
 }
 
-// Allow to check requested value in the body
+// Allow to check requested value in the body, the times are in RFC 3339 with their offset and the end is optional
 func (a *VisitRequest) Bind(r *http.Request) error {
-	layout := "2006-01-02"
 
-	if a.CatId == nil || *a.CatId <= 0 {
-		return errors.New("visit_cat_id must be a positive integer")
-	}
-
-	if a.Date == nil || *a.Date == "" {
-		return errors.New("visit_date is empty")
-	}
-
-	if a.Reason == nil || *a.Reason == "" {
-		return errors.New("visit_reason is empty")
-	}
-
-	if a.Vet == nil || *a.Vet == "" {
-		return errors.New("visit_vet is empty")
-	}
-
-	_, err := time.Parse(layout, *a.Date)
-	if err != nil {
-		return errors.New("visit_date wrong format, expected YYYY-MM-DD")
-	}
-
-	return nil
+	return validation.New().
+		Field("visit_cat_id", a.CatId, validation.Required(), validation.Min(1)).
+		Field("visit_starts_at", a.StartsAt, validation.Required()).
+		Field("visit_ends_at", a.EndsAt, validation.After("visit_starts_at", a.StartsAt)).
+		Field("visit_reason", a.Reason, validation.Required(), validation.MaxLength(500)).
+		Field("visit_vet", a.Vet, validation.Required(), validation.MaxLength(100)).
+		Err()
 }
 
 type VisitResponse struct {
 	Id         uint                 `json:"id"`
 	CatId      uint                 `json:"visit_cat_id"`
-	Date       string               `json:"visit_date"`
+	StartsAt   time.Time            `json:"visit_starts_at"`
+	EndsAt     *time.Time           `json:"visit_ends_at"`
 	Reason     string               `json:"visit_reason"`
 	Vet        string               `json:"visit_vet"`
 	Treatments []*TreatmentResponse `json:"visit_treatments"`
+	CreatedBy  *uint                `json:"visit_created_by"`
+	UpdatedBy  *uint                `json:"visit_updated_by"`
 }
 
 type VisitHistoryResponse struct {
 	Id         uint                        `json:"id"`
-	Date       string                      `json:"visit_date"`
+	StartsAt   time.Time                   `json:"visit_starts_at"`
+	EndsAt     *time.Time                  `json:"visit_ends_at"`
 	Reason     string                      `json:"visit_reason"`
 	Vet        string                      `json:"visit_vet"`
 	Treatments []*TreatmentHistoryResponse `json:"visit_treatments"`
+	CreatedBy  *uint                       `json:"visit_created_by"`
+	UpdatedBy  *uint                       `json:"visit_updated_by"`
 }
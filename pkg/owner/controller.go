@@ -0,0 +1,286 @@
+package owner
+
+import (
+	"net/http"
+	"strconv"
+	"vet-clinic-api/config"
+	"vet-clinic-api/database/dbmodel"
+	"vet-clinic-api/pkg/audit"
+	"vet-clinic-api/pkg/authentication"
+	"vet-clinic-api/pkg/model"
+	"vet-clinic-api/pkg/pagination"
+	"vet-clinic-api/pkg/problem"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// Fields of the response used to sort the owners, with their column
+var sortFields = map[string]string{
+	"id":          "id",
+	"owner_name":  "name",
+	"owner_email": "email",
+}
+
+type OwnerConfig struct {
+	*config.Config
+}
+
+func New(configuration *config.Config) *OwnerConfig {
+	return &OwnerConfig{configuration}
+}
+
+// PostHandler godoc
+// @Summary      Create a new Owner
+// @Description  Creates a new owner entry in the database
+// @Tags         owners
+// @Accept       json
+// @Produce      json
+// @Param        owner  body      model.OwnerRequest  true  "Owner creation payload"
+// @Security     BearerAuth
+// @Success      200    {object}  model.OwnerResponse
+// @Failure      400    {object}  problem.Problem  "Invalid Owner Post request payload"
+// @Failure      422    {object}  problem.Problem  "Validation failed"
+// @Failure      500    {object}  problem.Problem  "Failed to Create specific Owner"
+// @Router       /owners [post]
+func (config *OwnerConfig) PostHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the request
+	req := &model.OwnerRequest{}
+	if err := render.Bind(r, req); err != nil {
+		problem.Bind(w, r, err, "Invalid Owner Post request payload")
+		return
+	}
+
+	// Convert the requested data into dbmodel.OwnerEntry type for the "Create" function
+	ownerEntry := &dbmodel.OwnerEntry{
+		Name:    *req.Name,
+		Phone:   *req.Phone,
+		Email:   *req.Email,
+		Address: *req.Address}
+
+	// Request the DB to Create the informations
+	entries, err := config.OwnerEntryRepository.WithContext(audit.Context(r)).Create(ownerEntry)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Create specific Owner")
+		return
+	}
+
+	// Set up to a dedicated type for the response
+	res := &model.OwnerResponse{
+		Id:      entries.ID,
+		Name:    entries.Name,
+		Phone:   entries.Phone,
+		Email:   entries.Email,
+		Address: entries.Address}
+
+	render.JSON(w, r, res)
+}
+
+// GetAllHandler godoc
+// @Summary      Get all Owners
+// @Description  Find the owners in the database by page, optionally filtered by name and email. The total count is in the X-Total-Count header and the other pages in the Link header.
+// @Tags         owners
+// @Produce      json
+// @Param        name    query     string  false  "Filter by name"
+// @Param        email   query     string  false  "Filter by email"
+// @Param        limit   query     int     false  "Number of owners of the page (default 50, max 200)"
+// @Param        offset  query     int     false  "Number of owners skipped, instead of the cursor"
+// @Param        cursor  query     string  false  "Cursor of the next page, given in the Link header"
+// @Param        sort    query     string  false  "Fields separated by commas, - for descending (id, owner_name, owner_email)"
+// @Security     BearerAuth
+// @Success      200  {array}   model.OwnerResponse
+// @Header       200  {integer}  X-Total-Count  "Number of owners matching the filters"
+// @Header       200  {string}   Link           "Links to the first, previous, next and last pages"
+// @Failure      400  {object}  problem.Problem  "Invalid filter or page"
+// @Failure      500  {object}  problem.Problem  "Failed to retrieve owners"
+// @Router       /owners [get]
+func (config *OwnerConfig) GetAllHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Set up the filters
+	filter := dbmodel.OwnerFilter{Name: r.URL.Query().Get("name"), Email: r.URL.Query().Get("email")}
+
+	// Owners only get their own informations, none without owner id
+	if ownerId, ok := authentication.OwnerIdFromContext(r.Context()); ok {
+		filter.Id = &ownerId
+	}
+
+	page, err := pagination.Parse(r, sortFields)
+	if err != nil {
+		problem.BadRequest(w, r, err.Error())
+		return
+	}
+
+	// Request the DB to get the needed informations
+	entries, info, err := config.OwnerEntryRepository.FindAll(filter, page)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find All Owners")
+		return
+	}
+
+	// Set up to a dedicated type for the response
+	result := []*model.OwnerResponse{}
+	for _, entrie := range entries {
+		result = append(result,
+			&model.OwnerResponse{
+				Id:      entrie.ID,
+				Name:    entrie.Name,
+				Phone:   entrie.Phone,
+				Email:   entrie.Email,
+				Address: entrie.Address})
+	}
+
+	pagination.Write(w, r, page, info)
+	render.JSON(w, r, result)
+}
+
+// GetByIdHandler godoc
+// @Summary      Get owner by ID
+// @Description  Retrieves a specific owner from the database by its ID
+// @Tags         owners
+// @Produce      json
+// @Param        id   path      int  true  "Owner ID"
+// @Security     BearerAuth
+// @Success      200  {object}  model.OwnerResponse
+// @Failure      400  {object}  problem.Problem  "Invalid request"
+// @Failure      403  {object}  problem.Problem  "Forbidden for an owner account"
+// @Failure      404  {object}  problem.Problem  "Owner not found"
+// @Failure      500  {object}  problem.Problem  "Failed to find specific owner"
+// @Router       /owners/{id} [get]
+func (config *OwnerConfig) GetByIdHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the id in the URL
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		problem.NotFound(w, r, "Missing id")
+		return
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		problem.BadRequest(w, r, "id must be an integer")
+		return
+	}
+
+	// Owners can only read their own informations
+	if ownerId, ok := authentication.OwnerIdFromContext(r.Context()); ok && uint(id) != ownerId {
+		problem.Forbidden(w, r, "Owner not linked to the user")
+		return
+	}
+
+	// Request the DB to get the needed informations
+	entries, err := config.OwnerEntryRepository.FindById(id)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find specific Owner")
+		return
+	}
+
+	// Set up to a dedicated type for the response
+	res := &model.OwnerResponse{
+		Id:      entries.ID,
+		Name:    entries.Name,
+		Phone:   entries.Phone,
+		Email:   entries.Email,
+		Address: entries.Address}
+
+	render.JSON(w, r, res)
+}
+
+// UpdateHandler godoc
+// @Summary      Update an owner
+// @Description  Updates an existing owner's information in the database
+// @Tags         owners
+// @Accept       json
+// @Produce      json
+// @Param        id     path      int                 true  "Owner ID"
+// @Param        owner  body      model.OwnerRequest  true  "Owner update payload"
+// @Security     BearerAuth
+// @Success      200    {object}  model.OwnerResponse
+// @Failure      400    {object}  problem.Problem  "Invalid request payload"
+// @Failure      404    {object}  problem.Problem  "Owner not found"
+// @Failure      422    {object}  problem.Problem  "Validation failed"
+// @Failure      500    {object}  problem.Problem  "Failed to update owner"
+// @Router       /owners/{id} [put]
+func (config *OwnerConfig) UpdateHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the id in the URL
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		problem.NotFound(w, r, "Missing id")
+		return
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		problem.BadRequest(w, r, "id must be an integer")
+		return
+	}
+
+	// Get the request
+	req := &model.OwnerRequest{}
+	if err := render.Bind(r, req); err != nil {
+		problem.Bind(w, r, err, "Invalid Owner Update request payload")
+		return
+	}
+
+	// Convert the requested data into dbmodel.OwnerEntry type for the "Update" function
+	ownerEntry := &dbmodel.OwnerEntry{
+		Name:    *req.Name,
+		Phone:   *req.Phone,
+		Email:   *req.Email,
+		Address: *req.Address}
+
+	// Request the DB to Update the informations
+	entries, err := config.OwnerEntryRepository.WithContext(audit.Context(r)).Update(id, ownerEntry)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Update Owner")
+		return
+	}
+
+	// Set up to a dedicated type for the response
+	res := &model.OwnerResponse{
+		Id:      uint(id),
+		Name:    entries.Name,
+		Phone:   entries.Phone,
+		Email:   entries.Email,
+		Address: entries.Address}
+
+	render.JSON(w, r, res)
+}
+
+// DeleteHandler godoc
+// @Summary      Delete an owner
+// @Description  Deletes an owner from the database by its ID, the cats of the owner are kept without owner
+// @Tags         owners
+// @Produce      json
+// @Param        id   path      int  true  "Owner ID"
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]string  "Owner deleted successfully"
+// @Failure      400  {object}  problem.Problem  "Invalid request"
+// @Failure      404  {object}  problem.Problem  "Owner not found"
+// @Failure      500  {object}  problem.Problem  "Failed to delete owner"
+// @Router       /owners/{id} [delete]
+func (config *OwnerConfig) DeleteHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the id in the URL
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		problem.NotFound(w, r, "Missing id")
+		return
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		problem.BadRequest(w, r, "id must be an integer")
+		return
+	}
+
+	// Request the DB to Delete the informations
+	errDelete := config.OwnerEntryRepository.WithContext(audit.Context(r)).DeleteById(id)
+	if errDelete != nil {
+		problem.Error(w, r, errDelete, "Failed to Delete Owner")
+		return
+	}
+
+	render.JSON(w, r, map[string]string{"message": "Owner deleted successfully"})
+}
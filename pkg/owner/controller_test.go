@@ -0,0 +1,116 @@
+package owner
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"vet-clinic-api/config"
+	"vet-clinic-api/database/databasetest"
+	"vet-clinic-api/database/dbmodel"
+	"vet-clinic-api/pkg/authentication"
+)
+
+// The owners are created, read, updated and deleted through the owner routes
+func TestOwnerRoutes(t *testing.T) {
+
+	db := databasetest.Open(t)
+	keys := authentication.NewHMACKeySet("access secret")
+	configuration := &config.Config{
+		OwnerEntryRepository: dbmodel.NewOwnerEntryRepository(db),
+		RoleEntryRepository:  dbmodel.NewRoleEntryRepository(db),
+		AccessTokenKeys:      keys,
+	}
+	router := Routes(configuration)
+
+	signed, err := authentication.GenerateToken(keys, map[string]interface{}{"user_id": 1, "role": "admin"}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each step uses the owner created by the first one
+	var id uint
+	steps := []struct {
+		name   string
+		method string
+		path   func() string
+		body   string
+		status int
+		code   string
+		want   string
+	}{
+		{"create", "POST", func() string { return "/" }, `{"owner_name":"Alice","owner_phone":"0601020304"}`, 200, "", "Alice 0601020304 "},
+		{"create without name", "POST", func() string { return "/" }, `{"owner_phone":"0601020304"}`, 422, "validation_failed", ""},
+		{"create with a wrong email", "POST", func() string { return "/" }, `{"owner_name":"Bob","owner_phone":"0601020304","owner_email":"bob"}`, 422, "validation_failed", ""},
+		{"create with a wrong body", "POST", func() string { return "/" }, `{"owner_name":`, 400, "invalid_request", ""},
+		{"get", "GET", func() string { return fmt.Sprintf("/%d", id) }, "", 200, "", "Alice 0601020304 "},
+		{"update", "PUT", func() string { return fmt.Sprintf("/%d", id) }, `{"owner_name":"Alice Martin","owner_phone":"0601020304","owner_email":"alice@example.com"}`, 200, "", "Alice Martin 0601020304 alice@example.com"},
+		{"get updated", "GET", func() string { return fmt.Sprintf("/%d", id) }, "", 200, "", "Alice Martin 0601020304 alice@example.com"},
+		{"update without phone", "PUT", func() string { return fmt.Sprintf("/%d", id) }, `{"owner_name":"Alice"}`, 422, "validation_failed", ""},
+		{"get with a wrong id", "GET", func() string { return "/abc" }, "", 400, "invalid_request", ""},
+		{"delete", "DELETE", func() string { return fmt.Sprintf("/%d", id) }, "", 200, "", ""},
+		{"get deleted", "GET", func() string { return fmt.Sprintf("/%d", id) }, "", 404, "not_found", ""},
+		{"update deleted", "PUT", func() string { return fmt.Sprintf("/%d", id) }, `{"owner_name":"Alice","owner_phone":"0601020304"}`, 404, "not_found", ""},
+		{"delete deleted", "DELETE", func() string { return fmt.Sprintf("/%d", id) }, "", 404, "not_found", ""},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			r := httptest.NewRequest(step.method, step.path(), strings.NewReader(step.body))
+			r.Header.Set("Authorization", "Bearer "+signed)
+			r.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+
+			if w.Code != step.status {
+				t.Fatalf("got status %d, want %d: %s", w.Code, step.status, w.Body)
+			}
+
+			var res struct {
+				Id      uint   `json:"id"`
+				Name    string `json:"owner_name"`
+				Phone   string `json:"owner_phone"`
+				Email   string `json:"owner_email"`
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+				t.Fatal(err)
+			}
+
+			if res.Code != step.code {
+				t.Errorf("got code %q, want %q", res.Code, step.code)
+			}
+			if step.method == "POST" && step.status == 200 {
+				id = res.Id
+			}
+			if step.want == "" {
+				return
+			}
+			if got := res.Name + " " + res.Phone + " " + res.Email; got != step.want || res.Id != id {
+				t.Errorf("got owner %d %q, want %d %q", res.Id, got, id, step.want)
+			}
+		})
+	}
+
+	// Only the changes made are recorded, with the user of the token
+	t.Run("audit log", func(t *testing.T) {
+		entries, _, err := dbmodel.NewAuditLogEntryRepository(db).FindAll(dbmodel.AuditLogFilter{Entity: "owner", EntityId: id}, dbmodel.Page{Limit: 50})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		actions := []string{}
+		for _, entry := range entries {
+			if entry.ActorId == nil || *entry.ActorId != 1 {
+				t.Errorf("got actor %v, want user 1", entry.ActorId)
+			}
+			actions = append(actions, entry.Action)
+		}
+		if got := fmt.Sprint(actions); got != "[create update delete]" {
+			t.Errorf("got actions %s, want [create update delete]", got)
+		}
+	})
+}
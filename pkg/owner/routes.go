@@ -0,0 +1,35 @@
+package owner
+
+import (
+	"vet-clinic-api/config"
+	"vet-clinic-api/pkg/authentication"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func Routes(configuration *config.Config) chi.Router {
+
+	// Init router
+	ownerConfig := New(configuration)
+	router := chi.NewRouter()
+
+	// Routes protected by authentication
+	router.Group(func(router chi.Router) {
+		router.Use(authentication.AuthMiddleware(ownerConfig.AccessTokenKeys, ownerConfig.RevocationStore, ownerConfig.ApiKeyStore, ownerConfig.RoleEntryRepository))
+
+		// Routes protected by authentication and accessible with the "owners:read" permission
+		router.With(authentication.RequirePermission("owners:read")).Group(func(r chi.Router) {
+			r.Get("/{id}", ownerConfig.GetByIdHandler)
+			r.Get("/", ownerConfig.GetAllHandler)
+		})
+
+		// Routes protected by authentication and accessible with the "owners:write" permission
+		router.With(authentication.RequirePermission("owners:write")).Group(func(r chi.Router) {
+			r.Post("/", ownerConfig.PostHandler)
+			r.Put("/{id}", ownerConfig.UpdateHandler)
+			r.Delete("/{id}", ownerConfig.DeleteHandler)
+		})
+	})
+
+	return router
+}
@@ -0,0 +1,115 @@
+package pagination
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"vet-clinic-api/database/dbmodel"
+)
+
+// Number of records of a page when the limit is not given, and the highest limit
+const (
+	DefaultLimit = 50
+	MaxLimit     = 200
+)
+
+// Parse the page of a list request from the limit, offset, cursor and sort query parameters.
+// The sort is a list of fields of the response separated by commas, a "-" sorts the field in descending order.
+// The fields map the sortable fields of the response to their column.
+func Parse(r *http.Request, fields map[string]string) (dbmodel.Page, error) {
+
+	query := r.URL.Query()
+	page := dbmodel.Page{Limit: DefaultLimit, Cursor: query.Get("cursor")}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > MaxLimit {
+			return page, fmt.Errorf("limit must be an integer between 1 and %d", MaxLimit)
+		}
+		page.Limit = limit
+	}
+
+	if value := query.Get("offset"); value != "" {
+		if page.Cursor != "" {
+			return page, errors.New("offset and cursor can't be used together")
+		}
+
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			return page, errors.New("offset must be a positive integer")
+		}
+		page.Offset = offset
+	}
+
+	if value := query.Get("sort"); value != "" {
+		for _, name := range strings.Split(value, ",") {
+			sort := dbmodel.Sort{}
+			if strings.HasPrefix(name, "-") {
+				sort.Desc = true
+				name = name[1:]
+			}
+
+			column, ok := fields[name]
+			if !ok {
+				return page, errors.New("sort field " + name + " is unknown, expected one of " + strings.Join(slices.Sorted(maps.Keys(fields)), ", "))
+			}
+
+			sort.Column = column
+			page.Sort = append(page.Sort, sort)
+		}
+	}
+
+	return page, nil
+}
+
+// Set the total count of the records in the X-Total-Count header and the links to the other pages in the Link header.
+// The next page is given with a cursor, unless the request uses an offset which also gives the previous and last pages.
+func Write(w http.ResponseWriter, r *http.Request, page dbmodel.Page, info *dbmodel.PageInfo) {
+
+	w.Header().Set("X-Total-Count", strconv.FormatInt(info.Total, 10))
+
+	links := []string{link(r, "first", "offset", "")}
+
+	if !r.URL.Query().Has("offset") {
+		if info.NextCursor != "" {
+			links = append(links, link(r, "next", "cursor", info.NextCursor))
+		}
+	} else {
+		if page.Offset > 0 {
+			links = append(links, link(r, "prev", "offset", strconv.Itoa(max(page.Offset-page.Limit, 0))))
+		}
+
+		if int64(page.Offset+page.Limit) < info.Total {
+			links = append(links, link(r, "next", "offset", strconv.Itoa(page.Offset+page.Limit)))
+		}
+
+		last := 0
+		if info.Total > 0 {
+			last = int((info.Total - 1) / int64(page.Limit) * int64(page.Limit))
+		}
+		links = append(links, link(r, "last", "offset", strconv.Itoa(last)))
+	}
+
+	w.Header().Set("Link", strings.Join(links, ", "))
+}
+
+// Link to the same request with another offset or cursor, the other one is removed
+func link(r *http.Request, rel string, name string, value string) string {
+
+	query := r.URL.Query()
+	query.Del("offset")
+	query.Del("cursor")
+
+	if value != "" {
+		query.Set(name, value)
+	}
+
+	target := url.URL{Path: r.URL.Path, RawQuery: query.Encode()}
+
+	return "<" + target.String() + ">; rel=\"" + rel + "\""
+}
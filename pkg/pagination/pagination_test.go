@@ -0,0 +1,97 @@
+package pagination
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"vet-clinic-api/database/dbmodel"
+)
+
+var fields = map[string]string{"id": "id", "cat_name": "name", "cat_age": "age"}
+
+func TestParse(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+		err   bool
+	}{
+		{"defaults", "", "{50 0  []}", false},
+		{"limit and offset", "limit=10&offset=20", "{10 20  []}", false},
+		{"cursor", "limit=10&cursor=abc", "{10 0 abc []}", false},
+		{"sort", "sort=-cat_age,cat_name", "{50 0  [{age true} {name false}]}", false},
+		{"limit too high", "limit=201", "", true},
+		{"limit zero", "limit=0", "", true},
+		{"limit not a number", "limit=ten", "", true},
+		{"negative offset", "offset=-1", "", true},
+		{"offset with a cursor", "offset=10&cursor=abc", "", true},
+		{"unknown sort field", "sort=cat_color", "", true},
+		{"sort by column name", "sort=name", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := Parse(httptest.NewRequest("GET", "/api/v1/vet/cats?"+tt.query, nil), fields)
+			if tt.err {
+				if err == nil {
+					t.Errorf("got page %v, want an error", page)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := fmt.Sprint(page); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		query string
+		page  dbmodel.Page
+		info  dbmodel.PageInfo
+		links string
+	}{
+		{
+			"next page by cursor", "limit=2&sort=cat_name", dbmodel.Page{Limit: 2}, dbmodel.PageInfo{Total: 5, NextCursor: "abc"},
+			`</api/v1/vet/cats?limit=2&sort=cat_name>; rel="first", </api/v1/vet/cats?cursor=abc&limit=2&sort=cat_name>; rel="next"`,
+		},
+		{
+			"last page by cursor", "limit=2&cursor=abc", dbmodel.Page{Limit: 2, Cursor: "abc"}, dbmodel.PageInfo{Total: 5},
+			`</api/v1/vet/cats?limit=2>; rel="first"`,
+		},
+		{
+			"middle page by offset", "limit=2&offset=2", dbmodel.Page{Limit: 2, Offset: 2}, dbmodel.PageInfo{Total: 5},
+			`</api/v1/vet/cats?limit=2>; rel="first", </api/v1/vet/cats?limit=2&offset=0>; rel="prev", </api/v1/vet/cats?limit=2&offset=4>; rel="next", </api/v1/vet/cats?limit=2&offset=4>; rel="last"`,
+		},
+		{
+			"offset not on a page", "limit=2&offset=1", dbmodel.Page{Limit: 2, Offset: 1}, dbmodel.PageInfo{Total: 4},
+			`</api/v1/vet/cats?limit=2>; rel="first", </api/v1/vet/cats?limit=2&offset=0>; rel="prev", </api/v1/vet/cats?limit=2&offset=3>; rel="next", </api/v1/vet/cats?limit=2&offset=2>; rel="last"`,
+		},
+		{
+			"empty list by offset", "offset=0", dbmodel.Page{Limit: 50}, dbmodel.PageInfo{Total: 0},
+			`</api/v1/vet/cats>; rel="first", </api/v1/vet/cats?offset=0>; rel="last"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			Write(w, httptest.NewRequest("GET", "/api/v1/vet/cats?"+tt.query, nil), tt.page, &tt.info)
+
+			if got := w.Header().Get("X-Total-Count"); got != fmt.Sprint(tt.info.Total) {
+				t.Errorf("got X-Total-Count %s, want %d", got, tt.info.Total)
+			}
+			if got := w.Header().Get("Link"); got != tt.links {
+				t.Errorf("got Link %s, want %s", got, tt.links)
+			}
+		})
+	}
+}
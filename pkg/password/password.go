@@ -0,0 +1,256 @@
+package password
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/subtle"
+	_ "embed"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Hashing algorithms
+const (
+	Argon2id = "argon2id"
+	Bcrypt   = "bcrypt"
+)
+
+// Maximum length, bcrypt ignores the bytes after 72 and argon2id is slowed down by long passwords
+const maxLength = 72
+
+//go:embed common_passwords.txt
+var commonPasswords string
+
+// Settings of the password service, the algorithm is used for the new hashes
+type Params struct {
+	Algorithm string
+
+	// argon2id cost, memory in KiB
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+
+	// bcrypt cost
+	BcryptCost int
+
+	// Strength rules
+	MinLength int
+}
+
+// Hash, verify and check the strength of the passwords
+type Service struct {
+	params    Params
+	wordlist  map[string]struct{}
+	dummyHash string
+}
+
+// Default settings, argon2id with the parameters recommended by the OWASP
+func DefaultParams() Params {
+	return Params{
+		Algorithm:   Argon2id,
+		Memory:      64 * 1024,
+		Iterations:  3,
+		Parallelism: 2,
+		BcryptCost:  12,
+		MinLength:   10,
+	}
+}
+
+// Create the password service, the words of the wordlist file are refused in addition to the common passwords
+func New(params Params, wordlistFile string) (*Service, error) {
+
+	if params.Algorithm != Argon2id && params.Algorithm != Bcrypt {
+		return nil, errors.New("unsupported password hashing algorithm " + params.Algorithm)
+	}
+
+	if params.BcryptCost < bcrypt.MinCost || params.BcryptCost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+
+	if params.Memory == 0 || params.Iterations == 0 || params.Parallelism == 0 {
+		return nil, errors.New("argon2id memory, iterations and parallelism must be positive")
+	}
+
+	if params.MinLength < 1 || params.MinLength > maxLength {
+		return nil, fmt.Errorf("password minimum length must be between 1 and %d", maxLength)
+	}
+
+	service := &Service{params: params, wordlist: map[string]struct{}{}}
+
+	service.addWords(strings.NewReader(commonPasswords))
+
+	if wordlistFile != "" {
+		file, err := os.Open(wordlistFile)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		if err := service.addWords(file); err != nil {
+			return nil, err
+		}
+	}
+
+	// Hash compared when the user is unknown, to answer in the same time as for a wrong password
+	dummyHash, err := service.Hash("dummy password")
+	if err != nil {
+		return nil, err
+	}
+	service.dummyHash = dummyHash
+
+	return service, nil
+}
+
+// Hash a password with the configured algorithm
+func (s *Service) Hash(password string) (string, error) {
+
+	if s.params.Algorithm == Bcrypt {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), s.params.BcryptCost)
+		return string(hash), err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, s.params.Iterations, s.params.Memory, s.params.Parallelism, 32)
+
+	// PHC string format, the parameters are kept with the hash to verify it after a change of settings
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, s.params.Memory, s.params.Iterations, s.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Check a password against an argon2id or bcrypt hash.
+// The second value tells if the hash must be replaced because it doesn't use the current settings.
+func (s *Service) Verify(hash string, password string) (bool, bool) {
+
+	if strings.HasPrefix(hash, "$argon2id$") {
+		params, salt, key, err := decodeArgon2id(hash)
+		if err != nil {
+			return false, false
+		}
+
+		computed := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+		if subtle.ConstantTimeCompare(computed, key) != 1 {
+			return false, false
+		}
+
+		needsRehash := s.params.Algorithm != Argon2id ||
+			params.Memory != s.params.Memory ||
+			params.Iterations != s.params.Iterations ||
+			params.Parallelism != s.params.Parallelism
+
+		return true, needsRehash
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) != nil {
+		return false, false
+	}
+
+	cost, err := bcrypt.Cost([]byte(hash))
+	needsRehash := s.params.Algorithm != Bcrypt || err != nil || cost != s.params.BcryptCost
+
+	return true, needsRehash
+}
+
+// Compare the password with a dummy hash, used when the user doesn't exist
+func (s *Service) VerifyDummy(password string) {
+	s.Verify(s.dummyHash, password)
+}
+
+// Check the strength of a password, it must not be common or contain the given personal values like the email
+func (s *Service) Validate(password string, personal ...string) error {
+
+	length := utf8.RuneCountInString(password)
+	if length < s.params.MinLength {
+		return fmt.Errorf("password must contain at least %d characters", s.params.MinLength)
+	}
+
+	if len(password) > maxLength {
+		return fmt.Errorf("password must not exceed %d bytes", maxLength)
+	}
+
+	lower := strings.ToLower(password)
+	if _, ok := s.wordlist[lower]; ok {
+		return errors.New("password is too common")
+	}
+
+	// Compared by rune, a multi-byte character like "é" repeated is refused too
+	first, _ := utf8.DecodeRuneInString(lower)
+	if strings.Count(lower, string(first)) == utf8.RuneCountInString(lower) {
+		return errors.New("password must not repeat a single character")
+	}
+
+	for _, value := range personal {
+		value = strings.ToLower(strings.TrimSpace(value))
+
+		// For an email the name before the "@" is checked too
+		if name, _, ok := strings.Cut(value, "@"); ok && len(name) >= 4 && strings.Contains(lower, name) {
+			return errors.New("password must not contain the email")
+		}
+
+		if value != "" && strings.Contains(lower, value) {
+			return errors.New("password must not contain personal information")
+		}
+	}
+
+	return nil
+}
+
+// Read a wordlist, one password by line, the empty lines and the lines starting with "#" are ignored
+func (s *Service) addWords(reader io.Reader) error {
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		word := strings.TrimSpace(scanner.Text())
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		s.wordlist[strings.ToLower(word)] = struct{}{}
+	}
+
+	return scanner.Err()
+}
+
+// Read the parameters, salt and key of an argon2id PHC string
+func decodeArgon2id(hash string) (Params, []byte, []byte, error) {
+
+	params := Params{Algorithm: Argon2id}
+
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return params, nil, nil, errors.New("invalid argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, errors.New("unsupported argon2id version")
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, err
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, err
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return params, nil, nil, err
+	}
+
+	return params, salt, key, nil
+}
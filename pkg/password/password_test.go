@@ -0,0 +1,97 @@
+package password
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+
+	params := DefaultParams()
+	params.Iterations, params.Memory = 1, 1024
+	service, err := New(params, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		personal []string
+		valid    bool
+	}{
+		{"strong", "Clinic-Boot-77x", nil, true},
+		{"too short", "Short-1x", nil, false},
+		{"short with multi-byte characters", "ééééé-ààà", nil, false},
+		{"too long", strings.Repeat("Ab1-", 19), nil, false},
+		{"common", "Password123", nil, false},
+		{"repeated character", "aaaaaaaaaaaa", nil, false},
+		{"repeated character whatever its case", "aAaAaAaAaAaA", nil, false},
+		{"repeated multi-byte character", "éééééééééééé", nil, false},
+		{"multi-byte characters not repeated", "éèéèéèéèéèéè", nil, true},
+		{"email name", "My-jdupont-2024", []string{"jdupont@example.com"}, false},
+		{"short email name allowed", "My-jd-password-2024", []string{"jd@example.com"}, true},
+		{"personal value", "Whiskers-Tom-42", []string{" Tom "}, false},
+		{"empty personal value", "Clinic-Boot-77x", []string{""}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.Validate(tt.password, tt.personal...)
+			if (err == nil) != tt.valid {
+				t.Errorf("got %v, want valid %t", err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+
+	argon := DefaultParams()
+	argon.Iterations, argon.Memory = 1, 1024
+	bcryptParams := argon
+	bcryptParams.Algorithm, bcryptParams.BcryptCost = Bcrypt, 4
+
+	argonService, err := New(argon, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bcryptService, err := New(bcryptParams, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	argonHash, err := argonService.Hash("Clinic-Boot-77x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bcryptHash, err := bcryptService.Hash("Clinic-Boot-77x")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		service     *Service
+		hash        string
+		password    string
+		ok          bool
+		needsRehash bool
+	}{
+		{"argon2id", argonService, argonHash, "Clinic-Boot-77x", true, false},
+		{"argon2id wrong password", argonService, argonHash, "Clinic-Boot-78x", false, false},
+		{"bcrypt", bcryptService, bcryptHash, "Clinic-Boot-77x", true, false},
+		{"bcrypt rehashed with argon2id", argonService, bcryptHash, "Clinic-Boot-77x", true, true},
+		{"argon2id rehashed with bcrypt", bcryptService, argonHash, "Clinic-Boot-77x", true, true},
+		{"invalid hash", argonService, "$argon2id$v=19$invalid", "Clinic-Boot-77x", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, needsRehash := tt.service.Verify(tt.hash, tt.password)
+			if ok != tt.ok || needsRehash != tt.needsRehash {
+				t.Errorf("got %t and rehash %t, want %t and rehash %t", ok, needsRehash, tt.ok, tt.needsRehash)
+			}
+		})
+	}
+}
@@ -0,0 +1,308 @@
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Errors of the operations of a JSON Patch
+var (
+	ErrInvalidOperation = errors.New("invalid operation")
+	ErrPathNotFound     = errors.New("path not found")
+	ErrTestFailed       = errors.New("test failed")
+)
+
+// Operation of a JSON Patch (RFC 6902)
+type Operation struct {
+	Op    string           `json:"op"`
+	Path  string           `json:"path"`
+	From  string           `json:"from,omitempty"`
+	Value *json.RawMessage `json:"value,omitempty"`
+}
+
+// Error of an operation, the patch is applied entirely or not at all
+type OperationError struct {
+	Index int
+	Op    string
+	Path  string
+	Err   error
+}
+
+func (e *OperationError) Error() string {
+	return fmt.Sprintf("operation %d (%s %s): %s", e.Index, e.Op, e.Path, e.Err.Error())
+}
+
+func (e *OperationError) Unwrap() error {
+	return e.Err
+}
+
+// Apply the operations in order to the document
+func applyOperations(document interface{}, operations []Operation) (interface{}, error) {
+
+	for i, operation := range operations {
+		var err error
+		if document, err = applyOperation(document, operation); err != nil {
+			return nil, &OperationError{Index: i, Op: operation.Op, Path: operation.Path, Err: err}
+		}
+	}
+
+	return document, nil
+}
+
+func applyOperation(document interface{}, operation Operation) (interface{}, error) {
+
+	path, err := parsePointer(operation.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch operation.Op {
+	case "add", "replace", "test":
+		if operation.Value == nil {
+			return nil, fmt.Errorf("%w: value is missing", ErrInvalidOperation)
+		}
+
+		var value interface{}
+		if err := json.Unmarshal(*operation.Value, &value); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidOperation, err.Error())
+		}
+
+		switch operation.Op {
+		case "add":
+			return add(document, path, value)
+		case "replace":
+			return replace(document, path, value)
+		}
+
+		current, err := get(document, path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !reflect.DeepEqual(current, value) {
+			return nil, ErrTestFailed
+		}
+
+		return document, nil
+
+	case "remove":
+		document, _, err := remove(document, path)
+		return document, err
+
+	case "move", "copy":
+		from, err := parsePointer(operation.From)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := get(document, from)
+		if err != nil {
+			return nil, err
+		}
+
+		if operation.Op == "move" {
+			// A value can't be moved into one of its children
+			if operation.Path != operation.From && strings.HasPrefix(operation.Path, operation.From+"/") {
+				return nil, fmt.Errorf("%w: from is a parent of path", ErrInvalidOperation)
+			}
+
+			if document, _, err = remove(document, from); err != nil {
+				return nil, err
+			}
+		} else {
+			var copied interface{}
+			if err := roundTrip(value, &copied); err != nil {
+				return nil, err
+			}
+			value = copied
+		}
+
+		return add(document, path, value)
+	}
+
+	return nil, fmt.Errorf("%w: unknown op %q", ErrInvalidOperation, operation.Op)
+}
+
+// Tokens of a JSON Pointer (RFC 6901), none for the whole document
+func parsePointer(pointer string) ([]string, error) {
+
+	if pointer == "" {
+		return nil, nil
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%w: path must start with /", ErrInvalidOperation)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+
+	return tokens, nil
+}
+
+// Index of an array element, "-" is the index after the last element when allowed
+func arrayIndex(token string, length int, allowEnd bool) (int, error) {
+
+	if token == "-" && allowEnd {
+		return length, nil
+	}
+
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || index > length || (index == length && !allowEnd) || (token != "0" && strings.HasPrefix(token, "0")) {
+		return 0, ErrPathNotFound
+	}
+
+	return index, nil
+}
+
+func get(document interface{}, path []string) (interface{}, error) {
+
+	for _, token := range path {
+		switch node := document.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, ErrPathNotFound
+			}
+			document = value
+		case []interface{}:
+			index, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			document = node[index]
+		default:
+			return nil, ErrPathNotFound
+		}
+	}
+
+	return document, nil
+}
+
+// Change the parent of the last token of the path, the containers on the way are rebuilt
+func update(document interface{}, path []string, change func(parent interface{}, token string) (interface{}, error)) (interface{}, error) {
+
+	if len(path) == 1 {
+		return change(document, path[0])
+	}
+
+	switch node := document.(type) {
+	case map[string]interface{}:
+		child, ok := node[path[0]]
+		if !ok {
+			return nil, ErrPathNotFound
+		}
+
+		changed, err := update(child, path[1:], change)
+		if err != nil {
+			return nil, err
+		}
+
+		node[path[0]] = changed
+		return node, nil
+
+	case []interface{}:
+		index, err := arrayIndex(path[0], len(node), false)
+		if err != nil {
+			return nil, err
+		}
+
+		changed, err := update(node[index], path[1:], change)
+		if err != nil {
+			return nil, err
+		}
+
+		node[index] = changed
+		return node, nil
+	}
+
+	return nil, ErrPathNotFound
+}
+
+// Add a member to an object, or insert an element in an array
+func add(document interface{}, path []string, value interface{}) (interface{}, error) {
+
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return update(document, path, func(parent interface{}, token string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			node[token] = value
+			return node, nil
+		case []interface{}:
+			index, err := arrayIndex(token, len(node), true)
+			if err != nil {
+				return nil, err
+			}
+			return append(node[:index], append([]interface{}{value}, node[index:]...)...), nil
+		}
+		return nil, ErrPathNotFound
+	})
+}
+
+// Replace an existing value
+func replace(document interface{}, path []string, value interface{}) (interface{}, error) {
+
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return update(document, path, func(parent interface{}, token string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			if _, ok := node[token]; !ok {
+				return nil, ErrPathNotFound
+			}
+			node[token] = value
+			return node, nil
+		case []interface{}:
+			index, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			node[index] = value
+			return node, nil
+		}
+		return nil, ErrPathNotFound
+	})
+}
+
+// Remove an existing value, the removed value is returned
+func remove(document interface{}, path []string) (interface{}, interface{}, error) {
+
+	if len(path) == 0 {
+		return nil, nil, fmt.Errorf("%w: the whole document can't be removed", ErrInvalidOperation)
+	}
+
+	var removed interface{}
+	document, err := update(document, path, func(parent interface{}, token string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, ErrPathNotFound
+			}
+			removed = value
+			delete(node, token)
+			return node, nil
+		case []interface{}:
+			index, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			removed = node[index]
+			return append(node[:index], node[index+1:]...), nil
+		}
+		return nil, ErrPathNotFound
+	})
+
+	return document, removed, err
+}
@@ -0,0 +1,113 @@
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+)
+
+// Media types of the PATCH requests
+const (
+	MergePatch = "application/merge-patch+json"
+	JSONPatch  = "application/json-patch+json"
+)
+
+// Error of a PATCH request sent with another media type
+var ErrUnsupportedMediaType = errors.New("Content-Type must be " + MergePatch + " or " + JSONPatch)
+
+// Apply the patch in the body of the request to the current record, the result is decoded into the target.
+// The current record and the target are request models, the fields which aren't in the model are refused.
+func Apply(r *http.Request, current interface{}, target interface{}) error {
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != MergePatch && mediaType != JSONPatch {
+		return ErrUnsupportedMediaType
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+
+	// The record is patched as a JSON document
+	var document interface{}
+	if err := roundTrip(current, &document); err != nil {
+		return err
+	}
+
+	if mediaType == MergePatch {
+		var mergePatch interface{}
+		if err := json.Unmarshal(body, &mergePatch); err != nil {
+			return err
+		}
+
+		document = merge(document, mergePatch)
+	} else {
+		var operations []Operation
+		if err := json.Unmarshal(body, &operations); err != nil {
+			return err
+		}
+
+		if document, err = applyOperations(document, operations); err != nil {
+			return err
+		}
+	}
+
+	patched, err := json.Marshal(document)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+
+	return decoder.Decode(target)
+}
+
+// Add the column to the changed columns when its value is not the same after the patch
+func Changed(columns map[string]interface{}, column string, before interface{}, after interface{}) {
+
+	if !reflect.DeepEqual(before, after) {
+		columns[column] = after
+	}
+}
+
+// Apply a JSON Merge Patch (RFC 7396), a null removes the member
+func merge(target interface{}, patch interface{}) interface{} {
+
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+
+		targetObject[key] = merge(targetObject[key], value)
+	}
+
+	return targetObject
+}
+
+// Copy a value through its JSON encoding
+func roundTrip(value interface{}, target interface{}) error {
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(encoded, target)
+}
@@ -0,0 +1,128 @@
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type cat struct {
+	Name  *string  `json:"cat_name"`
+	Age   *int     `json:"cat_age"`
+	Notes []string `json:"cat_notes"`
+}
+
+func TestApply(t *testing.T) {
+
+	name, age := "Tom", 3
+	current := &cat{Name: &name, Age: &age, Notes: []string{"calm"}}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+		err         error
+	}{
+		{"merge patch", MergePatch, `{"cat_age":4}`, `{"cat_name":"Tom","cat_age":4,"cat_notes":["calm"]}`, nil},
+		{"merge patch with charset", MergePatch + "; charset=utf-8", `{"cat_name":"Tim"}`, `{"cat_name":"Tim","cat_age":3,"cat_notes":["calm"]}`, nil},
+		{"merge patch removing a field", MergePatch, `{"cat_age":null}`, `{"cat_name":"Tom","cat_age":null,"cat_notes":["calm"]}`, nil},
+		{"merge patch replacing an array", MergePatch, `{"cat_notes":["shy"]}`, `{"cat_name":"Tom","cat_age":3,"cat_notes":["shy"]}`, nil},
+		{"JSON patch", JSONPatch, `[{"op":"replace","path":"/cat_age","value":4},{"op":"add","path":"/cat_notes/-","value":"shy"}]`, `{"cat_name":"Tom","cat_age":4,"cat_notes":["calm","shy"]}`, nil},
+		{"JSON patch with a failed test", JSONPatch, `[{"op":"replace","path":"/cat_age","value":4},{"op":"test","path":"/cat_name","value":"Tim"}]`, "", ErrTestFailed},
+		{"unknown field", MergePatch, `{"cat_color":"black"}`, "", nil},
+		{"JSON content type", "application/json", `{"cat_age":4}`, "", ErrUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("PATCH", "/api/v1/vet/cats/1", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", tt.contentType)
+
+			target := &cat{}
+			err := Apply(r, current, target)
+
+			if tt.want == "" {
+				if err == nil || (tt.err != nil && !errors.Is(err, tt.err)) {
+					t.Fatalf("got %v, want error %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, _ := json.Marshal(target)
+			if string(got) != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if *current.Age != 3 || len(current.Notes) != 1 {
+		t.Errorf("current record changed to %+v", current)
+	}
+}
+
+// Examples of RFC 6902 appendix A, and the errors of the operations
+func TestApplyOperations(t *testing.T) {
+
+	tests := []struct {
+		name       string
+		document   string
+		operations string
+		want       string
+		err        error
+	}{
+		{"add an object member", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`, nil},
+		{"add an array element", `{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`, nil},
+		{"add at the end of an array", `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":"baz"}]`, `{"foo":["bar","baz"]}`, nil},
+		{"remove an object member", `{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`, nil},
+		{"remove an array element", `{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`, nil},
+		{"replace a value", `{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`, nil},
+		{"move a value", `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`, nil},
+		{"move an array element", `{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`, nil},
+		{"copy a value", `{"foo":{"bar":1}}`, `[{"op":"copy","from":"/foo","path":"/baz"}]`, `{"baz":{"bar":1},"foo":{"bar":1}}`, nil},
+		{"test a value", `{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`, `{"baz":"qux","foo":["a",2,"c"]}`, nil},
+		{"escaped keys", `{"a/b":1,"m~n":2}`, `[{"op":"replace","path":"/a~1b","value":3},{"op":"remove","path":"/m~0n"}]`, `{"a/b":3}`, nil},
+		{"failed test", `{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`, "", ErrTestFailed},
+		{"missing member", `{"foo":"bar"}`, `[{"op":"replace","path":"/baz","value":1}]`, "", ErrPathNotFound},
+		{"array index out of range", `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/2","value":"baz"}]`, "", ErrPathNotFound},
+		{"move into its own child", `{"foo":{"bar":1}}`, `[{"op":"move","from":"/foo","path":"/foo/bar"}]`, "", ErrInvalidOperation},
+		{"unknown op", `{"foo":"bar"}`, `[{"op":"rename","path":"/foo"}]`, "", ErrInvalidOperation},
+		{"path without slash", `{"foo":"bar"}`, `[{"op":"remove","path":"foo"}]`, "", ErrInvalidOperation},
+		{"add without value", `{"foo":"bar"}`, `[{"op":"add","path":"/baz"}]`, "", ErrInvalidOperation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var document interface{}
+			var operations []Operation
+			if err := json.Unmarshal([]byte(tt.document), &document); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tt.operations), &operations); err != nil {
+				t.Fatal(err)
+			}
+
+			patched, err := applyOperations(document, operations)
+			if tt.err != nil {
+				var operationError *OperationError
+				if !errors.Is(err, tt.err) || !errors.As(err, &operationError) {
+					t.Fatalf("got %v, want %v in an operation error", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, _ := json.Marshal(patched)
+			if string(got) != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
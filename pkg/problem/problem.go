@@ -0,0 +1,195 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"vet-clinic-api/database/dbmodel"
+	"vet-clinic-api/pkg/patch"
+	"vet-clinic-api/pkg/validation"
+
+	"gorm.io/gorm"
+)
+
+// Stable codes of the errors, given in the "code" member and at the end of the "type" of every problem
+const (
+	CodeInvalidRequest   = "invalid_request"
+	CodeValidationFailed = "validation_failed"
+	CodeInvalidReference = "invalid_reference"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeNotFound         = "not_found"
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodeUnsupportedMedia = "unsupported_media_type"
+	CodeInvalidPatch     = "invalid_patch"
+	CodeAlreadyExists    = "already_exists"
+	CodeConflict         = "conflict"
+	CodeTooManyRequests  = "too_many_requests"
+	CodeInternal         = "internal_error"
+)
+
+// Media type of the error responses
+const ContentType = "application/problem+json"
+
+// Error response following the RFC 7807 "Problem Details for HTTP APIs"
+type Problem struct {
+	Type     string `json:"type" example:"/problems/not_found"`
+	Title    string `json:"title" example:"Not Found"`
+	Status   int    `json:"status" example:"404"`
+	Detail   string `json:"detail,omitempty" example:"Failed to Find specific Cat"`
+	Instance string `json:"instance,omitempty" example:"/api/v1/vet/cats/12"`
+	Code     string `json:"code" example:"not_found"`
+
+	// Every refused field of the request, for the validation problems
+	Errors validation.Errors `json:"errors,omitempty"`
+}
+
+// Build a problem, the title is the text of the status
+func New(status int, code string, detail string) *Problem {
+	return &Problem{
+		Type:   "/problems/" + code,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// Write the problem as the response of the request
+func Write(w http.ResponseWriter, r *http.Request, problem *Problem) {
+
+	problem.Instance = r.URL.Path
+
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(problem.Status)
+
+	if err := json.NewEncoder(w).Encode(problem); err != nil {
+		http.Error(w, problem.Detail, problem.Status)
+	}
+}
+
+// Malformed request, like a body which is not JSON or a wrong query parameter
+func BadRequest(w http.ResponseWriter, r *http.Request, detail string) {
+	Write(w, r, New(http.StatusBadRequest, CodeInvalidRequest, detail))
+}
+
+// Well-formed request with values breaking the rules
+func Validation(w http.ResponseWriter, r *http.Request, detail string) {
+	Write(w, r, New(http.StatusUnprocessableEntity, CodeValidationFailed, detail))
+}
+
+// Well-formed request with fields breaking the rules, every field error is given
+func Fields(w http.ResponseWriter, r *http.Request, detail string, errs validation.Errors) {
+
+	problem := New(http.StatusUnprocessableEntity, CodeValidationFailed, detail)
+	problem.Errors = errs
+
+	Write(w, r, problem)
+}
+
+// Request linking a record which doesn't exist, like the cat of a new visit
+func InvalidReference(w http.ResponseWriter, r *http.Request, detail string) {
+	Write(w, r, New(http.StatusUnprocessableEntity, CodeInvalidReference, detail))
+}
+
+func Unauthorized(w http.ResponseWriter, r *http.Request, detail string) {
+	Write(w, r, New(http.StatusUnauthorized, CodeUnauthorized, detail))
+}
+
+func Forbidden(w http.ResponseWriter, r *http.Request, detail string) {
+	Write(w, r, New(http.StatusForbidden, CodeForbidden, detail))
+}
+
+func NotFound(w http.ResponseWriter, r *http.Request, detail string) {
+	Write(w, r, New(http.StatusNotFound, CodeNotFound, detail))
+}
+
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request, detail string) {
+	Write(w, r, New(http.StatusMethodNotAllowed, CodeMethodNotAllowed, detail))
+}
+
+// Record with the same unique value already in the DB
+func AlreadyExists(w http.ResponseWriter, r *http.Request, detail string) {
+	Write(w, r, New(http.StatusConflict, CodeAlreadyExists, detail))
+}
+
+// Request not possible in the current state of the record
+func Conflict(w http.ResponseWriter, r *http.Request, detail string) {
+	Write(w, r, New(http.StatusConflict, CodeConflict, detail))
+}
+
+// The Retry-After header is set by the caller
+func TooManyRequests(w http.ResponseWriter, r *http.Request, detail string) {
+	Write(w, r, New(http.StatusTooManyRequests, CodeTooManyRequests, detail))
+}
+
+func Internal(w http.ResponseWriter, r *http.Request, detail string) {
+	Write(w, r, New(http.StatusInternalServerError, CodeInternal, detail))
+}
+
+// Write the problem matching an error of a repository, the error itself is not given to the client
+func Error(w http.ResponseWriter, r *http.Request, err error, detail string) {
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		NotFound(w, r, detail)
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		AlreadyExists(w, r, detail)
+	case errors.Is(err, gorm.ErrForeignKeyViolated), errors.Is(err, dbmodel.ErrParentDeleted),
+		errors.Is(err, dbmodel.ErrAppointmentConflict), errors.Is(err, dbmodel.ErrAppointmentClosed):
+		Conflict(w, r, detail)
+	case errors.Is(err, dbmodel.ErrInvalidCursor):
+		BadRequest(w, r, detail+". "+err.Error())
+	default:
+		Internal(w, r, detail)
+	}
+}
+
+// Write the problem matching an error of the lookup of a linked record, 422 when the record doesn't exist
+func Reference(w http.ResponseWriter, r *http.Request, err error, detail string) {
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		InvalidReference(w, r, detail)
+		return
+	}
+
+	Error(w, r, err, detail)
+}
+
+// Write the problem matching an error of render.Bind, 400 when the body can't be decoded and 422 with the field errors when its values are refused
+func Bind(w http.ResponseWriter, r *http.Request, err error, detail string) {
+
+	var syntaxError *json.SyntaxError
+	var typeError *json.UnmarshalTypeError
+	var fieldErrors validation.Errors
+
+	if errors.As(err, &fieldErrors) {
+		Fields(w, r, detail, fieldErrors)
+		return
+	}
+
+	if errors.As(err, &syntaxError) || errors.As(err, &typeError) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		BadRequest(w, r, detail+". "+err.Error())
+		return
+	}
+
+	Validation(w, r, detail+". "+err.Error())
+}
+
+// Write the problem matching an error of patch.Apply, 415 for another media type, 409 for a failed test and 422 for an operation which can't be applied
+func Patch(w http.ResponseWriter, r *http.Request, err error, detail string) {
+
+	var operationError *patch.OperationError
+
+	switch {
+	case errors.Is(err, patch.ErrUnsupportedMediaType):
+		Write(w, r, New(http.StatusUnsupportedMediaType, CodeUnsupportedMedia, err.Error()))
+	case errors.Is(err, patch.ErrTestFailed):
+		Conflict(w, r, detail+". "+err.Error())
+	case errors.As(err, &operationError):
+		Write(w, r, New(http.StatusUnprocessableEntity, CodeInvalidPatch, detail+". "+err.Error()))
+	default:
+		Bind(w, r, err, detail)
+	}
+}
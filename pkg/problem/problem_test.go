@@ -0,0 +1,113 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"vet-clinic-api/database/dbmodel"
+	"vet-clinic-api/pkg/patch"
+	"vet-clinic-api/pkg/validation"
+
+	"gorm.io/gorm"
+)
+
+// Call the writer of a problem and decode the response
+func written(t *testing.T, write func(w http.ResponseWriter, r *http.Request)) *Problem {
+
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	write(w, httptest.NewRequest("GET", "/api/v1/vet/cats/12", nil))
+
+	if got := w.Header().Get("Content-Type"); got != ContentType {
+		t.Errorf("got Content-Type %s, want %s", got, ContentType)
+	}
+
+	problem := &Problem{}
+	if err := json.NewDecoder(w.Body).Decode(problem); err != nil {
+		t.Fatal(err)
+	}
+	if problem.Status != w.Code || problem.Instance != "/api/v1/vet/cats/12" || problem.Type != "/problems/"+problem.Code {
+		t.Errorf("got %+v with status %d", problem, w.Code)
+	}
+
+	return problem
+}
+
+func TestError(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"not found", gorm.ErrRecordNotFound, 404, CodeNotFound},
+		{"duplicated key", gorm.ErrDuplicatedKey, 409, CodeAlreadyExists},
+		{"foreign key", gorm.ErrForeignKeyViolated, 409, CodeConflict},
+		{"parent in the trash", dbmodel.ErrParentDeleted, 409, CodeConflict},
+		{"wrapped appointment conflict", fmt.Errorf("booking: %w", dbmodel.ErrAppointmentConflict), 409, CodeConflict},
+		{"invalid cursor", dbmodel.ErrInvalidCursor, 400, CodeInvalidRequest},
+		{"other error", errors.New("disk full"), 500, CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problem := written(t, func(w http.ResponseWriter, r *http.Request) { Error(w, r, tt.err, "Failed to Find specific Cat") })
+			if problem.Status != tt.status || problem.Code != tt.code {
+				t.Errorf("got %d %s, want %d %s", problem.Status, problem.Code, tt.status, tt.code)
+			}
+		})
+	}
+
+	t.Run("internal error not given", func(t *testing.T) {
+		problem := written(t, func(w http.ResponseWriter, r *http.Request) {
+			Error(w, r, errors.New("disk full"), "Failed to Find specific Cat")
+		})
+		if problem.Detail != "Failed to Find specific Cat" {
+			t.Errorf("got detail %q", problem.Detail)
+		}
+	})
+}
+
+func TestBindAndPatch(t *testing.T) {
+
+	var syntaxError error = &json.SyntaxError{}
+	fieldErrors := validation.New().Field("cat_name", (*string)(nil), validation.Required()).Err()
+
+	tests := []struct {
+		name   string
+		write  func(w http.ResponseWriter, r *http.Request, err error, detail string)
+		err    error
+		status int
+		code   string
+	}{
+		{"bind syntax error", Bind, syntaxError, 400, CodeInvalidRequest},
+		{"bind empty body", Bind, io.EOF, 400, CodeInvalidRequest},
+		{"bind field errors", Bind, fieldErrors, 422, CodeValidationFailed},
+		{"bind other error", Bind, errors.New("cat_id must be positive"), 422, CodeValidationFailed},
+		{"reference not found", Reference, gorm.ErrRecordNotFound, 422, CodeInvalidReference},
+		{"reference other error", Reference, errors.New("disk full"), 500, CodeInternal},
+		{"patch media type", Patch, patch.ErrUnsupportedMediaType, 415, CodeUnsupportedMedia},
+		{"patch failed test", Patch, &patch.OperationError{Op: "test", Path: "/cat_age", Err: patch.ErrTestFailed}, 409, CodeConflict},
+		{"patch invalid operation", Patch, &patch.OperationError{Op: "remove", Path: "/x", Err: patch.ErrPathNotFound}, 422, CodeInvalidPatch},
+		{"patch field errors", Patch, fieldErrors, 422, CodeValidationFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problem := written(t, func(w http.ResponseWriter, r *http.Request) { tt.write(w, r, tt.err, "Invalid Cat request payload") })
+			if problem.Status != tt.status || problem.Code != tt.code {
+				t.Errorf("got %d %s, want %d %s", problem.Status, problem.Code, tt.status, tt.code)
+			}
+			var errs validation.Errors
+			if errors.As(tt.err, &errs) && (len(problem.Errors) != 1 || problem.Errors[0].Path != "cat_name") {
+				t.Errorf("got field errors %+v, want cat_name", problem.Errors)
+			}
+		})
+	}
+}
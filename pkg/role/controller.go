@@ -0,0 +1,374 @@
+package role
+
+import (
+	"net/http"
+	"strconv"
+	"vet-clinic-api/config"
+	"vet-clinic-api/database/dbmodel"
+	"vet-clinic-api/pkg/audit"
+	"vet-clinic-api/pkg/model"
+	"vet-clinic-api/pkg/pagination"
+	"vet-clinic-api/pkg/problem"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// Fields of the response used to sort the roles, with their column
+var sortFields = map[string]string{
+	"id":        "id",
+	"role_name": "name",
+}
+
+type RoleConfig struct {
+	*config.Config
+}
+
+func New(configuration *config.Config) *RoleConfig {
+	return &RoleConfig{configuration}
+}
+
+// PostHandler godoc
+// @Summary      Create a new Role
+// @Description  Creates a new role with its permissions, every permission must exist
+// @Tags         roles
+// @Accept       json
+// @Produce      json
+// @Param        role  body      model.RoleRequest  true  "Role creation payload"
+// @Security     BearerAuth
+// @Success      200   {object}  model.RoleResponse
+// @Failure      400   {object}  problem.Problem  "Invalid Role Post request payload"
+// @Failure      409   {object}  problem.Problem  "A Role already exists with this name"
+// @Failure      422   {object}  problem.Problem  "Validation failed"
+// @Failure      500   {object}  problem.Problem  "Failed to Create specific Role"
+// @Router       /roles [post]
+func (config *RoleConfig) PostHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the request
+	req := &model.RoleRequest{}
+	if err := render.Bind(r, req); err != nil {
+		problem.Bind(w, r, err, "Invalid Role Post request payload")
+		return
+	}
+
+	// Check if the requested permissions existe
+	permissions, missing, err := config.findPermissions(req.Permissions)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find Permissions")
+		return
+	}
+
+	if missing != "" {
+		problem.InvalidReference(w, r, "Permission "+missing+" not found in the DB")
+		return
+	}
+
+	// Convert the requested data into dbmodel.RoleEntry type for the "Create" function
+	roleEntry := &dbmodel.RoleEntry{
+		Name:        *req.Name,
+		Description: *req.Description,
+		Permissions: permissions}
+
+	// Request the DB to Create the informations
+	entries, err := config.RoleEntryRepository.WithContext(audit.Context(r)).Create(roleEntry)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Create specific Role")
+		return
+	}
+
+	render.JSON(w, r, roleResponse(entries))
+}
+
+// GetAllHandler godoc
+// @Summary      Get all Roles
+// @Description  Find the roles with their permissions by page. The total count is in the X-Total-Count header and the other pages in the Link header.
+// @Tags         roles
+// @Produce      json
+// @Param        limit   query     int     false  "Number of roles of the page (default 50, max 200)"
+// @Param        offset  query     int     false  "Number of roles skipped, instead of the cursor"
+// @Param        cursor  query     string  false  "Cursor of the next page, given in the Link header"
+// @Param        sort    query     string  false  "Fields separated by commas, - for descending (id, role_name)"
+// @Security     BearerAuth
+// @Success      200  {array}   model.RoleResponse
+// @Header       200  {integer}  X-Total-Count  "Number of roles"
+// @Header       200  {string}   Link           "Links to the first, previous, next and last pages"
+// @Failure      400  {object}  problem.Problem  "Invalid page"
+// @Failure      500  {object}  problem.Problem  "Failed to retrieve roles"
+// @Router       /roles [get]
+func (config *RoleConfig) GetAllHandler(w http.ResponseWriter, r *http.Request) {
+
+	page, err := pagination.Parse(r, sortFields)
+	if err != nil {
+		problem.BadRequest(w, r, err.Error())
+		return
+	}
+
+	// Request the DB to get the needed informations
+	entries, info, err := config.RoleEntryRepository.FindAll(page)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find All Roles")
+		return
+	}
+
+	// Set up to a dedicated type for the response
+	result := []*model.RoleResponse{}
+	for _, entrie := range entries {
+		result = append(result, roleResponse(entrie))
+	}
+
+	pagination.Write(w, r, page, info)
+	render.JSON(w, r, result)
+}
+
+// GetAllPermissionsHandler godoc
+// @Summary      Get all Permissions
+// @Description  Find all the permissions which can be granted to a role
+// @Tags         roles
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   model.PermissionResponse
+// @Failure      500  {object}  problem.Problem  "Failed to retrieve permissions"
+// @Router       /roles/permissions [get]
+func (config *RoleConfig) GetAllPermissionsHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Request the DB to get the needed informations
+	entries, err := config.PermissionEntryRepository.FindAll()
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find All Permissions")
+		return
+	}
+
+	// Set up to a dedicated type for the response
+	var result []*model.PermissionResponse
+	for _, entrie := range entries {
+		result = append(result,
+			&model.PermissionResponse{
+				Id:          entrie.ID,
+				Name:        entrie.Name,
+				Description: entrie.Description})
+	}
+
+	render.JSON(w, r, result)
+}
+
+// GetByIdHandler godoc
+// @Summary      Get role by ID
+// @Description  Retrieves a specific role with its permissions
+// @Tags         roles
+// @Produce      json
+// @Param        id   path      int  true  "Role ID"
+// @Security     BearerAuth
+// @Success      200  {object}  model.RoleResponse
+// @Failure      400  {object}  problem.Problem  "Invalid request"
+// @Failure      404  {object}  problem.Problem  "Role not found"
+// @Failure      500  {object}  problem.Problem  "Failed to find specific role"
+// @Router       /roles/{id} [get]
+func (config *RoleConfig) GetByIdHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the id in the URL
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		problem.NotFound(w, r, "Missing id")
+		return
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		problem.BadRequest(w, r, "id must be an integer")
+		return
+	}
+
+	// Request the DB to get the needed informations
+	entries, err := config.RoleEntryRepository.FindById(id)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find specific Role")
+		return
+	}
+
+	render.JSON(w, r, roleResponse(entries))
+}
+
+// UpdateHandler godoc
+// @Summary      Update a role
+// @Description  Updates a role and replaces its permissions. The "admin" role can't be changed and the "owner" role can't be renamed.
+// @Tags         roles
+// @Accept       json
+// @Produce      json
+// @Param        id    path      int                true  "Role ID"
+// @Param        role  body      model.RoleRequest  true  "Role update payload"
+// @Security     BearerAuth
+// @Success      200   {object}  model.RoleResponse
+// @Failure      400   {object}  problem.Problem  "Invalid request payload"
+// @Failure      404   {object}  problem.Problem  "Role not found"
+// @Failure      409   {object}  problem.Problem  "Protected role or name already used"
+// @Failure      422   {object}  problem.Problem  "Validation failed"
+// @Failure      500   {object}  problem.Problem  "Failed to update role"
+// @Router       /roles/{id} [put]
+func (config *RoleConfig) UpdateHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the id in the URL
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		problem.NotFound(w, r, "Missing id")
+		return
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		problem.BadRequest(w, r, "id must be an integer")
+		return
+	}
+
+	// Get the request
+	req := &model.RoleRequest{}
+	if err := render.Bind(r, req); err != nil {
+		problem.Bind(w, r, err, "Invalid Role Update request payload")
+		return
+	}
+
+	// Request the DB to get the current role
+	current, err := config.RoleEntryRepository.FindById(id)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find specific Role")
+		return
+	}
+
+	// The admin role always keeps every permission, the owner role name is used to filter the animals
+	if current.Name == "admin" {
+		problem.Conflict(w, r, "The admin role can't be changed")
+		return
+	}
+
+	if current.Name == "owner" && *req.Name != "owner" {
+		problem.Conflict(w, r, "The owner role can't be renamed")
+		return
+	}
+
+	// Check if the requested permissions existe
+	permissions, missing, err := config.findPermissions(req.Permissions)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find Permissions")
+		return
+	}
+
+	if missing != "" {
+		problem.InvalidReference(w, r, "Permission "+missing+" not found in the DB")
+		return
+	}
+
+	// Convert the requested data into dbmodel.RoleEntry type for the "Update" function
+	roleEntry := &dbmodel.RoleEntry{
+		Name:        *req.Name,
+		Description: *req.Description,
+		Permissions: permissions}
+
+	// Request the DB to Update the informations
+	entries, err := config.RoleEntryRepository.WithContext(audit.Context(r)).Update(id, roleEntry)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Update Role")
+		return
+	}
+
+	entries.ID = uint(id)
+
+	render.JSON(w, r, roleResponse(entries))
+}
+
+// DeleteHandler godoc
+// @Summary      Delete a role
+// @Description  Deletes a role which isn't used by any user. The "admin" and "owner" roles can't be deleted.
+// @Tags         roles
+// @Produce      json
+// @Param        id   path      int  true  "Role ID"
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]string  "Role deleted successfully"
+// @Failure      400  {object}  problem.Problem  "Invalid request"
+// @Failure      404  {object}  problem.Problem  "Role not found"
+// @Failure      409  {object}  problem.Problem  "Protected role or role still used by some users"
+// @Failure      500  {object}  problem.Problem  "Failed to delete role"
+// @Router       /roles/{id} [delete]
+func (config *RoleConfig) DeleteHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the id in the URL
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		problem.NotFound(w, r, "Missing id")
+		return
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		problem.BadRequest(w, r, "id must be an integer")
+		return
+	}
+
+	// Request the DB to get the current role
+	current, err := config.RoleEntryRepository.FindById(id)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find specific Role")
+		return
+	}
+
+	if current.Name == "admin" || current.Name == "owner" {
+		problem.Conflict(w, r, "The "+current.Name+" role can't be deleted")
+		return
+	}
+
+	// Check if the role is still used by some users
+	count, err := config.UserEntryRepository.CountByRole(current.Name)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Count the users of the Role")
+		return
+	}
+
+	if count > 0 {
+		problem.Conflict(w, r, "The role is still used by some users")
+		return
+	}
+
+	// Request the DB to Delete the informations
+	errDelete := config.RoleEntryRepository.WithContext(audit.Context(r)).DeleteById(id)
+	if errDelete != nil {
+		problem.Error(w, r, errDelete, "Failed to Delete Role")
+		return
+	}
+
+	render.JSON(w, r, map[string]string{"message": "Role deleted successfully"})
+}
+
+// Find the permissions by name, the first requested permission which doesn't existe is returned
+func (config *RoleConfig) findPermissions(names []string) ([]dbmodel.PermissionEntry, string, error) {
+
+	permissions, err := config.PermissionEntryRepository.FindByNames(names)
+	if err != nil {
+		return nil, "", err
+	}
+
+	found := map[string]bool{}
+	for _, permission := range permissions {
+		found[permission.Name] = true
+	}
+
+	for _, name := range names {
+		if !found[name] {
+			return nil, name, nil
+		}
+	}
+
+	return permissions, "", nil
+}
+
+// Set up a role to a dedicated type for the responses
+func roleResponse(entry *dbmodel.RoleEntry) *model.RoleResponse {
+
+	permissions := []string{}
+	for _, permission := range entry.Permissions {
+		permissions = append(permissions, permission.Name)
+	}
+
+	return &model.RoleResponse{
+		Id:          entry.ID,
+		Name:        entry.Name,
+		Description: entry.Description,
+		Permissions: permissions}
+}
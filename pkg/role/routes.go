@@ -0,0 +1,36 @@
+package role
+
+import (
+	"vet-clinic-api/config"
+	"vet-clinic-api/pkg/authentication"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func Routes(configuration *config.Config) chi.Router {
+
+	// Init router
+	roleConfig := New(configuration)
+	router := chi.NewRouter()
+
+	// Routes protected by authentication
+	router.Group(func(router chi.Router) {
+		router.Use(authentication.AuthMiddleware(roleConfig.AccessTokenKeys, roleConfig.RevocationStore, roleConfig.ApiKeyStore, roleConfig.RoleEntryRepository))
+
+		// Routes protected by authentication and accessible with the "roles:read" permission
+		router.With(authentication.RequirePermission("roles:read")).Group(func(r chi.Router) {
+			r.Get("/permissions", roleConfig.GetAllPermissionsHandler)
+			r.Get("/{id}", roleConfig.GetByIdHandler)
+			r.Get("/", roleConfig.GetAllHandler)
+		})
+
+		// Routes protected by authentication and accessible with the "roles:write" permission
+		router.With(authentication.RequirePermission("roles:write")).Group(func(r chi.Router) {
+			r.Post("/", roleConfig.PostHandler)
+			r.Put("/{id}", roleConfig.UpdateHandler)
+			r.Delete("/{id}", roleConfig.DeleteHandler)
+		})
+	})
+
+	return router
+}
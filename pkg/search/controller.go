@@ -0,0 +1,142 @@
+package search
+
+import (
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+	"vet-clinic-api/config"
+	"vet-clinic-api/database/dbmodel"
+	"vet-clinic-api/pkg/authentication"
+	"vet-clinic-api/pkg/model"
+	"vet-clinic-api/pkg/problem"
+
+	"github.com/go-chi/render"
+)
+
+// Number of results when the limit is not given, and the highest limit
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// Highest number of words of a search
+const MaxTerms = 10
+
+// Searched entities, with the permission needed to read them
+var entityPermissions = []struct {
+	entity     string
+	permission string
+}{
+	{dbmodel.SearchCat, "cats:read"},
+	{dbmodel.SearchVisit, "visits:read"},
+	{dbmodel.SearchTreatment, "treatments:read"},
+}
+
+// Replace the markers of the matched terms once the text is escaped
+var highlighter = strings.NewReplacer(dbmodel.HighlightStart, "<mark>", dbmodel.HighlightEnd, "</mark>")
+
+type SearchConfig struct {
+	*config.Config
+}
+
+func New(configuration *config.Config) *SearchConfig {
+	return &SearchConfig{configuration}
+}
+
+// SearchHandler godoc
+// @Summary      Search the cats, visits and treatments
+// @Description  Finds the cats by name and breed, the visits by reason and vet and the treatments by name. A record matches when it contains one of the words, the records containing more words are ranked first. The words are matched as prefixes and stemmed with FTS5 ("limping" matches "limp"), or searched with LIKE when SQLite is built without FTS5. Only the entities readable with the permissions of the user are searched, owners only get the records of their own cats.
+// @Tags         search
+// @Produce      json
+// @Param        q       query     string  true   "Words to search, like: limping siamese amoxicillin"
+// @Param        entity  query     string  false  "Entities to search separated by commas (cat, visit, treatment)"
+// @Param        limit   query     int     false  "Number of results (default 20, max 100)"
+// @Security     BearerAuth
+// @Success      200  {array}   model.SearchResultResponse
+// @Failure      400  {object}  problem.Problem  "Invalid search"
+// @Failure      403  {object}  problem.Problem  "No searchable entity readable by the user"
+// @Failure      500  {object}  problem.Problem  "Failed to search"
+// @Router       /search [get]
+func (config *SearchConfig) SearchHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Split the search in words, the other characters are ignored
+	terms := strings.FieldsFunc(r.URL.Query().Get("q"), func(c rune) bool {
+		return !unicode.IsLetter(c) && !unicode.IsNumber(c)
+	})
+	if len(terms) == 0 {
+		problem.BadRequest(w, r, "q must contain at least one word")
+		return
+	}
+	if len(terms) > MaxTerms {
+		problem.BadRequest(w, r, "q must contain at most "+strconv.Itoa(MaxTerms)+" words")
+		return
+	}
+
+	query := dbmodel.SearchQuery{Terms: terms, Limit: DefaultLimit}
+
+	if value := r.URL.Query().Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > MaxLimit {
+			problem.BadRequest(w, r, "limit must be an integer between 1 and "+strconv.Itoa(MaxLimit))
+			return
+		}
+		query.Limit = limit
+	}
+
+	// Check the requested entities
+	requested := map[string]bool{}
+	if value := r.URL.Query().Get("entity"); value != "" {
+		for _, entity := range strings.Split(value, ",") {
+			if entity != dbmodel.SearchCat && entity != dbmodel.SearchVisit && entity != dbmodel.SearchTreatment {
+				problem.BadRequest(w, r, "entity must be cat, visit or treatment")
+				return
+			}
+			requested[entity] = true
+		}
+	}
+
+	// Only the entities readable by the user are searched
+	principal, _ := authentication.FromContext(r.Context())
+	for _, item := range entityPermissions {
+		if (len(requested) == 0 || requested[item.entity]) && principal.HasPermission(item.permission) {
+			query.Types = append(query.Types, item.entity)
+		}
+	}
+
+	if len(query.Types) == 0 {
+		problem.Forbidden(w, r, "Permission cats:read, visits:read or treatments:read required")
+		return
+	}
+
+	// Owners only get the records of their own cats, none without owner id
+	if ownerId, ok := authentication.OwnerIdFromContext(r.Context()); ok {
+		query.OwnerId = &ownerId
+	}
+
+	// Request the DB to search the records
+	entries, err := config.SearchEntryRepository.Search(query)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Search")
+		return
+	}
+
+	// Set up to a dedicated type for the response, the fields are named like in the responses of the entities
+	res := []*model.SearchResultResponse{}
+	for _, entrie := range entries {
+		highlights := map[string]string{}
+		for column, text := range entrie.Highlights {
+			highlights[entrie.Type+"_"+column] = highlighter.Replace(html.EscapeString(text))
+		}
+
+		res = append(res, &model.SearchResultResponse{
+			Id:         entrie.Id,
+			Entity:     entrie.Type,
+			CatId:      entrie.CatId,
+			Rank:       entrie.Rank,
+			Highlights: highlights})
+	}
+
+	render.JSON(w, r, res)
+}
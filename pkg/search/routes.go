@@ -0,0 +1,24 @@
+package search
+
+import (
+	"vet-clinic-api/config"
+	"vet-clinic-api/pkg/authentication"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func Routes(configuration *config.Config) chi.Router {
+
+	// Init router
+	searchConfig := New(configuration)
+	router := chi.NewRouter()
+
+	// Routes protected by authentication, the results are limited to the entities the user can read
+	router.Group(func(router chi.Router) {
+		router.Use(authentication.AuthMiddleware(searchConfig.AccessTokenKeys, searchConfig.RevocationStore, searchConfig.ApiKeyStore, searchConfig.RoleEntryRepository))
+
+		router.Get("/", searchConfig.SearchHandler)
+	})
+
+	return router
+}
@@ -0,0 +1,127 @@
+package trash
+
+import (
+	"net/http"
+	"time"
+	"vet-clinic-api/config"
+	"vet-clinic-api/pkg/audit"
+	"vet-clinic-api/pkg/model"
+	"vet-clinic-api/pkg/pagination"
+	"vet-clinic-api/pkg/problem"
+
+	"github.com/go-chi/render"
+)
+
+// Fields of the response used to sort the trash, with their column
+var sortFields = map[string]string{
+	"trash_deleted_at": "deleted_at",
+	"trash_entity":     "entity",
+	"trash_name":       "name",
+}
+
+type TrashConfig struct {
+	*config.Config
+}
+
+func New(configuration *config.Config) *TrashConfig {
+	return &TrashConfig{configuration}
+}
+
+// GetAllHandler godoc
+// @Summary      Get the trash
+// @Description  Find the deleted cats, visits and treatments by page, last deleted first. They can be restored with POST /{entity}/{id}/restore until they are purged. The total count is in the X-Total-Count header and the other pages in the Link header.
+// @Tags         trash
+// @Produce      json
+// @Param        entity  query     string  false  "Filter by entity (cat, visit, treatment)"
+// @Param        limit   query     int     false  "Number of records of the page (default 50, max 200)"
+// @Param        offset  query     int     false  "Number of records skipped, instead of the cursor"
+// @Param        cursor  query     string  false  "Cursor of the next page, given in the Link header"
+// @Param        sort    query     string  false  "Fields separated by commas, - for descending (trash_deleted_at, trash_entity, trash_name)"
+// @Security     BearerAuth
+// @Success      200  {array}   model.TrashEntryResponse
+// @Header       200  {integer}  X-Total-Count  "Number of records matching the filter"
+// @Header       200  {string}   Link           "Links to the first, previous, next and last pages"
+// @Failure      400  {object}  problem.Problem  "Invalid entity or page"
+// @Failure      500  {object}  problem.Problem  "Failed to retrieve the trash"
+// @Router       /trash [get]
+func (config *TrashConfig) GetAllHandler(w http.ResponseWriter, r *http.Request) {
+
+	entity := r.URL.Query().Get("entity")
+	if entity != "" && entity != "cat" && entity != "visit" && entity != "treatment" {
+		problem.BadRequest(w, r, "entity must be cat, visit or treatment")
+		return
+	}
+
+	page, err := pagination.Parse(r, sortFields)
+	if err != nil {
+		problem.BadRequest(w, r, err.Error())
+		return
+	}
+
+	// Request the DB to get the deleted records of every requested entity
+	entries, info, err := config.TrashEntryRepository.FindAll(entity, page)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find the trash")
+		return
+	}
+
+	// Set up to a dedicated type for the response, the last user who updated the record is the one who deleted it
+	res := []*model.TrashEntryResponse{}
+	for _, entrie := range entries {
+
+		// A visit is named by its start in the timezone of the clinic and its reason
+		name := entrie.Name
+		if entrie.Entity == "visit" {
+			name = entrie.StartsAt.In(config.ClinicLocation).Format("2006-01-02 15:04") + " " + entrie.Name
+		}
+
+		res = append(res, &model.TrashEntryResponse{
+			Entity:    entrie.Entity,
+			EntityId:  entrie.EntityId,
+			Name:      name,
+			ParentId:  entrie.ParentId,
+			DeletedAt: entrie.DeletedAt,
+			DeletedBy: entrie.UpdatedBy,
+			PurgeAt:   entrie.DeletedAt.Add(config.TrashRetention),
+		})
+	}
+
+	pagination.Write(w, r, page, info)
+	render.JSON(w, r, res)
+}
+
+// PurgeHandler godoc
+// @Summary      Purge the trash
+// @Description  Permanently deletes the cats, visits and treatments in the trash for longer than TRASH_RETENTION_DAYS, a purged cat takes its visits and treatments with it. The revisions and the audit log are kept.
+// @Tags         trash
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  model.TrashPurgeResponse
+// @Failure      500  {object}  problem.Problem  "Failed to purge the trash"
+// @Router       /trash/purge [post]
+func (config *TrashConfig) PurgeHandler(w http.ResponseWriter, r *http.Request) {
+
+	before := time.Now().Add(-config.TrashRetention)
+	ctx := audit.Context(r)
+
+	// The cats are purged first, with their visits and treatments
+	catIds, err := config.CatEntryRepository.WithContext(ctx).PurgeDeleted(before)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Purge Cats")
+		return
+	}
+
+	visitIds, err := config.VisitEntryRepository.WithContext(ctx).PurgeDeleted(before)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Purge Visits")
+		return
+	}
+
+	treatmentIds, err := config.TreatmentEntryRepository.WithContext(ctx).PurgeDeleted(before)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Purge Treatments")
+		return
+	}
+
+	render.JSON(w, r, &model.TrashPurgeResponse{Cats: len(catIds), Visits: len(visitIds), Treatments: len(treatmentIds)})
+}
@@ -0,0 +1,32 @@
+package trash
+
+import (
+	"vet-clinic-api/config"
+	"vet-clinic-api/pkg/authentication"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func Routes(configuration *config.Config) chi.Router {
+
+	// Init router
+	trashConfig := New(configuration)
+	router := chi.NewRouter()
+
+	// Routes protected by authentication
+	router.Group(func(router chi.Router) {
+		router.Use(authentication.AuthMiddleware(trashConfig.AccessTokenKeys, trashConfig.RevocationStore, trashConfig.ApiKeyStore, trashConfig.RoleEntryRepository))
+
+		// Routes protected by authentication and accessible with the "trash:read" permission
+		router.With(authentication.RequirePermission("trash:read")).Group(func(r chi.Router) {
+			r.Get("/", trashConfig.GetAllHandler)
+		})
+
+		// Routes protected by authentication and accessible with the "trash:purge" permission
+		router.With(authentication.RequirePermission("trash:purge")).Group(func(r chi.Router) {
+			r.Post("/purge", trashConfig.PurgeHandler)
+		})
+	})
+
+	return router
+}
@@ -1,17 +1,29 @@
 package treatment
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
 	"strconv"
 	"vet-clinic-api/config"
 	"vet-clinic-api/database/dbmodel"
+	"vet-clinic-api/pkg/audit"
+	"vet-clinic-api/pkg/authentication"
 	"vet-clinic-api/pkg/model"
+	"vet-clinic-api/pkg/pagination"
+	"vet-clinic-api/pkg/patch"
+	"vet-clinic-api/pkg/problem"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
 )
 
+// Fields of the response used to sort the treatments, with their column
+var sortFields = map[string]string{
+	"id":                 "id",
+	"treatment_name":     "name",
+	"treatment_visit_id": "visit_id",
+}
+
 type TreatmentConfig struct {
 	*config.Config
 }
@@ -29,21 +41,22 @@ func New(configuration *config.Config) *TreatmentConfig {
 // @Param        treatment  body      model.TreatmentRequest  true  "Treatment creation payload"
 // @Security     BearerAuth
 // @Success      200        {object}  model.TreatmentResponse
-// @Failure      400        {object}  map[string]string  "Invalid request payload"
-// @Failure      500        {object}  map[string]string  "Failed to create treatment"
+// @Failure      400        {object}  problem.Problem  "Invalid request payload"
+// @Failure      422        {object}  problem.Problem  "Validation failed"
+// @Failure      500        {object}  problem.Problem  "Failed to create treatment"
 // @Router       /treatments [post]
 func (config *TreatmentConfig) PostHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Get the request
 	req := &model.TreatmentRequest{}
 	if err := render.Bind(r, req); err != nil {
-		render.JSON(w, r, map[string]string{"error": "Invalid Treatment Post request payload. " + err.Error()})
+		problem.Bind(w, r, err, "Invalid Treatment Post request payload")
 		return
 	}
 
 	// Check if the linked cat id existe
 	if !config.VisitEntryRepository.FindLastVisitId(int(*req.VisitId)) {
-		render.JSON(w, r, map[string]string{"error": "VisitId not found in the DB"})
+		problem.InvalidReference(w, r, "VisitId not found in the DB")
 		return
 	}
 
@@ -51,42 +64,75 @@ func (config *TreatmentConfig) PostHandler(w http.ResponseWriter, r *http.Reques
 	treatmentEntry := &dbmodel.TreatmentEntry{Name: *req.Name, VisitId: uint(*req.VisitId)}
 
 	// Request the DB to Create the informations
-	entries, err := config.TreatmentEntryRepository.Create(treatmentEntry)
+	entries, err := config.TreatmentEntryRepository.WithContext(audit.Context(r)).Create(treatmentEntry)
 	if err != nil {
-		render.JSON(w, r, map[string]string{"error": "Failed to Create Treatment"})
+		problem.Error(w, r, err, "Failed to Create Treatment")
 		return
 	}
 
 	// Set up to a dedicated type for the response
-	res := &model.TreatmentResponse{Id: entries.ID, Name: entries.Name, VisitId: entries.VisitId}
+	res := treatmentResponse(entries)
 
 	render.JSON(w, r, res)
 }
 
 // GetAllHandler godoc
 // @Summary      Get all treatments
-// @Description  Retrieves a list of all treatments from the database
+// @Description  Retrieves the treatments from the database by page, optionally filtered by name and visit. The total count is in the X-Total-Count header and the other pages in the Link header.
 // @Tags         treatments
 // @Produce      json
+// @Param        name      query     string  false  "Filter by name"
+// @Param        visit_id  query     int     false  "Filter by visit id"
+// @Param        limit     query     int     false  "Number of treatments of the page (default 50, max 200)"
+// @Param        offset    query     int     false  "Number of treatments skipped, instead of the cursor"
+// @Param        cursor    query     string  false  "Cursor of the next page, given in the Link header"
+// @Param        sort      query     string  false  "Fields separated by commas, - for descending (id, treatment_name, treatment_visit_id)"
 // @Security     BearerAuth
 // @Success      200  {array}   model.TreatmentResponse
-// @Failure      500  {object}  map[string]string  "Failed to retrieve treatments"
+// @Header       200  {integer}  X-Total-Count  "Number of treatments matching the filters"
+// @Header       200  {string}   Link           "Links to the first, previous, next and last pages"
+// @Failure      400  {object}  problem.Problem  "Invalid filter or page"
+// @Failure      500  {object}  problem.Problem  "Failed to retrieve treatments"
 // @Router       /treatments [get]
 func (config *TreatmentConfig) GetAllHandler(w http.ResponseWriter, r *http.Request) {
 
+	// Set up the filters
+	filter := dbmodel.TreatmentFilter{Name: r.URL.Query().Get("name")}
+
+	if value := r.URL.Query().Get("visit_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			problem.BadRequest(w, r, "visit_id must be a positive integer")
+			return
+		}
+		filter.VisitId = uint(id)
+	}
+
+	// Owners only get the treatments of their own cats, none without owner id
+	if ownerId, ok := authentication.OwnerIdFromContext(r.Context()); ok {
+		filter.OwnerId = &ownerId
+	}
+
+	page, err := pagination.Parse(r, sortFields)
+	if err != nil {
+		problem.BadRequest(w, r, err.Error())
+		return
+	}
+
 	// Request the DB to Get the needed informations
-	entries, err := config.TreatmentEntryRepository.FindAll()
+	entries, info, err := config.TreatmentEntryRepository.FindAll(filter, page)
 	if err != nil {
-		render.JSON(w, r, map[string]string{"error": "Failed to Find All treatments"})
+		problem.Error(w, r, err, "Failed to Find All treatments")
 		return
 	}
 
 	// Set up to a dedicated type for the response
-	var result []*model.TreatmentResponse
+	result := []*model.TreatmentResponse{}
 	for _, entrie := range entries {
-		result = append(result, &model.TreatmentResponse{Id: entrie.ID, Name: entrie.Name})
+		result = append(result, &model.TreatmentResponse{Id: entrie.ID, Name: entrie.Name, VisitId: entrie.VisitId, CreatedBy: entrie.CreatedBy, UpdatedBy: entrie.UpdatedBy})
 	}
 
+	pagination.Write(w, r, page, info)
 	render.JSON(w, r, result)
 }
 
@@ -98,32 +144,41 @@ func (config *TreatmentConfig) GetAllHandler(w http.ResponseWriter, r *http.Requ
 // @Param        id   path      int  true  "Treatment ID"
 // @Security     BearerAuth
 // @Success      200  {object}  model.TreatmentResponse
-// @Failure      404  {object}  map[string]string  "Treatment not found"
-// @Failure      500  {object}  map[string]string  "Failed to find specific treatment"
+// @Failure      400  {object}  problem.Problem  "Invalid request"
+// @Failure      403  {object}  problem.Problem  "Forbidden for an owner account"
+// @Failure      404  {object}  problem.Problem  "Treatment not found"
+// @Failure      500  {object}  problem.Problem  "Failed to find specific treatment"
 // @Router       /treatments/{id} [get]
 func (config *TreatmentConfig) GetByIdHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Get the id in the URL
 	idStr := chi.URLParam(r, "id")
 	if idStr == "" {
-		w.WriteHeader(http.StatusNotFound)
+		problem.NotFound(w, r, "Missing id")
 		return
 	}
 
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		fmt.Println("Error during id convertion")
+		problem.BadRequest(w, r, "id must be an integer")
+		return
 	}
 
 	// Request the DB to Get the needed informations
 	entries, err := config.TreatmentEntryRepository.FindById(id)
 	if err != nil {
-		render.JSON(w, r, map[string]string{"error": "Failed to Find specific Treatment"})
+		problem.Error(w, r, err, "Failed to Find specific Treatment")
+		return
+	}
+
+	// Owners can only read the treatments of their own cats
+	if !config.isVisitReadable(r, int(entries.VisitId)) {
+		problem.Forbidden(w, r, "Treatment not owned by the user")
 		return
 	}
 
 	// Set up to dedicated type for the response
-	res := &model.TreatmentResponse{Id: entries.ID, Name: entries.Name, VisitId: entries.VisitId}
+	res := treatmentResponse(entries)
 
 	render.JSON(w, r, res)
 }
@@ -136,34 +191,43 @@ func (config *TreatmentConfig) GetByIdHandler(w http.ResponseWriter, r *http.Req
 // @Param        id   path      int  true  "Visit ID"
 // @Security     BearerAuth
 // @Success      200  {array}   model.TreatmentHistoryResponse
-// @Failure      404  {object}  map[string]string  "Visit not found"
-// @Failure      500  {object}  map[string]string  "Failed to find treatments for visit"
+// @Failure      400  {object}  problem.Problem  "Invalid request"
+// @Failure      403  {object}  problem.Problem  "Forbidden for an owner account"
+// @Failure      404  {object}  problem.Problem  "Visit not found"
+// @Failure      500  {object}  problem.Problem  "Failed to find treatments for visit"
 // @Router       /treatments/{id}/history [get]
 func (config *TreatmentConfig) GetByVisitIdHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Get the id in the URL
 	idStr := chi.URLParam(r, "id")
 	if idStr == "" {
-		w.WriteHeader(http.StatusNotFound)
+		problem.NotFound(w, r, "Missing id")
 		return
 	}
 
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		fmt.Println("Error during id convertion")
+		problem.BadRequest(w, r, "id must be an integer")
+		return
+	}
+
+	// Owners can only read the treatments of their own cats
+	if !config.isVisitReadable(r, id) {
+		problem.Forbidden(w, r, "Visit not owned by the user")
+		return
 	}
 
 	// Request the DB to Get the needed informations
 	entries, err := config.TreatmentEntryRepository.FindByVisitId(id)
 	if err != nil {
-		render.JSON(w, r, map[string]string{"error": "Failed to Find All treatments for a specific visit"})
+		problem.Error(w, r, err, "Failed to Find All treatments for a specific visit")
 		return
 	}
 
 	// Set up to a dedicated type for the response
 	var result []*model.TreatmentHistoryResponse
 	for _, entrie := range entries {
-		res := &model.TreatmentHistoryResponse{Id: entrie.ID, Name: entrie.Name}
+		res := &model.TreatmentHistoryResponse{Id: entrie.ID, Name: entrie.Name, CreatedBy: entrie.CreatedBy, UpdatedBy: entrie.UpdatedBy}
 		result = append(result, res)
 	}
 
@@ -180,34 +244,36 @@ func (config *TreatmentConfig) GetByVisitIdHandler(w http.ResponseWriter, r *htt
 // @Param        treatment  body      model.TreatmentRequest  true  "Treatment update payload"
 // @Security     BearerAuth
 // @Success      200        {object}  model.TreatmentResponse
-// @Failure      400        {object}  map[string]string  "Invalid request payload"
-// @Failure      404        {object}  map[string]string  "Treatment not found"
-// @Failure      500        {object}  map[string]string  "Failed to update treatment"
+// @Failure      400        {object}  problem.Problem  "Invalid request payload"
+// @Failure      404        {object}  problem.Problem  "Treatment not found"
+// @Failure      422        {object}  problem.Problem  "Validation failed"
+// @Failure      500        {object}  problem.Problem  "Failed to update treatment"
 // @Router       /treatments/{id} [put]
 func (config *TreatmentConfig) UpdateHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Get the id in the URL
 	idStr := chi.URLParam(r, "id")
 	if idStr == "" {
-		w.WriteHeader(http.StatusNotFound)
+		problem.NotFound(w, r, "Missing id")
 		return
 	}
 
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		fmt.Println("Error during id convertion")
+		problem.BadRequest(w, r, "id must be an integer")
+		return
 	}
 
 	// Get the request
 	req := &model.TreatmentRequest{}
 	if err := render.Bind(r, req); err != nil {
-		render.JSON(w, r, map[string]string{"error": "Invalid Treatment Update request payload. " + err.Error()})
+		problem.Bind(w, r, err, "Invalid Treatment Update request payload")
 		return
 	}
 
 	// Check if the linked cat id existe
 	if !config.VisitEntryRepository.FindLastVisitId(int(*req.VisitId)) {
-		render.JSON(w, r, map[string]string{"error": "VisitId not found in the DB"})
+		problem.InvalidReference(w, r, "VisitId not found in the DB")
 		return
 	}
 
@@ -215,14 +281,106 @@ func (config *TreatmentConfig) UpdateHandler(w http.ResponseWriter, r *http.Requ
 	treatmentEntry := &dbmodel.TreatmentEntry{Name: *req.Name, VisitId: *req.VisitId}
 
 	// Request the DB to Update the informations
-	entries, err := config.TreatmentEntryRepository.Update(id, treatmentEntry)
+	if _, err := config.TreatmentEntryRepository.WithContext(audit.Context(r)).Update(id, treatmentEntry); err != nil {
+		problem.Error(w, r, err, "Failed to Update Treatment")
+		return
+	}
+
+	// Request the DB to get the updated treatment
+	entries, err := config.TreatmentEntryRepository.FindById(id)
 	if err != nil {
-		render.JSON(w, r, map[string]string{"error": "Failed to Update Treatment"})
+		problem.Error(w, r, err, "Failed to Find updated Treatment")
 		return
 	}
 
 	// Set up to a dedicated type for the response
-	res := &model.TreatmentResponse{Id: uint(id), Name: entries.Name, VisitId: entries.VisitId}
+	res := treatmentResponse(entries)
+
+	render.JSON(w, r, res)
+}
+
+// PatchHandler godoc
+// @Summary      Partially update a treatment
+// @Description  Updates only the given fields of a treatment, with a JSON Merge Patch (Content-Type application/merge-patch+json) or a JSON Patch (Content-Type application/json-patch+json). The patched treatment is checked like a full update.
+// @Tags         treatments
+// @Accept       json
+// @Produce      json
+// @Param        id         path      int                     true  "Treatment ID"
+// @Param        treatment  body      model.TreatmentRequest  true  "Fields to change (merge patch) or operations (JSON patch)"
+// @Security     BearerAuth
+// @Success      200  {object}  model.TreatmentResponse
+// @Failure      400  {object}  problem.Problem  "Invalid request payload"
+// @Failure      404  {object}  problem.Problem  "Treatment not found"
+// @Failure      409  {object}  problem.Problem  "JSON Patch test failed"
+// @Failure      415  {object}  problem.Problem  "Unsupported Content-Type"
+// @Failure      422  {object}  problem.Problem  "Validation failed or operation not applicable"
+// @Failure      500  {object}  problem.Problem  "Failed to update treatment"
+// @Router       /treatments/{id} [patch]
+func (config *TreatmentConfig) PatchHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the id in the URL
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		problem.NotFound(w, r, "Missing id")
+		return
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		problem.BadRequest(w, r, "id must be an integer")
+		return
+	}
+
+	// Get the current treatment, the patch is applied to its informations
+	before, err := config.TreatmentEntryRepository.FindById(id)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find specific Treatment")
+		return
+	}
+
+	req := &model.TreatmentRequest{}
+	if err := patch.Apply(r, treatmentRequest(before), req); err != nil {
+		problem.Patch(w, r, err, "Invalid Treatment Patch request payload")
+		return
+	}
+
+	// The patched treatment is checked like a full update
+	if err := req.Bind(r); err != nil {
+		problem.Bind(w, r, err, "Invalid Treatment Patch request payload")
+		return
+	}
+
+	// Check if the linked visit id existe
+	if !config.VisitEntryRepository.FindLastVisitId(int(*req.VisitId)) {
+		problem.InvalidReference(w, r, "VisitId not found in the DB")
+		return
+	}
+
+	// Only the changed columns are updated
+	columns := map[string]interface{}{}
+	patch.Changed(columns, "name", before.Name, *req.Name)
+	patch.Changed(columns, "visit_id", before.VisitId, *req.VisitId)
+
+	if len(columns) == 0 {
+		render.JSON(w, r, treatmentResponse(before))
+		return
+	}
+
+	// Request the DB to Update the changed informations
+	if _, err := config.TreatmentEntryRepository.WithContext(audit.Context(r)).Patch(id, columns); err != nil {
+		problem.Error(w, r, err, "Failed to Update Treatment")
+		return
+	}
+
+	// Request the DB to get the updated treatment
+	entries, err := config.TreatmentEntryRepository.FindById(id)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find updated Treatment")
+		return
+	}
+
+	// Set up to a dedicated type for the response
+	res := treatmentResponse(entries)
 
 	render.JSON(w, r, res)
 }
@@ -235,29 +393,110 @@ func (config *TreatmentConfig) UpdateHandler(w http.ResponseWriter, r *http.Requ
 // @Param        id   path      int  true  "Treatment ID"
 // @Security     BearerAuth
 // @Success      200  {object}  map[string]string  "Treatment deleted successfully"
-// @Failure      404  {object}  map[string]string  "Treatment not found"
-// @Failure      500  {object}  map[string]string  "Failed to delete treatment"
+// @Failure      400  {object}  problem.Problem  "Invalid request"
+// @Failure      404  {object}  problem.Problem  "Treatment not found"
+// @Failure      500  {object}  problem.Problem  "Failed to delete treatment"
 // @Router       /treatments/{id} [delete]
 func (config *TreatmentConfig) DeleteHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Get the id in the URL
 	idStr := chi.URLParam(r, "id")
 	if idStr == "" {
-		w.WriteHeader(http.StatusNotFound)
+		problem.NotFound(w, r, "Missing id")
 		return
 	}
 
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		fmt.Println("Error during id convertion")
+		problem.BadRequest(w, r, "id must be an integer")
+		return
 	}
 
 	// Request the DB to Delete the informations
-	errDelete := config.TreatmentEntryRepository.DeleteById(id)
+	errDelete := config.TreatmentEntryRepository.WithContext(audit.Context(r)).DeleteById(id)
 	if errDelete != nil {
-		render.JSON(w, r, map[string]string{"error": "Failed to Delete Treatment"})
+		problem.Error(w, r, errDelete, "Failed to Delete Treatment")
 		return
 	}
 
 	render.JSON(w, r, map[string]string{"message": "Treatment deleted successfully"})
 }
+
+// RestoreHandler godoc
+// @Summary      Restore a treatment
+// @Description  Takes a treatment out of the trash, its visit must not be in the trash
+// @Tags         treatments
+// @Produce      json
+// @Param        id   path      int  true  "Treatment ID"
+// @Security     BearerAuth
+// @Success      200  {object}  model.TreatmentResponse
+// @Failure      400  {object}  problem.Problem  "Invalid request"
+// @Failure      404  {object}  problem.Problem  "Treatment not found in the trash"
+// @Failure      409  {object}  problem.Problem  "The visit of the treatment is in the trash"
+// @Router       /treatments/{id}/restore [post]
+func (config *TreatmentConfig) RestoreHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the id in the URL
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		problem.NotFound(w, r, "Missing id")
+		return
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		problem.BadRequest(w, r, "id must be an integer")
+		return
+	}
+
+	// Request the DB to take the treatment out of the trash
+	if _, err := config.TreatmentEntryRepository.WithContext(audit.Context(r)).Restore(id); err != nil {
+		if errors.Is(err, dbmodel.ErrParentDeleted) {
+			problem.Conflict(w, r, "The visit of the treatment is in the trash, restore it first")
+			return
+		}
+		problem.Error(w, r, err, "Treatment not found in the trash")
+		return
+	}
+
+	// Request the DB to get the restored treatment
+	entries, err := config.TreatmentEntryRepository.FindById(id)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find restored Treatment")
+		return
+	}
+
+	// Set up to a dedicated type for the response
+	res := treatmentResponse(entries)
+
+	render.JSON(w, r, res)
+}
+
+// Check if the visit can be read by the user, owners can only read the visits of their own cats
+func (config *TreatmentConfig) isVisitReadable(r *http.Request, visitId int) bool {
+
+	ownerId, ok := authentication.OwnerIdFromContext(r.Context())
+	if !ok {
+		return true
+	}
+
+	visit, err := config.VisitEntryRepository.FindById(visitId)
+	if err != nil {
+		return false
+	}
+
+	return config.CatEntryRepository.BelongsToOwner(int(visit.CatId), ownerId)
+}
+
+// Set up a treatment to the request model, the patches are applied to it
+func treatmentRequest(entries *dbmodel.TreatmentEntry) *model.TreatmentRequest {
+
+	return &model.TreatmentRequest{
+		Name:    &entries.Name,
+		VisitId: &entries.VisitId}
+}
+
+// Set up a treatment to a dedicated type for the responses
+func treatmentResponse(entries *dbmodel.TreatmentEntry) *model.TreatmentResponse {
+	return &model.TreatmentResponse{Id: entries.ID, Name: entries.Name, VisitId: entries.VisitId, CreatedBy: entries.CreatedBy, UpdatedBy: entries.UpdatedBy}
+}
@@ -15,17 +15,22 @@ func Routes(configuration *config.Config) chi.Router {
 
 	// Routes protected by authentication
 	router.Group(func(router chi.Router) {
-		router.Use(authentication.AuthMiddleware(treatmentConfig.JWTSecret))
+		router.Use(authentication.AuthMiddleware(treatmentConfig.AccessTokenKeys, treatmentConfig.RevocationStore, treatmentConfig.ApiKeyStore, treatmentConfig.RoleEntryRepository))
 
-		router.Get("/", treatmentConfig.GetAllHandler)
-		router.Get("/{id}", treatmentConfig.GetByIdHandler)
-		router.Get("/{id}/history", treatmentConfig.GetByVisitIdHandler)
+		// Routes protected by authentication and accessible with the "treatments:read" permission
+		router.With(authentication.RequirePermission("treatments:read")).Group(func(r chi.Router) {
+			r.Get("/", treatmentConfig.GetAllHandler)
+			r.Get("/{id}", treatmentConfig.GetByIdHandler)
+			r.Get("/{id}/history", treatmentConfig.GetByVisitIdHandler)
+		})
 
-		// Routes protected by authentication and accessible by admin only
-		router.With(authentication.RoleMiddleware("admin")).Group(func(r chi.Router) {
+		// Routes protected by authentication and accessible with the "treatments:prescribe" permission
+		router.With(authentication.RequirePermission("treatments:prescribe")).Group(func(r chi.Router) {
 			r.Post("/", treatmentConfig.PostHandler)
 			r.Put("/{id}", treatmentConfig.UpdateHandler)
+			r.Patch("/{id}", treatmentConfig.PatchHandler)
 			r.Delete("/{id}", treatmentConfig.DeleteHandler)
+			r.Post("/{id}/restore", treatmentConfig.RestoreHandler)
 		})
 	})
 
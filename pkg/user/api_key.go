@@ -0,0 +1,226 @@
+package user
+
+import (
+	"net/http"
+	"strconv"
+	"vet-clinic-api/database/dbmodel"
+	"vet-clinic-api/pkg/audit"
+	"vet-clinic-api/pkg/authentication"
+	"vet-clinic-api/pkg/model"
+	"vet-clinic-api/pkg/problem"
+	"vet-clinic-api/pkg/validation"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// CreateApiKeyHandler godoc
+// @Summary      Create an API key
+// @Description  Creates a long-lived API key for a user, sent as "Authorization: ApiKey <key>". The key is only returned once and is limited to its scopes, which must be granted to the user role.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        id      path      int                   true  "User ID"
+// @Param        apiKey  body      model.ApiKeyRequest   true  "API key payload"
+// @Security     BearerAuth
+// @Success      200  {object}  model.ApiKeyResponse
+// @Failure      400  {object}  problem.Problem  "Invalid API key request payload"
+// @Failure      404  {object}  problem.Problem  "User not found"
+// @Failure      422  {object}  problem.Problem  "Validation failed"
+// @Failure      500  {object}  problem.Problem  "Failed to create the API key"
+// @Router       /users/{id}/api-keys [post]
+func (config *UserConfig) CreateApiKeyHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the id in the URL
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		problem.NotFound(w, r, "Missing id")
+		return
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		problem.BadRequest(w, r, "id must be an integer")
+		return
+	}
+
+	// Get the request
+	req := &model.ApiKeyRequest{}
+	if err := render.Bind(r, req); err != nil {
+		problem.Bind(w, r, err, "Invalid API key request payload")
+		return
+	}
+
+	// Check if the user existe
+	user, err := config.UserEntryRepository.FindById(id)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find specific User")
+		return
+	}
+
+	// Check if the scopes existe and are granted to the role of the user
+	scopes, err := config.PermissionEntryRepository.FindByNames(req.Scopes)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find Permissions")
+		return
+	}
+
+	found := map[string]bool{}
+	for _, scope := range scopes {
+		found[scope.Name] = true
+	}
+
+	v := validation.New()
+	for i, name := range req.Scopes {
+		if !found[name] {
+			problem.InvalidReference(w, r, "Permission "+name+" not found in the DB")
+			return
+		}
+
+		if !config.RoleEntryRepository.HasPermission(user.Role, name) {
+			v.Add("api_key_scopes["+strconv.Itoa(i)+"]", validation.CodeNotGranted, "Permission "+name+" not granted to the role "+user.Role, map[string]interface{}{"role": user.Role})
+		}
+	}
+
+	if fieldErrors := v.Errors(); len(fieldErrors) > 0 {
+		problem.Fields(w, r, "Invalid API key scopes", fieldErrors)
+		return
+	}
+
+	key, prefix, err := authentication.GenerateApiKey()
+	if err != nil {
+		problem.Error(w, r, err, "Failed to generate API key")
+		return
+	}
+
+	// Request the DB to Create the key, only its hash is saved
+	entries, err := config.ApiKeyEntryRepository.WithContext(audit.ActionContext(r, audit.CreateApiKey)).Create(&dbmodel.ApiKeyEntry{
+		Name:      *req.Name,
+		Prefix:    prefix,
+		KeyHash:   authentication.HashApiKey(key),
+		UserId:    user.ID,
+		ExpiresAt: req.ExpiresAt,
+		Scopes:    scopes,
+	})
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Create API key")
+		return
+	}
+
+	res := apiKeyResponse(entries)
+	res.Key = key
+
+	render.JSON(w, r, res)
+}
+
+// GetApiKeysHandler godoc
+// @Summary      Get the API keys of a user
+// @Description  Find the API keys of a specific user, only their prefix is shown
+// @Tags         users
+// @Produce      json
+// @Param        id   path      int  true  "User ID"
+// @Security     BearerAuth
+// @Success      200  {array}   model.ApiKeyResponse
+// @Failure      400  {object}  problem.Problem  "Invalid request"
+// @Failure      404  {object}  problem.Problem  "User not found"
+// @Failure      500  {object}  problem.Problem  "Failed to find the API keys"
+// @Router       /users/{id}/api-keys [get]
+func (config *UserConfig) GetApiKeysHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the id in the URL
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		problem.NotFound(w, r, "Missing id")
+		return
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		problem.BadRequest(w, r, "id must be an integer")
+		return
+	}
+
+	// Check if the user existe
+	if _, err := config.UserEntryRepository.FindById(id); err != nil {
+		problem.Error(w, r, err, "Failed to Find specific User")
+		return
+	}
+
+	// Request the DB to Find the keys of the user
+	entries, err := config.ApiKeyEntryRepository.FindByUserId(uint(id))
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find API keys")
+		return
+	}
+
+	// Set up to a dedicated type for the response
+	res := []*model.ApiKeyResponse{}
+	for _, entry := range entries {
+		res = append(res, apiKeyResponse(entry))
+	}
+
+	render.JSON(w, r, res)
+}
+
+// DeleteApiKeyHandler godoc
+// @Summary      Delete an API key
+// @Description  Deletes an API key of a user, the key can't be used anymore
+// @Tags         users
+// @Produce      json
+// @Param        id     path      int  true  "User ID"
+// @Param        keyId  path      int  true  "API key ID"
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]string  "API key deleted successfully"
+// @Failure      400  {object}  problem.Problem  "Invalid request"
+// @Failure      404  {object}  problem.Problem  "API key not found"
+// @Router       /users/{id}/api-keys/{keyId} [delete]
+func (config *UserConfig) DeleteApiKeyHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the ids in the URL
+	idStr := chi.URLParam(r, "id")
+	keyIdStr := chi.URLParam(r, "keyId")
+	if idStr == "" || keyIdStr == "" {
+		problem.NotFound(w, r, "Missing id")
+		return
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		problem.BadRequest(w, r, "id must be an integer")
+		return
+	}
+
+	keyId, err := strconv.Atoi(keyIdStr)
+	if err != nil {
+		problem.BadRequest(w, r, "keyId must be an integer")
+		return
+	}
+
+	// Request the DB to Delete the key, only a key of the given user is deleted
+	if err := config.ApiKeyEntryRepository.WithContext(audit.ActionContext(r, audit.DeleteApiKey)).DeleteById(uint(id), keyId); err != nil {
+		problem.Error(w, r, err, "Failed to Delete API key")
+		return
+	}
+
+	render.JSON(w, r, map[string]string{"message": "API key deleted successfully"})
+}
+
+// Set up an API key to a dedicated type for the response, without the key itself
+func apiKeyResponse(entry *dbmodel.ApiKeyEntry) *model.ApiKeyResponse {
+
+	scopes := []string{}
+	for _, scope := range entry.Scopes {
+		scopes = append(scopes, scope.Name)
+	}
+
+	return &model.ApiKeyResponse{
+		Id:         entry.ID,
+		Name:       entry.Name,
+		Prefix:     entry.Prefix,
+		Scopes:     scopes,
+		UserId:     entry.UserId,
+		ExpiresAt:  entry.ExpiresAt,
+		LastUsedAt: entry.LastUsedAt,
+		CreatedAt:  entry.CreatedAt,
+	}
+}
@@ -0,0 +1,50 @@
+package user
+
+import (
+	"errors"
+	"log"
+	"vet-clinic-api/config"
+	"vet-clinic-api/database/dbmodel"
+)
+
+// Create the first admin with the given credentials when there is no user in the DB
+func BootstrapAdmin(configuration *config.Config, email, password string) error {
+
+	// Check if the DB already has users
+	count, err := configuration.UserEntryRepository.Count()
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	if email == "" || password == "" {
+		log.Println("No user found, set BOOTSTRAP_ADMIN_EMAIL and BOOTSTRAP_ADMIN_PASSWORD to create the first admin")
+		return nil
+	}
+
+	// Check the admin password strength and hash it for better security
+	if err := configuration.PasswordService.Validate(password, email); err != nil {
+		return errors.New("bootstrap admin password refused, " + err.Error())
+	}
+
+	hashedPassword, err := configuration.PasswordService.Hash(password)
+	if err != nil {
+		return errors.New("failed to hash the bootstrap admin password")
+	}
+
+	// Request the DB to Create the first admin
+	if _, err := configuration.UserEntryRepository.Create(&dbmodel.UserEntry{
+		Email:    email,
+		Password: hashedPassword,
+		Role:     "admin",
+	}); err != nil {
+		return err
+	}
+
+	log.Println("Bootstrap admin created:", email)
+
+	return nil
+}
@@ -1,19 +1,30 @@
 package user
 
 import (
-	"fmt"
+	"log"
 	"net/http"
 	"strconv"
 	"vet-clinic-api/config"
 	"vet-clinic-api/database/dbmodel"
+	"vet-clinic-api/pkg/audit"
 	"vet-clinic-api/pkg/authentication"
 	"vet-clinic-api/pkg/model"
+	"vet-clinic-api/pkg/pagination"
+	"vet-clinic-api/pkg/patch"
+	"vet-clinic-api/pkg/problem"
+	"vet-clinic-api/pkg/validation"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
-	"golang.org/x/crypto/bcrypt"
 )
 
+// Fields of the response used to sort the users, with their column
+var sortFields = map[string]string{
+	"id":         "id",
+	"user_email": "email",
+	"user_role":  "role",
+}
+
 type UserConfig struct {
 	*config.Config
 }
@@ -25,116 +36,157 @@ func New(configuration *config.Config) *UserConfig {
 // LoginHandler godoc
 // @Summary      Authenticate a user and get JWT
 // @Description  Authenticates a user by email and password, returns a JWT token if credentials are valid.
+// @Description  Accounts with TOTP enabled, or whose role requires it, get a model.MfaChallengeResponse to exchange on /users/login/mfa instead.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
 // @Param        login body model.UserLoginRequest true "Login credentials"
 // @Success      200  {object}  model.TokensResponse
-// @Failure      400  {object}  map[string]string "Invalid JSON payload"
-// @Failure      401  {object}  map[string]string "Invalid email or password"
-// @Failure      500  {object}  map[string]string "Failed to generate token"
+// @Success      202  {object}  model.MfaChallengeResponse
+// @Failure      400  {object}  problem.Problem "Invalid JSON payload"
+// @Failure      401  {object}  problem.Problem "Invalid email or password"
+// @Failure      422  {object}  problem.Problem "Validation failed"
+// @Failure      429  {object}  problem.Problem "Too many failed login attempts, retry later"
+// @Failure      500  {object}  problem.Problem "Failed to generate token"
 // @Router       /users/login [post]
 func (config *UserConfig) LoginHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Get the request
 	req := &model.UserLoginRequest{}
 	if err := render.Bind(r, req); err != nil {
-		render.JSON(w, r, map[string]string{"error": "Invalid User Post request payload. " + err.Error()})
+		problem.Bind(w, r, err, "Invalid User Post request payload")
 		return
 	}
 
-	// Request the DB to Find the informations
+	// Request the DB to Find the informations, an unknown email gets the same answers as a wrong password
 	user, err := config.UserEntryRepository.FindByEmail(*req.Email)
-	if err != nil || user == nil {
-		render.JSON(w, r, map[string]string{"error": "No user found with email : " + *req.Email})
+	if err != nil {
+		user = nil
+	}
+
+	// Refuse the attempt while the IP address or the account is locked
+	if wait, blocked := config.loginBlocked(r, *req.Email, user); blocked {
+		tooManyAttempts(w, r, wait)
 		return
 	}
 
-	// Check User password
-	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(*req.Password)) != nil {
-		http.Error(w, "Invalid email or password", http.StatusUnauthorized)
+	// Check User password, a dummy hash is compared for an unknown email to keep the same response time
+	valid, needsRehash := false, false
+	if user != nil {
+		valid, needsRehash = config.PasswordService.Verify(user.Password, *req.Password)
+	} else {
+		config.PasswordService.VerifyDummy(*req.Password)
+	}
+
+	if !valid {
+		config.recordLoginFailure(r, *req.Email, user)
+		problem.Unauthorized(w, r, "Invalid email or password")
 		return
 	}
 
-	// Generate access token for a specific user with 2 hours expiration time
-	accessToken, err := authentication.GenerateToken(config.JWTSecret,
-		map[string]interface{}{
-			"email": user.Email,
-			"role":  user.Role},
-		2)
+	// Upgrade a hash made with an old algorithm or cost, the password is known only now
+	if needsRehash {
+		if hashedPassword, err := config.PasswordService.Hash(*req.Password); err == nil {
+			if err := config.UserEntryRepository.UpdatePassword(int(user.ID), hashedPassword); err != nil {
+				log.Println("Failed to rehash the password of user", user.ID)
+			}
+		}
+	}
 
-	if err != nil {
-		http.Error(w, "Failed to generate access token", http.StatusInternalServerError)
+	// Accounts protected by a second factor get a short-lived token to exchange with a TOTP code
+	if user.TotpEnabled || config.mfaRequired(user) {
+		mfaToken, err := config.generateMfaToken(user)
+		if err != nil {
+			problem.Internal(w, r, "Failed to generate mfa token")
+			return
+		}
+
+		render.Status(r, http.StatusAccepted)
+		render.JSON(w, r, &model.MfaChallengeResponse{Token: mfaToken, EnrollmentRequired: !user.TotpEnabled})
 		return
 	}
 
-	// Generate refresh token for a specific user with 7 days expiration time
-	refreshToken, err := authentication.GenerateToken(config.JWTRefreshSecret, map[string]interface{}{"email": user.Email}, 7*24)
+	// Generate access token and start a new session for a specific user
+	res, err := config.issueTokens(user)
 	if err != nil {
-		http.Error(w, "Failed to generate refresh token", http.StatusInternalServerError)
+		problem.Internal(w, r, "Failed to generate token")
 		return
 	}
 
-	// Set up token to specific response format for better readability
-	res := &model.TokensResponse{AccessToken: accessToken, RefreshToken: refreshToken}
+	config.recordLoginSuccess(user)
 
 	render.JSON(w, r, res)
 }
 
 // RefreshHandler godoc
 // @Summary      Refresh access token
-// @Description  Generate a new access token using a valid refresh token
+// @Description  Generate a new access token and a new refresh token using a valid refresh token. The used refresh token is revoked, using it again revokes the whole session.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
 // @Param        refresh body model.RefreshTokenRequest true "Refresh token payload"
-// @Success      200  {object}  model.AccessTokenResponse
-// @Failure      400  {object}  map[string]string "Invalid JSON payload"
-// @Failure      401  {object}  map[string]string "Invalid refresh token"
-// @Failure      500  {object}  map[string]string "Failed to generate token"
+// @Success      200  {object}  model.TokensResponse
+// @Failure      400  {object}  problem.Problem "Invalid JSON payload"
+// @Failure      401  {object}  problem.Problem "Invalid refresh token"
+// @Failure      422  {object}  problem.Problem "Validation failed"
+// @Failure      500  {object}  problem.Problem "Failed to generate token"
 // @Router       /users/refresh [post]
 func (config *UserConfig) RefreshHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Get the request
 	req := &model.RefreshTokenRequest{}
 	if err := render.Bind(r, req); err != nil {
-		render.JSON(w, r, map[string]string{"error": "Invalid refresh token request payload. " + err.Error()})
+		problem.Bind(w, r, err, "Invalid refresh token request payload")
+		return
+	}
+
+	// Check the refresh token validity and find the issued token in the DB
+	entry, err := config.findRefreshToken(*req.RefreshToken)
+	if err != nil {
+		problem.Unauthorized(w, r, "Invalid refresh token")
 		return
 	}
 
-	// Check the refresh token validity
-	claims, err := authentication.ParseTokenClaims(config.JWTRefreshSecret, *req.RefreshToken)
+	// Revoke the used token, a token already revoked means it has been stolen so the whole session is revoked
+	active, err := config.RefreshTokenEntryRepository.RevokeByJti(entry.Jti)
 	if err != nil {
-		http.Error(w, "Invalid refresh token", http.StatusUnauthorized)
+		problem.Internal(w, r, "Failed to revoke refresh token")
 		return
 	}
 
-	email, ok := claims["email"].(string)
-	if !ok || email == "" {
-		http.Error(w, "Email claim not found or invalid", http.StatusInternalServerError)
+	if !active {
+		if err := config.RefreshTokenEntryRepository.RevokeFamily(entry.FamilyId); err != nil {
+			problem.Internal(w, r, "Failed to revoke session")
+			return
+		}
+
+		problem.Unauthorized(w, r, "Refresh token reuse detected, session revoked")
 		return
 	}
 
 	// Request the DB to Find the informations
-	user, err := config.UserEntryRepository.FindByEmail(email)
+	user, err := config.UserEntryRepository.FindById(int(entry.UserId))
 	if err != nil || user == nil {
-		render.JSON(w, r, map[string]string{"error": "No user found with email : " + email})
+		problem.Unauthorized(w, r, "Invalid refresh token")
 		return
 	}
 
 	// Generate new access token with 2 hours expiration time
-	newAccessToken, err := authentication.GenerateToken(config.JWTSecret,
-		map[string]interface{}{
-			"email": user.Email,
-			"role":  user.Role},
-		2)
+	newAccessToken, err := authentication.GenerateToken(config.AccessTokenKeys, accessClaims(user, entry.FamilyId), accessTokenDuration)
 
 	if err != nil {
-		http.Error(w, "Failed to generate token", http.StatusInternalServerError)
+		problem.Internal(w, r, "Failed to generate token")
 		return
 	}
 
-	res := &model.AccessTokenResponse{AccessToken: newAccessToken}
+	// Generate new refresh token in the same session with 7 days expiration time
+	newRefreshToken, err := config.generateRefreshToken(user, entry.FamilyId)
+	if err != nil {
+		problem.Internal(w, r, "Failed to generate refresh token")
+		return
+	}
+
+	res := &model.TokensResponse{AccessToken: newAccessToken, RefreshToken: newRefreshToken}
 
 	render.JSON(w, r, res)
 }
@@ -146,76 +198,109 @@ func (config *UserConfig) RefreshHandler(w http.ResponseWriter, r *http.Request)
 // @Accept       json
 // @Produce      json
 // @Param        user  body      model.UserRequest  true  "User creation payload"
+// @Security     BearerAuth
 // @Success      200  {object}  model.UserResponse
-// @Failure      400  {object}  map[string]string  "Invalid User Post request payload"
-// @Failure      500  {object}  map[string]string  "Failed to Create specific User"
+// @Failure      400  {object}  problem.Problem  "Invalid User Post request payload"
+// @Failure      422  {object}  problem.Problem  "Validation failed"
+// @Failure      500  {object}  problem.Problem  "Failed to Create specific User"
 // @Router       /users [post]
 func (config *UserConfig) PostHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Get the request
 	req := &model.UserRequest{}
 	if err := render.Bind(r, req); err != nil {
-		render.JSON(w, r, map[string]string{"error": "Invalid User Post request payload. " + err.Error()})
+		problem.Bind(w, r, err, "Invalid User Post request payload")
 		return
 	}
 
-	// Hash the user password for better security
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(*req.Password), bcrypt.DefaultCost)
+	// Check if the requested role existe
+	if _, err := config.RoleEntryRepository.FindByName(*req.Role); err != nil {
+		problem.Reference(w, r, err, "Role not found in the DB")
+		return
+	}
+
+	// Check if the linked owner id existe
+	if req.OwnerId != nil && !config.OwnerEntryRepository.FindLastOwnerId(int(*req.OwnerId)) {
+		problem.InvalidReference(w, r, "OwnerId not found in the DB")
+		return
+	}
+
+	// Check the password strength and hash it for better security
+	if err := config.PasswordService.Validate(*req.Password, *req.Email); err != nil {
+		problem.Fields(w, r, "Invalid password", validation.Errors{{Path: "user_password", Code: validation.CodePassword, Message: err.Error()}})
+		return
+	}
+
+	hashedPassword, err := config.PasswordService.Hash(*req.Password)
 	if err != nil {
-		render.JSON(w, r, map[string]string{"error": "Failed to hash password"})
+		problem.Error(w, r, err, "Failed to hash password")
 		return
 	}
 
 	// Convert the requested data into dbmodel.UserEntry type for the "Create" function
 	userEntry := &dbmodel.UserEntry{
 		Email:    *req.Email,
-		Password: string(hashedPassword),
+		Password: hashedPassword,
 		Role:     *req.Role,
+		OwnerId:  req.OwnerId,
 	}
 
 	// Request the DB to Create the informations
-	entries, err := config.UserEntryRepository.Create(userEntry)
+	entries, err := config.UserEntryRepository.WithContext(audit.Context(r)).Create(userEntry)
 	if err != nil {
-		render.JSON(w, r, map[string]string{"error": "Failed to Create specific User"})
+		problem.Error(w, r, err, "Failed to Create specific User")
 		return
 	}
 
 	// Set up to a dediusered type for the response
-	res := &model.UserResponse{
-		Id:    entries.ID,
-		Email: entries.Email,
-		Role:  entries.Role}
+	res := userResponse(entries)
 
 	render.JSON(w, r, res)
 }
 
 // GetAllHandler godoc
 // @Summary      Get all Users
-// @Description  Find all the users in the database
+// @Description  Find the users in the database by page, optionally filtered by email and role. The total count is in the X-Total-Count header and the other pages in the Link header.
 // @Tags         users
 // @Produce      json
+// @Param        email   query     string  false  "Filter by email"
+// @Param        role    query     string  false  "Filter by role"
+// @Param        limit   query     int     false  "Number of users of the page (default 50, max 200)"
+// @Param        offset  query     int     false  "Number of users skipped, instead of the cursor"
+// @Param        cursor  query     string  false  "Cursor of the next page, given in the Link header"
+// @Param        sort    query     string  false  "Fields separated by commas, - for descending (id, user_email, user_role)"
+// @Security     BearerAuth
 // @Success      200  {array}  model.UserResponse
-// @Failure      500  {object}  map[string]string  "Failed to retrieve users"
+// @Header       200  {integer}  X-Total-Count  "Number of users matching the filters"
+// @Header       200  {string}   Link           "Links to the first, previous, next and last pages"
+// @Failure      400  {object}  problem.Problem  "Invalid filter or page"
+// @Failure      500  {object}  problem.Problem  "Failed to retrieve users"
 // @Router       /users [get]
 func (config *UserConfig) GetAllHandler(w http.ResponseWriter, r *http.Request) {
 
+	// Set up the filters
+	filter := dbmodel.UserFilter{Email: r.URL.Query().Get("email"), Role: r.URL.Query().Get("role")}
+
+	page, err := pagination.Parse(r, sortFields)
+	if err != nil {
+		problem.BadRequest(w, r, err.Error())
+		return
+	}
+
 	// Request the DB to get the needed informations
-	entries, err := config.UserEntryRepository.FindAll()
+	entries, info, err := config.UserEntryRepository.FindAll(filter, page)
 	if err != nil {
-		render.JSON(w, r, map[string]string{"error": "Invalid Find All Users request payload"})
+		problem.Error(w, r, err, "Invalid Find All Users request payload")
 		return
 	}
 
 	// Set up to a dediusered type for the response
-	var result []*model.UserResponse
+	result := []*model.UserResponse{}
 	for _, entrie := range entries {
-		result = append(result,
-			&model.UserResponse{
-				Id:    entrie.ID,
-				Email: entrie.Email,
-				Role:  entrie.Role})
+		result = append(result, userResponse(entrie))
 	}
 
+	pagination.Write(w, r, page, info)
 	render.JSON(w, r, result)
 }
 
@@ -225,36 +310,36 @@ func (config *UserConfig) GetAllHandler(w http.ResponseWriter, r *http.Request)
 // @Tags         users
 // @Produce      json
 // @Param        id   path      int  true  "User ID"
+// @Security     BearerAuth
 // @Success      200  {object}  model.UserResponse
-// @Failure      404  {object}  map[string]string  "User not found"
-// @Failure      500  {object}  map[string]string  "Failed to find specific user"
+// @Failure      400  {object}  problem.Problem  "Invalid request"
+// @Failure      404  {object}  problem.Problem  "User not found"
+// @Failure      500  {object}  problem.Problem  "Failed to find specific user"
 // @Router       /users/{id} [get]
 func (config *UserConfig) GetByIdHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Get the id in the URL
 	idStr := chi.URLParam(r, "id")
 	if idStr == "" {
-		w.WriteHeader(http.StatusNotFound)
+		problem.NotFound(w, r, "Missing id")
 		return
 	}
 
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		fmt.Println("Error during id convertion")
+		problem.BadRequest(w, r, "id must be an integer")
+		return
 	}
 
 	// Request the DB to get the needed informations
 	entries, err := config.UserEntryRepository.FindById(id)
 	if err != nil {
-		render.JSON(w, r, map[string]string{"error": "Failed to Find specific User"})
+		problem.Error(w, r, err, "Failed to Find specific User")
 		return
 	}
 
 	// Set up to a dediusered type for the response
-	res := &model.UserResponse{
-		Id:    entries.ID,
-		Email: entries.Email,
-		Role:  entries.Role}
+	res := userResponse(entries)
 
 	render.JSON(w, r, res)
 }
@@ -267,51 +352,205 @@ func (config *UserConfig) GetByIdHandler(w http.ResponseWriter, r *http.Request)
 // @Produce      json
 // @Param        id   path      int                 true  "User ID"
 // @Param        user  body      model.UserRequest   true  "User update payload"
+// @Security     BearerAuth
 // @Success      200  {object}  model.UserResponse
-// @Failure      400  {object}  map[string]string  "Invalid request payload"
-// @Failure      404  {object}  map[string]string  "User not found"
-// @Failure      500  {object}  map[string]string  "Failed to update user"
+// @Failure      400  {object}  problem.Problem  "Invalid request payload"
+// @Failure      404  {object}  problem.Problem  "User not found"
+// @Failure      422  {object}  problem.Problem  "Validation failed"
+// @Failure      500  {object}  problem.Problem  "Failed to update user"
 // @Router       /users/{id} [put]
 func (config *UserConfig) UpdateHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Get the id in the UR
 	idStr := chi.URLParam(r, "id")
 	if idStr == "" {
-		w.WriteHeader(http.StatusNotFound)
+		problem.NotFound(w, r, "Missing id")
 		return
 	}
 
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		fmt.Println("Error during id convertion")
+		problem.BadRequest(w, r, "id must be an integer")
+		return
 	}
 
 	// Get the request
 	req := &model.UserRequest{}
 	if err := render.Bind(r, req); err != nil {
-		render.JSON(w, r, map[string]string{"error": "Invalid User Update request payload. " + err.Error()})
+		problem.Bind(w, r, err, "Invalid User Update request payload")
+		return
+	}
+
+	// Check if the requested role existe
+	if _, err := config.RoleEntryRepository.FindByName(*req.Role); err != nil {
+		problem.Reference(w, r, err, "Role not found in the DB")
+		return
+	}
+
+	// Check if the linked owner id existe
+	if req.OwnerId != nil && !config.OwnerEntryRepository.FindLastOwnerId(int(*req.OwnerId)) {
+		problem.InvalidReference(w, r, "OwnerId not found in the DB")
+		return
+	}
+
+	// Check the password strength and hash it, the password was saved in plain text before
+	if err := config.PasswordService.Validate(*req.Password, *req.Email); err != nil {
+		problem.Fields(w, r, "Invalid password", validation.Errors{{Path: "user_password", Code: validation.CodePassword, Message: err.Error()}})
+		return
+	}
+
+	hashedPassword, err := config.PasswordService.Hash(*req.Password)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to hash password")
 		return
 	}
 
 	// Convert the requested data into dbmodel.UserEntry type for the "Update" function
 	userEntry := &dbmodel.UserEntry{
 		Email:    *req.Email,
-		Password: *req.Password,
+		Password: hashedPassword,
 		Role:     *req.Role,
+		OwnerId:  req.OwnerId,
 	}
 
 	// Request the DB to Update the informations
-	entries, err := config.UserEntryRepository.Update(id, userEntry)
+	if _, err := config.UserEntryRepository.WithContext(audit.Context(r)).Update(id, userEntry); err != nil {
+		problem.Error(w, r, err, "Failed to Update User")
+		return
+	}
+
+	// Request the DB to get the updated user with its second factor state
+	entries, err := config.UserEntryRepository.FindById(id)
 	if err != nil {
-		render.JSON(w, r, map[string]string{"error": "Failed to Update User"})
+		problem.Error(w, r, err, "Failed to Find specific User")
+		return
+	}
+
+	// Revoke the access tokens of the user, they still carry the old role
+	if err := config.revokeAccessTokens(uint(id)); err != nil {
+		problem.Error(w, r, err, "Failed to Revoke User tokens")
 		return
 	}
 
 	// Set up to a dediusered type for the response
-	res := &model.UserResponse{
-		Id:    uint(id),
-		Email: entries.Email,
-		Role:  entries.Role}
+	res := userResponse(entries)
+
+	render.JSON(w, r, res)
+}
+
+// PatchHandler godoc
+// @Summary      Partially update a user
+// @Description  Updates only the given fields of a user, with a JSON Merge Patch (Content-Type application/merge-patch+json) or a JSON Patch (Content-Type application/json-patch+json). The password is only changed when user_password is given, the access tokens of the user are revoked when something changes.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        id    path      int                     true  "User ID"
+// @Param        user  body      model.UserPatchRequest  true  "Fields to change (merge patch) or operations (JSON patch)"
+// @Security     BearerAuth
+// @Success      200  {object}  model.UserResponse
+// @Failure      400  {object}  problem.Problem  "Invalid request payload"
+// @Failure      404  {object}  problem.Problem  "User not found"
+// @Failure      409  {object}  problem.Problem  "JSON Patch test failed"
+// @Failure      415  {object}  problem.Problem  "Unsupported Content-Type"
+// @Failure      422  {object}  problem.Problem  "Validation failed or operation not applicable"
+// @Failure      500  {object}  problem.Problem  "Failed to update user"
+// @Router       /users/{id} [patch]
+func (config *UserConfig) PatchHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the id in the URL
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		problem.NotFound(w, r, "Missing id")
+		return
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		problem.BadRequest(w, r, "id must be an integer")
+		return
+	}
+
+	// Get the current user, the patch is applied to its informations without the password
+	before, err := config.UserEntryRepository.FindById(id)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find specific User")
+		return
+	}
+
+	current := &model.UserPatchRequest{Email: &before.Email, Role: &before.Role, OwnerId: before.OwnerId}
+
+	req := &model.UserPatchRequest{}
+	if err := patch.Apply(r, current, req); err != nil {
+		problem.Patch(w, r, err, "Invalid User Patch request payload")
+		return
+	}
+
+	// The patched user is checked like a full update
+	if err := req.Bind(r); err != nil {
+		problem.Bind(w, r, err, "Invalid User Patch request payload")
+		return
+	}
+
+	// Check if the requested role existe
+	if _, err := config.RoleEntryRepository.FindByName(*req.Role); err != nil {
+		problem.Reference(w, r, err, "Role not found in the DB")
+		return
+	}
+
+	// Check if the linked owner id existe
+	if req.OwnerId != nil && !config.OwnerEntryRepository.FindLastOwnerId(int(*req.OwnerId)) {
+		problem.InvalidReference(w, r, "OwnerId not found in the DB")
+		return
+	}
+
+	// Only the changed columns are updated
+	columns := map[string]interface{}{}
+	patch.Changed(columns, "email", before.Email, *req.Email)
+	patch.Changed(columns, "role", before.Role, *req.Role)
+	patch.Changed(columns, "owner_id", before.OwnerId, req.OwnerId)
+
+	// Check the password strength and hash it, only when a new password is given
+	if req.Password != nil {
+		if err := config.PasswordService.Validate(*req.Password, *req.Email); err != nil {
+			problem.Fields(w, r, "Invalid password", validation.Errors{{Path: "user_password", Code: validation.CodePassword, Message: err.Error()}})
+			return
+		}
+
+		hashedPassword, err := config.PasswordService.Hash(*req.Password)
+		if err != nil {
+			problem.Error(w, r, err, "Failed to hash password")
+			return
+		}
+
+		columns["password"] = hashedPassword
+	}
+
+	if len(columns) == 0 {
+		render.JSON(w, r, userResponse(before))
+		return
+	}
+
+	// Request the DB to Update the changed informations
+	if err := config.UserEntryRepository.WithContext(audit.Context(r)).Patch(id, columns); err != nil {
+		problem.Error(w, r, err, "Failed to Update User")
+		return
+	}
+
+	// Request the DB to get the updated user with its second factor state
+	entries, err := config.UserEntryRepository.FindById(id)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find specific User")
+		return
+	}
+
+	// Revoke the access tokens of the user, they may carry the old role
+	if err := config.revokeAccessTokens(uint(id)); err != nil {
+		problem.Error(w, r, err, "Failed to Revoke User tokens")
+		return
+	}
+
+	// Set up to a dedicated type for the response
+	res := userResponse(entries)
 
 	render.JSON(w, r, res)
 }
@@ -322,30 +561,198 @@ func (config *UserConfig) UpdateHandler(w http.ResponseWriter, r *http.Request)
 // @Tags         users
 // @Produce      json
 // @Param        id   path      int  true  "User ID"
+// @Security     BearerAuth
 // @Success      200  {object}  map[string]string  "User deleted successfully"
-// @Failure      404  {object}  map[string]string  "User not found"
-// @Failure      500  {object}  map[string]string  "Failed to delete user"
+// @Failure      400  {object}  problem.Problem  "Invalid request"
+// @Failure      404  {object}  problem.Problem  "User not found"
+// @Failure      500  {object}  problem.Problem  "Failed to delete user"
 // @Router       /users/{id} [delete]
 func (config *UserConfig) DeleteHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Get the id in the UR
 	idStr := chi.URLParam(r, "id")
 	if idStr == "" {
-		w.WriteHeader(http.StatusNotFound)
+		problem.NotFound(w, r, "Missing id")
 		return
 	}
 
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		fmt.Println("Error during id convertion")
+		problem.BadRequest(w, r, "id must be an integer")
+		return
 	}
 
 	// Request the DB to Delete the informations
-	errDelete := config.UserEntryRepository.DeleteById(id)
+	errDelete := config.UserEntryRepository.WithContext(audit.Context(r)).DeleteById(id)
 	if errDelete != nil {
-		render.JSON(w, r, map[string]string{"error": "Failed to Delete User"})
+		problem.Error(w, r, errDelete, "Failed to Delete User")
+		return
+	}
+
+	// Revoke the sessions, the access tokens and the API keys of the deleted user
+	if err := config.RefreshTokenEntryRepository.RevokeByUserId(uint(id)); err != nil {
+		problem.Error(w, r, err, "Failed to Revoke User sessions")
+		return
+	}
+
+	if err := config.revokeAccessTokens(uint(id)); err != nil {
+		problem.Error(w, r, err, "Failed to Revoke User tokens")
+		return
+	}
+
+	if err := config.ApiKeyEntryRepository.DeleteByUserId(uint(id)); err != nil {
+		problem.Error(w, r, err, "Failed to Delete User API keys")
 		return
 	}
 
 	render.JSON(w, r, map[string]string{"message": "User deleted successfully"})
 }
+
+// GetMeHandler godoc
+// @Summary      Get the logged-in user
+// @Description  Retrieves the user linked to the access token
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  model.UserResponse
+// @Failure      401  {object}  problem.Problem  "Invalid token"
+// @Failure      500  {object}  problem.Problem  "Failed to find the logged-in user"
+// @Router       /users/me [get]
+func (config *UserConfig) GetMeHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Request the DB to get the user linked to the token
+	entries, err := config.loggedInUser(r)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find the logged-in User")
+		return
+	}
+
+	// Set up to a dedicated type for the response
+	res := userResponse(entries)
+
+	render.JSON(w, r, res)
+}
+
+// UpdateMeHandler godoc
+// @Summary      Update the logged-in user
+// @Description  Updates the email and optionally the password of the user linked to the access token, the role can't be changed. Changing the email or the password requires current_password, and a new password revokes the other sessions of the user.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        user  body      model.UserMeRequest  true  "User update payload"
+// @Security     BearerAuth
+// @Success      200  {object}  model.UserResponse
+// @Failure      400  {object}  problem.Problem  "Invalid request payload"
+// @Failure      401  {object}  problem.Problem  "Invalid token or current password"
+// @Failure      422  {object}  problem.Problem  "Validation failed"
+// @Failure      500  {object}  problem.Problem  "Failed to update the logged-in user"
+// @Router       /users/me [put]
+func (config *UserConfig) UpdateMeHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the request
+	req := &model.UserMeRequest{}
+	if err := render.Bind(r, req); err != nil {
+		problem.Bind(w, r, err, "Invalid User Update request payload")
+		return
+	}
+
+	// Request the DB to get the logged-in user
+	user, err := config.loggedInUser(r)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find the logged-in User")
+		return
+	}
+
+	// The email and the password are only changed by someone knowing the current password
+	changesPassword := req.Password != nil
+	if *req.Email != user.Email || changesPassword {
+		if req.CurrentPassword == nil || *req.CurrentPassword == "" {
+			problem.Fields(w, r, "Current password required", validation.Errors{{Path: "current_password", Code: validation.CodeRequired, Message: "current_password is required to change the email or the password"}})
+			return
+		}
+
+		if valid, _ := config.PasswordService.Verify(user.Password, *req.CurrentPassword); !valid {
+			problem.Unauthorized(w, r, "Invalid current password")
+			return
+		}
+	}
+
+	// Keep the current password unless a new one is given
+	password := user.Password
+	if changesPassword {
+		if err := config.PasswordService.Validate(*req.Password, *req.Email); err != nil {
+			problem.Fields(w, r, "Invalid password", validation.Errors{{Path: "user_password", Code: validation.CodePassword, Message: err.Error()}})
+			return
+		}
+
+		password, err = config.PasswordService.Hash(*req.Password)
+		if err != nil {
+			problem.Error(w, r, err, "Failed to hash password")
+			return
+		}
+	}
+
+	// Convert the requested data into dbmodel.UserEntry type for the "Update" function
+	userEntry := &dbmodel.UserEntry{
+		Email:    *req.Email,
+		Password: password,
+		Role:     user.Role,
+		OwnerId:  user.OwnerId,
+	}
+
+	// Request the DB to Update the informations
+	entries, err := config.UserEntryRepository.WithContext(audit.Context(r)).Update(int(user.ID), userEntry)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Update User")
+		return
+	}
+
+	// A new password logs out the other sessions of the user, the current one is kept
+	if changesPassword {
+		principal, _ := authentication.FromContext(r.Context())
+		if err := config.RefreshTokenEntryRepository.RevokeOtherFamilies(user.ID, principal.SessionId); err != nil {
+			problem.Error(w, r, err, "Failed to Revoke the other sessions")
+			return
+		}
+	}
+
+	// Set up to a dedicated type for the response
+	res := &model.UserResponse{
+		Id:          user.ID,
+		Email:       entries.Email,
+		Role:        entries.Role,
+		OwnerId:     entries.OwnerId,
+		MfaEnabled:  user.TotpEnabled,
+		LockedUntil: user.LockedUntil}
+
+	render.JSON(w, r, res)
+}
+
+// Set up a user to a dedicated type for the responses, the secrets of the user are never given
+func userResponse(entries *dbmodel.UserEntry) *model.UserResponse {
+
+	return &model.UserResponse{
+		Id:          entries.ID,
+		Email:       entries.Email,
+		Role:        entries.Role,
+		OwnerId:     entries.OwnerId,
+		MfaEnabled:  entries.TotpEnabled,
+		LockedUntil: entries.LockedUntil}
+}
+
+// Set up the access token claims of a specific user in a session
+func accessClaims(user *dbmodel.UserEntry, sessionId string) map[string]interface{} {
+
+	claims := map[string]interface{}{
+		"user_id": user.ID,
+		"email":   user.Email,
+		"role":    user.Role,
+		"sid":     sessionId}
+
+	// Owner tokens are limited to the animals of the linked owner
+	if user.Role == "owner" && user.OwnerId != nil {
+		claims["owner_id"] = *user.OwnerId
+	}
+
+	return claims
+}
@@ -0,0 +1,148 @@
+package user
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"vet-clinic-api/config"
+	"vet-clinic-api/database/databasetest"
+	"vet-clinic-api/database/dbmodel"
+	"vet-clinic-api/pkg/authentication"
+	"vet-clinic-api/pkg/model"
+)
+
+// The logged-in user changes their email or password only with their current password
+func TestUpdateMe(t *testing.T) {
+
+	userConfig, user := sessionConfig(t)
+	withPassword(t, userConfig, user, "Clinic-Boot-77x")
+
+	// The request is made in the current session, the other session is logged out by a new password
+	current := newSession(t, userConfig, user)
+	other := newSession(t, userConfig, user)
+	session, err := userConfig.findRefreshToken(current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	principal := &authentication.Principal{UserId: user.ID, Email: user.Email, Role: user.Role, SessionId: session.FamilyId}
+
+	call := func(method string, body string) (int, *model.UserResponse) {
+		r := httptest.NewRequest(method, "/api/v1/vet/users/me", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		r = r.WithContext(authentication.NewContext(r.Context(), principal))
+		w := httptest.NewRecorder()
+		if method == "GET" {
+			userConfig.GetMeHandler(w, r)
+		} else {
+			userConfig.UpdateMeHandler(w, r)
+		}
+
+		res := &model.UserResponse{}
+		json.NewDecoder(w.Body).Decode(res)
+		return w.Code, res
+	}
+
+	steps := []struct {
+		name   string
+		method string
+		body   string
+		status int
+		email  string
+	}{
+		{"get", "GET", "", 200, "vet@example.com"},
+		{"same email", "PUT", `{"user_email":"vet@example.com"}`, 200, "vet@example.com"},
+		{"email without current password", "PUT", `{"user_email":"martin@example.com"}`, 422, ""},
+		{"email with a wrong current password", "PUT", `{"user_email":"martin@example.com","current_password":"Wrong-Password-1"}`, 401, ""},
+		{"email with the current password", "PUT", `{"user_email":"martin@example.com","current_password":"Clinic-Boot-77x"}`, 200, "martin@example.com"},
+		{"password without current password", "PUT", `{"user_email":"martin@example.com","user_password":"Kitten-Shelf-42q"}`, 422, ""},
+		{"weak password", "PUT", `{"user_email":"martin@example.com","user_password":"123","current_password":"Clinic-Boot-77x"}`, 422, ""},
+		{"password with the current password", "PUT", `{"user_email":"martin@example.com","user_password":"Kitten-Shelf-42q","current_password":"Clinic-Boot-77x"}`, 200, "martin@example.com"},
+		{"old password", "PUT", `{"user_email":"vet@example.com","current_password":"Clinic-Boot-77x"}`, 401, ""},
+		{"get updated", "GET", "", 200, "martin@example.com"},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			status, res := call(step.method, step.body)
+			if status != step.status {
+				t.Fatalf("got status %d, want %d", status, step.status)
+			}
+			if step.email != "" && res.Email != step.email {
+				t.Errorf("got email %s, want %s", res.Email, step.email)
+			}
+		})
+	}
+
+	t.Run("sessions after a new password", func(t *testing.T) {
+		if got, _ := postRefreshToken(userConfig.RefreshHandler, other); got != 401 {
+			t.Errorf("other session: got status %d, want 401", got)
+		}
+		if got, _ := postRefreshToken(userConfig.RefreshHandler, current); got != 200 {
+			t.Errorf("current session: got status %d, want 200", got)
+		}
+	})
+}
+
+// The first admin is only created with a strong password on a DB without user
+func TestBootstrapAdmin(t *testing.T) {
+
+	// Config on a new DB, with a user already created or not
+	newConfig := func(withUser bool) *config.Config {
+		configuration := &config.Config{
+			UserEntryRepository: dbmodel.NewUserEntryRepository(databasetest.Open(t)),
+			PasswordService:     testPasswordService(t),
+		}
+		if withUser {
+			if _, err := configuration.UserEntryRepository.Create(&dbmodel.UserEntry{Email: "vet@example.com", Role: "vet"}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return configuration
+	}
+	created := newConfig(false)
+
+	tests := []struct {
+		name     string
+		config   *config.Config
+		email    string
+		password string
+		wantErr  bool
+		admin    bool
+	}{
+		{"users already in the DB", newConfig(true), "admin@example.com", "Clinic-Boot-77x", false, false},
+		{"no credentials", newConfig(false), "", "", false, false},
+		{"weak password", newConfig(false), "admin@example.com", "admin", true, false},
+		{"first admin", created, "admin@example.com", "Clinic-Boot-77x", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := BootstrapAdmin(tt.config, tt.email, tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+
+			admin, err := tt.config.UserEntryRepository.FindByEmail("admin@example.com")
+			if (err == nil) != tt.admin {
+				t.Fatalf("got admin %v, want admin %t", admin, tt.admin)
+			}
+			if !tt.admin {
+				return
+			}
+
+			if valid, _ := tt.config.PasswordService.Verify(admin.Password, tt.password); !valid || admin.Role != "admin" {
+				t.Errorf("got role %s and password valid %t, want admin with the given password", admin.Role, valid)
+			}
+		})
+	}
+
+	t.Run("second start", func(t *testing.T) {
+		if err := BootstrapAdmin(created, "other@example.com", "Clinic-Boot-77x"); err != nil {
+			t.Fatal(err)
+		}
+		if count, _ := created.UserEntryRepository.Count(); count != 1 {
+			t.Errorf("got %d users, want 1", count)
+		}
+	})
+}
@@ -0,0 +1,119 @@
+package user
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"vet-clinic-api/database/dbmodel"
+	"vet-clinic-api/pkg/audit"
+	"vet-clinic-api/pkg/authentication"
+	"vet-clinic-api/pkg/problem"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// UnlockHandler godoc
+// @Summary      Unlock a user
+// @Description  Removes the lock and the failed login counter of a user locked after too many failed logins
+// @Tags         users
+// @Produce      json
+// @Param        id   path      int  true  "User ID"
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]string  "User unlocked successfully"
+// @Failure      400  {object}  problem.Problem  "Invalid request"
+// @Failure      404  {object}  problem.Problem  "User not found"
+// @Failure      500  {object}  problem.Problem  "Failed to unlock user"
+// @Router       /users/{id}/unlock [post]
+func (config *UserConfig) UnlockHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the id in the URL
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		problem.NotFound(w, r, "Missing id")
+		return
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		problem.BadRequest(w, r, "id must be an integer")
+		return
+	}
+
+	// Check if the user existe
+	if _, err := config.UserEntryRepository.FindById(id); err != nil {
+		problem.Error(w, r, err, "Failed to Find specific User")
+		return
+	}
+
+	if err := config.UserEntryRepository.WithContext(audit.ActionContext(r, audit.Unlock)).ResetFailedLogins(id); err != nil {
+		problem.Error(w, r, err, "Failed to Unlock User")
+		return
+	}
+
+	render.JSON(w, r, map[string]string{"message": "User unlocked successfully"})
+}
+
+// Return the remaining lock time of a login attempt, by IP address then by account.
+// Unknown emails are locked in memory the same way as the accounts.
+func (config *UserConfig) loginBlocked(r *http.Request, email string, user *dbmodel.UserEntry) (time.Duration, bool) {
+
+	if wait, blocked := config.LoginIPThrottle.Blocked(authentication.ClientIP(r)); blocked {
+		return wait, true
+	}
+
+	if user == nil {
+		return config.LoginEmailThrottle.Blocked(strings.ToLower(email))
+	}
+
+	if user.LockedUntil != nil {
+		wait := time.Until(*user.LockedUntil)
+		return wait, wait > 0
+	}
+
+	return 0, false
+}
+
+// Count a failed login for the IP address and the account, the account is locked with an exponential backoff
+func (config *UserConfig) recordLoginFailure(r *http.Request, email string, user *dbmodel.UserEntry) {
+
+	config.LoginIPThrottle.Failure(authentication.ClientIP(r))
+
+	if user == nil {
+		config.LoginEmailThrottle.Failure(strings.ToLower(email))
+		return
+	}
+
+	failures, err := config.UserEntryRepository.IncrementFailedLogins(int(user.ID))
+	if err != nil {
+		log.Println("Failed to count the failed login of user", user.ID)
+		return
+	}
+
+	if delay := authentication.BackoffDelay(failures, authentication.AccountFreeAttempts); delay > 0 {
+		if err := config.UserEntryRepository.Lock(int(user.ID), time.Now().Add(delay)); err != nil {
+			log.Println("Failed to lock user", user.ID)
+		}
+	}
+}
+
+// Forget the failed logins of an account once logged in
+func (config *UserConfig) recordLoginSuccess(user *dbmodel.UserEntry) {
+
+	if user.FailedLoginAttempts == 0 && user.LockedUntil == nil {
+		return
+	}
+
+	if err := config.UserEntryRepository.ResetFailedLogins(int(user.ID)); err != nil {
+		log.Println("Failed to reset the failed logins of user", user.ID)
+	}
+}
+
+// Answer a locked login attempt, the same way for an existing account and an unknown email
+func tooManyAttempts(w http.ResponseWriter, r *http.Request, wait time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	problem.TooManyRequests(w, r, "Too many failed login attempts, retry later")
+}
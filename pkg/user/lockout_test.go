@@ -0,0 +1,63 @@
+package user
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"vet-clinic-api/pkg/authentication"
+)
+
+// An account is locked after its free attempts, even for the right password, and an unknown email gets the same answers
+func TestLoginLockout(t *testing.T) {
+
+	userConfig, user := sessionConfig(t)
+	withPassword(t, userConfig, user, "Clinic-Boot-77x")
+	userConfig.LoginIPThrottle = authentication.NewLoginThrottle(authentication.IPFreeAttempts)
+	userConfig.LoginEmailThrottle = authentication.NewLoginThrottle(authentication.AccountFreeAttempts)
+
+	login := func(ip string, email string, password string) (int, string) {
+		r := httptest.NewRequest("POST", "/api/v1/vet/users/login", strings.NewReader(`{"user_email":"`+email+`","user_password":"`+password+`"}`))
+		r.Header.Set("Content-Type", "application/json")
+		r.RemoteAddr = ip + ":1234"
+		w := httptest.NewRecorder()
+		userConfig.LoginHandler(w, r)
+		return w.Code, w.Header().Get("Retry-After")
+	}
+
+	tests := []struct {
+		name     string
+		ip       string
+		email    string
+		password string
+		attempts int
+		want     int
+	}{
+		{"free attempts of an account", "10.0.0.1", "vet@example.com", "Wrong-Password-1", authentication.AccountFreeAttempts, 401},
+		{"account locked for the right password", "10.0.0.2", "vet@example.com", "Clinic-Boot-77x", 1, 429},
+		{"free attempts of an unknown email", "10.0.0.3", "nobody@example.com", "Wrong-Password-1", authentication.AccountFreeAttempts, 401},
+		{"unknown email locked", "10.0.0.4", "nobody@example.com", "Wrong-Password-1", 1, 429},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < tt.attempts; i++ {
+				got, retryAfter := login(tt.ip, tt.email, tt.password)
+				if got != tt.want {
+					t.Fatalf("attempt %d: got status %d, want %d", i+1, got, tt.want)
+				}
+				if got == 429 && retryAfter == "" {
+					t.Error("got no Retry-After header")
+				}
+			}
+		})
+	}
+
+	t.Run("unlocked account", func(t *testing.T) {
+		if err := userConfig.UserEntryRepository.ResetFailedLogins(int(user.ID)); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := login("10.0.0.5", "vet@example.com", "Clinic-Boot-77x"); got != 200 {
+			t.Errorf("got status %d, want 200", got)
+		}
+	})
+}
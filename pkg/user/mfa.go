@@ -0,0 +1,566 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"vet-clinic-api/database/dbmodel"
+	"vet-clinic-api/pkg/audit"
+	"vet-clinic-api/pkg/authentication"
+	"vet-clinic-api/pkg/model"
+	"vet-clinic-api/pkg/problem"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// Lifetime of the token exchanged with a TOTP code after the password check
+const mfaTokenDuration = 5 * time.Minute
+
+// Type of the token exchanged with a TOTP code, refused by the AuthMiddleware
+const mfaTokenType = "mfa_pending"
+
+// Name shown in the authenticator applications
+const mfaIssuer = "Vet Clinic API"
+
+// Number of recovery codes given when the TOTP is enabled
+const recoveryCodeCount = 10
+
+// Token issued after the password check of an account protected by a second factor
+type mfaToken struct {
+	user      *dbmodel.UserEntry
+	jti       string
+	expiresAt time.Time
+}
+
+// LoginMfaHandler godoc
+// @Summary      Complete the login with a TOTP code
+// @Description  Exchanges the mfa token given by /users/login and a TOTP code (or a recovery code) against the tokens. The mfa token can only be used once.
+// @Description  When the enrollment is required, the code confirms the secret given by /users/login/mfa/enroll and the recovery codes are returned with the tokens.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        login body model.MfaLoginRequest true "MFA token and code"
+// @Success      200  {object}  model.TokensResponse
+// @Failure      400  {object}  problem.Problem "Invalid JSON payload"
+// @Failure      401  {object}  problem.Problem "Invalid mfa token or code"
+// @Failure      409  {object}  problem.Problem  "MFA enrollment not started"
+// @Failure      422  {object}  problem.Problem "Validation failed"
+// @Failure      429  {object}  problem.Problem "Too many failed login attempts, retry later"
+// @Failure      500  {object}  problem.Problem "Failed to generate token"
+// @Router       /users/login/mfa [post]
+func (config *UserConfig) LoginMfaHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the request
+	req := &model.MfaLoginRequest{}
+	if err := render.Bind(r, req); err != nil {
+		problem.Bind(w, r, err, "Invalid mfa login request payload")
+		return
+	}
+
+	// Check the mfa token validity and find the user
+	token, err := config.parseMfaToken(*req.Token)
+	if err != nil {
+		problem.Unauthorized(w, r, "Invalid mfa token")
+		return
+	}
+
+	user := token.user
+
+	// Refuse the attempt while the IP address or the account is locked
+	if wait, blocked := config.loginBlocked(r, user.Email, user); blocked {
+		tooManyAttempts(w, r, wait)
+		return
+	}
+
+	if !user.TotpEnabled && user.TotpSecret == "" {
+		problem.Conflict(w, r, "MFA enrollment not started, call /users/login/mfa/enroll first")
+		return
+	}
+
+	// The mfa token is used only once, a wrong code requires to log in again
+	if err := config.RevocationStore.Revoke(token.jti, token.expiresAt); err != nil {
+		problem.Internal(w, r, "Failed to revoke mfa token")
+		return
+	}
+
+	var recoveryCodes []string
+
+	// A wrong code counts as a failed login
+	if user.TotpEnabled {
+		if !config.verifyMfaCode(user, *req.Code) {
+			config.recordLoginFailure(r, user.Email, user)
+			problem.Unauthorized(w, r, "Invalid mfa code")
+			return
+		}
+	} else {
+		recoveryCodes, err = config.confirmEnrollment(r.Context(), user, *req.Code)
+		if err != nil {
+			config.recordLoginFailure(r, user.Email, user)
+			problem.Unauthorized(w, r, "Invalid mfa code")
+			return
+		}
+	}
+
+	// Generate access token and start a new session for a specific user
+	res, err := config.issueTokens(user)
+	if err != nil {
+		problem.Internal(w, r, "Failed to generate token")
+		return
+	}
+
+	res.RecoveryCodes = recoveryCodes
+
+	config.recordLoginSuccess(user)
+
+	render.JSON(w, r, res)
+}
+
+// LoginMfaEnrollHandler godoc
+// @Summary      Start the TOTP enrollment during the login
+// @Description  Generates the TOTP secret of an account whose role requires a second factor, using the mfa token given by /users/login. The enrollment is confirmed with /users/login/mfa.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        login body model.MfaTokenRequest true "MFA token"
+// @Success      200  {object}  model.MfaEnrollResponse
+// @Failure      400  {object}  problem.Problem "Invalid JSON payload"
+// @Failure      401  {object}  problem.Problem "Invalid mfa token"
+// @Failure      409  {object}  problem.Problem  "MFA already enabled"
+// @Failure      422  {object}  problem.Problem "Validation failed"
+// @Failure      500  {object}  problem.Problem "Failed to start the enrollment"
+// @Router       /users/login/mfa/enroll [post]
+func (config *UserConfig) LoginMfaEnrollHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the request
+	req := &model.MfaTokenRequest{}
+	if err := render.Bind(r, req); err != nil {
+		problem.Bind(w, r, err, "Invalid mfa enroll request payload")
+		return
+	}
+
+	// Check the mfa token validity and find the user
+	token, err := config.parseMfaToken(*req.Token)
+	if err != nil {
+		problem.Unauthorized(w, r, "Invalid mfa token")
+		return
+	}
+
+	if token.user.TotpEnabled {
+		problem.Conflict(w, r, "MFA already enabled")
+		return
+	}
+
+	res, err := config.startEnrollment(token.user)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to start the MFA enrollment")
+		return
+	}
+
+	render.JSON(w, r, res)
+}
+
+// EnrollMfaHandler godoc
+// @Summary      Start the TOTP enrollment
+// @Description  Generates a new TOTP secret for the logged-in user and returns it with its otpauth URI. The second factor is enabled once confirmed with /users/me/mfa/confirm.
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  model.MfaEnrollResponse
+// @Failure      401  {object}  problem.Problem  "Invalid token"
+// @Failure      409  {object}  problem.Problem  "MFA already enabled"
+// @Failure      500  {object}  problem.Problem  "Failed to start the enrollment"
+// @Router       /users/me/mfa/enroll [post]
+func (config *UserConfig) EnrollMfaHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Request the DB to get the logged-in user
+	user, err := config.loggedInUser(r)
+	if err != nil {
+		problem.Unauthorized(w, r, "Logged-in user not found")
+		return
+	}
+
+	if user.TotpEnabled {
+		problem.Conflict(w, r, "MFA already enabled")
+		return
+	}
+
+	res, err := config.startEnrollment(user)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to start the MFA enrollment")
+		return
+	}
+
+	render.JSON(w, r, res)
+}
+
+// ConfirmMfaHandler godoc
+// @Summary      Confirm the TOTP enrollment
+// @Description  Enables the second factor of the logged-in user with a code of the authenticator application and returns the recovery codes, they are only shown once.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        mfa  body      model.MfaCodeRequest  true  "TOTP code"
+// @Security     BearerAuth
+// @Success      200  {object}  model.MfaRecoveryCodesResponse
+// @Failure      400  {object}  problem.Problem  "Invalid request payload"
+// @Failure      401  {object}  problem.Problem  "Invalid mfa code"
+// @Failure      409  {object}  problem.Problem  "MFA already enabled or enrollment not started"
+// @Failure      422  {object}  problem.Problem  "Validation failed"
+// @Router       /users/me/mfa/confirm [post]
+func (config *UserConfig) ConfirmMfaHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the request
+	req := &model.MfaCodeRequest{}
+	if err := render.Bind(r, req); err != nil {
+		problem.Bind(w, r, err, "Invalid mfa confirm request payload")
+		return
+	}
+
+	// Request the DB to get the logged-in user
+	user, err := config.loggedInUser(r)
+	if err != nil {
+		problem.Unauthorized(w, r, "Logged-in user not found")
+		return
+	}
+
+	if user.TotpEnabled {
+		problem.Conflict(w, r, "MFA already enabled")
+		return
+	}
+
+	if user.TotpSecret == "" {
+		problem.Conflict(w, r, "MFA enrollment not started, call /users/me/mfa/enroll first")
+		return
+	}
+
+	recoveryCodes, err := config.confirmEnrollment(audit.ActionContext(r, audit.EnableMfa), user, *req.Code)
+	if err != nil {
+		problem.Unauthorized(w, r, "Invalid mfa code")
+		return
+	}
+
+	render.JSON(w, r, &model.MfaRecoveryCodesResponse{RecoveryCodes: recoveryCodes})
+}
+
+// RegenerateRecoveryCodesHandler godoc
+// @Summary      Regenerate the recovery codes
+// @Description  Replaces every recovery code of the logged-in user, a TOTP code is needed
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        mfa  body      model.MfaCodeRequest  true  "TOTP code"
+// @Security     BearerAuth
+// @Success      200  {object}  model.MfaRecoveryCodesResponse
+// @Failure      400  {object}  problem.Problem  "Invalid request payload"
+// @Failure      401  {object}  problem.Problem  "Invalid mfa code"
+// @Failure      409  {object}  problem.Problem  "MFA not enabled"
+// @Failure      422  {object}  problem.Problem  "Validation failed"
+// @Router       /users/me/mfa/recovery-codes [post]
+func (config *UserConfig) RegenerateRecoveryCodesHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the request
+	req := &model.MfaCodeRequest{}
+	if err := render.Bind(r, req); err != nil {
+		problem.Bind(w, r, err, "Invalid recovery codes request payload")
+		return
+	}
+
+	// Request the DB to get the logged-in user
+	user, err := config.loggedInUser(r)
+	if err != nil {
+		problem.Unauthorized(w, r, "Logged-in user not found")
+		return
+	}
+
+	if !user.TotpEnabled {
+		problem.Conflict(w, r, "MFA not enabled")
+		return
+	}
+
+	if !config.verifyTotp(user, *req.Code) {
+		problem.Unauthorized(w, r, "Invalid mfa code")
+		return
+	}
+
+	recoveryCodes, err := config.generateRecoveryCodes(audit.ActionContext(r, audit.RegenerateRecoveryCodes), user.ID)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to generate the recovery codes")
+		return
+	}
+
+	render.JSON(w, r, &model.MfaRecoveryCodesResponse{RecoveryCodes: recoveryCodes})
+}
+
+// DisableMfaHandler godoc
+// @Summary      Disable the TOTP
+// @Description  Disables the second factor of the logged-in user with a TOTP code or a recovery code, not allowed for the roles requiring a second factor
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        mfa  body      model.MfaCodeRequest  true  "TOTP or recovery code"
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]string  "MFA disabled successfully"
+// @Failure      400  {object}  problem.Problem  "Invalid request payload"
+// @Failure      401  {object}  problem.Problem  "Invalid mfa code"
+// @Failure      403  {object}  problem.Problem  "MFA required for the role"
+// @Failure      409  {object}  problem.Problem  "MFA not enabled"
+// @Failure      422  {object}  problem.Problem  "Validation failed"
+// @Router       /users/me/mfa/disable [post]
+func (config *UserConfig) DisableMfaHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the request
+	req := &model.MfaCodeRequest{}
+	if err := render.Bind(r, req); err != nil {
+		problem.Bind(w, r, err, "Invalid mfa disable request payload")
+		return
+	}
+
+	// Request the DB to get the logged-in user
+	user, err := config.loggedInUser(r)
+	if err != nil {
+		problem.Unauthorized(w, r, "Logged-in user not found")
+		return
+	}
+
+	if !user.TotpEnabled {
+		problem.Conflict(w, r, "MFA not enabled")
+		return
+	}
+
+	if config.mfaRequired(user) {
+		problem.Forbidden(w, r, "MFA required for the role "+user.Role)
+		return
+	}
+
+	if !config.verifyMfaCode(user, *req.Code) {
+		problem.Unauthorized(w, r, "Invalid mfa code")
+		return
+	}
+
+	if err := config.clearMfa(audit.ActionContext(r, audit.DisableMfa), user.ID); err != nil {
+		problem.Error(w, r, err, "Failed to disable MFA")
+		return
+	}
+
+	render.JSON(w, r, map[string]string{"message": "MFA disabled successfully"})
+}
+
+// ResetMfaHandler godoc
+// @Summary      Reset the TOTP of a user
+// @Description  Removes the second factor of a user who lost their device and revokes their sessions. A role requiring a second factor has to enroll again at the next login.
+// @Tags         users
+// @Produce      json
+// @Param        id   path      int  true  "User ID"
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]string  "MFA reset successfully"
+// @Failure      400  {object}  problem.Problem  "Invalid request"
+// @Failure      404  {object}  problem.Problem  "User not found"
+// @Failure      500  {object}  problem.Problem  "Failed to reset MFA"
+// @Router       /users/{id}/mfa/reset [post]
+func (config *UserConfig) ResetMfaHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the id in the URL
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		problem.NotFound(w, r, "Missing id")
+		return
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		problem.BadRequest(w, r, "id must be an integer")
+		return
+	}
+
+	// Check if the user existe
+	if _, err := config.UserEntryRepository.FindById(id); err != nil {
+		problem.Error(w, r, err, "Failed to Find specific User")
+		return
+	}
+
+	if err := config.clearMfa(audit.ActionContext(r, audit.ResetMfa), uint(id)); err != nil {
+		problem.Error(w, r, err, "Failed to Reset User MFA")
+		return
+	}
+
+	// The sessions opened with the old second factor are closed
+	if err := config.RefreshTokenEntryRepository.RevokeByUserId(uint(id)); err != nil {
+		problem.Error(w, r, err, "Failed to Revoke User sessions")
+		return
+	}
+
+	if err := config.revokeAccessTokens(uint(id)); err != nil {
+		problem.Error(w, r, err, "Failed to Revoke User tokens")
+		return
+	}
+
+	render.JSON(w, r, map[string]string{"message": "MFA reset successfully"})
+}
+
+// Check if the role of the user can't log in with a password alone
+func (config *UserConfig) mfaRequired(user *dbmodel.UserEntry) bool {
+	return slices.Contains(config.MfaRequiredRoles, user.Role)
+}
+
+// Generate the short-lived token exchanged with a TOTP code on /users/login/mfa
+func (config *UserConfig) generateMfaToken(user *dbmodel.UserEntry) (string, error) {
+	return authentication.GenerateToken(config.AccessTokenKeys,
+		map[string]interface{}{
+			"user_id": user.ID,
+			"typ":     mfaTokenType},
+		mfaTokenDuration)
+}
+
+// Check the mfa token signature, type and revocation and find its user
+func (config *UserConfig) parseMfaToken(tokenString string) (*mfaToken, error) {
+
+	claims, err := authentication.ParseTokenClaims(config.AccessTokenKeys, tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if typ, _ := claims["typ"].(string); typ != mfaTokenType {
+		return nil, errors.New("not an mfa token")
+	}
+
+	jti, _ := claims["jti"].(string)
+	userId, _ := claims["user_id"].(float64)
+	issuedAt, _ := claims["iat"].(float64)
+	expiresAt, _ := claims["exp"].(float64)
+
+	if jti == "" || config.RevocationStore.IsRevoked(jti, uint(userId), time.UnixMilli(int64(issuedAt*1000))) {
+		return nil, errors.New("revoked mfa token")
+	}
+
+	user, err := config.UserEntryRepository.FindById(int(userId))
+	if err != nil {
+		return nil, err
+	}
+
+	return &mfaToken{user: user, jti: jti, expiresAt: time.Unix(int64(expiresAt), 0)}, nil
+}
+
+// Request the DB to get the user linked to the access token
+func (config *UserConfig) loggedInUser(r *http.Request) (*dbmodel.UserEntry, error) {
+
+	principal, ok := authentication.FromContext(r.Context())
+	if !ok {
+		return nil, errors.New("principal not found in the request context")
+	}
+
+	return config.UserEntryRepository.FindById(int(principal.UserId))
+}
+
+// Generate and save a new TOTP secret, not enabled until confirmed with a code
+func (config *UserConfig) startEnrollment(user *dbmodel.UserEntry) (*model.MfaEnrollResponse, error) {
+
+	secret, err := authentication.GenerateTotpSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := config.UserEntryRepository.UpdateTotp(int(user.ID), secret, false); err != nil {
+		return nil, err
+	}
+
+	return &model.MfaEnrollResponse{
+		Secret:     secret,
+		OtpauthURI: authentication.TotpURI(mfaIssuer, user.Email, secret)}, nil
+}
+
+// Enable the TOTP when the code matches the pending secret and return new recovery codes
+func (config *UserConfig) confirmEnrollment(ctx context.Context, user *dbmodel.UserEntry, code string) ([]string, error) {
+
+	if !config.verifyTotp(user, code) {
+		return nil, errors.New("invalid mfa code")
+	}
+
+	if err := config.UserEntryRepository.WithContext(ctx).UpdateTotp(int(user.ID), user.TotpSecret, true); err != nil {
+		return nil, err
+	}
+
+	// The first codes are part of the enrollment, only the user change is recorded in the audit log
+	return config.generateRecoveryCodes(context.Background(), user.ID)
+}
+
+// Check a TOTP code, each code can be used only once
+func (config *UserConfig) verifyTotp(user *dbmodel.UserEntry, code string) bool {
+
+	step, ok := authentication.ValidateTotp(user.TotpSecret, strings.TrimSpace(code), time.Now())
+	if !ok {
+		return false
+	}
+
+	used, err := config.UserEntryRepository.UseTotpStep(int(user.ID), step)
+	if err != nil || !used {
+		return false
+	}
+
+	return true
+}
+
+// Check a TOTP code or else a recovery code, a recovery code can be used only once
+func (config *UserConfig) verifyMfaCode(user *dbmodel.UserEntry, code string) bool {
+
+	if config.verifyTotp(user, code) {
+		return true
+	}
+
+	used, err := config.RecoveryCodeEntryRepository.UseCode(user.ID, hashRecoveryCode(code))
+
+	return err == nil && used
+}
+
+// Generate new recovery codes for a user, only their hash is saved
+func (config *UserConfig) generateRecoveryCodes(ctx context.Context, userId uint) ([]string, error) {
+
+	var codes []string
+	var codeHashes []string
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		bytes := make([]byte, 6)
+		if _, err := rand.Read(bytes); err != nil {
+			return nil, err
+		}
+
+		code := strings.ToLower(base32.StdEncoding.EncodeToString(bytes))[:10]
+		code = code[:5] + "-" + code[5:]
+
+		codes = append(codes, code)
+		codeHashes = append(codeHashes, hashRecoveryCode(code))
+	}
+
+	if err := config.RecoveryCodeEntryRepository.WithContext(ctx).ReplaceByUserId(userId, codeHashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Remove the TOTP secret and the recovery codes of a user
+func (config *UserConfig) clearMfa(ctx context.Context, userId uint) error {
+
+	if err := config.UserEntryRepository.WithContext(ctx).UpdateTotp(int(userId), "", false); err != nil {
+		return err
+	}
+
+	return config.RecoveryCodeEntryRepository.DeleteByUserId(userId)
+}
+
+// Hash a recovery code, the dash and the case are ignored
+func hashRecoveryCode(code string) string {
+
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(code))
+
+	return hex.EncodeToString(sum[:])
+}
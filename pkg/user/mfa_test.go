@@ -0,0 +1,101 @@
+package user
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"vet-clinic-api/database/dbmodel"
+	"vet-clinic-api/pkg/authentication"
+)
+
+// Current code of a secret, computed like an authenticator application
+func currentTotpCode(t *testing.T, secret string) string {
+
+	t.Helper()
+
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := make([]byte, 8)
+	binary.BigEndian.PutUint64(message, uint64(time.Now().Unix()/30))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(message)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	return fmt.Sprintf("%06d", (binary.BigEndian.Uint32(sum[offset:offset+4])&0x7fffffff)%1000000)
+}
+
+func TestVerifyMfaCode(t *testing.T) {
+
+	userConfig, user := sessionConfig(t)
+
+	secret, err := authentication.GenerateTotpSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := userConfig.UserEntryRepository.UpdateTotp(int(user.ID), secret, true); err != nil {
+		t.Fatal(err)
+	}
+	user.TotpSecret = secret
+
+	codes, err := userConfig.generateRecoveryCodes(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != recoveryCodeCount {
+		t.Fatalf("got %d recovery codes, want %d", len(codes), recoveryCodeCount)
+	}
+
+	other, err := userConfig.UserEntryRepository.Create(&dbmodel.UserEntry{Email: "other@example.com", Role: "vet"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	totpCode := currentTotpCode(t, secret)
+
+	// The steps share the DB, a code used by a step is refused by the next ones
+	steps := []struct {
+		name string
+		user *dbmodel.UserEntry
+		code string
+		want bool
+	}{
+		{"TOTP code", user, totpCode, true},
+		{"TOTP code used again", user, totpCode, false},
+		{"recovery code of another user", other, codes[0], false},
+		{"recovery code", user, codes[0], true},
+		{"recovery code used again", user, codes[0], false},
+		{"recovery code without dash in uppercase", user, strings.ToUpper(strings.ReplaceAll(codes[1], "-", "")), true},
+		{"unknown code", user, "aaaaa-bbbbb", false},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			if got := userConfig.verifyMfaCode(step.user, step.code); got != step.want {
+				t.Errorf("got %t, want %t", got, step.want)
+			}
+		})
+	}
+
+	t.Run("regenerated codes replace the old ones", func(t *testing.T) {
+		newCodes, err := userConfig.generateRecoveryCodes(context.Background(), user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if userConfig.verifyMfaCode(user, codes[2]) {
+			t.Error("old recovery code accepted")
+		}
+		if !userConfig.verifyMfaCode(user, newCodes[0]) {
+			t.Error("new recovery code refused")
+		}
+	})
+}
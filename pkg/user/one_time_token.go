@@ -0,0 +1,330 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"vet-clinic-api/database/dbmodel"
+	"vet-clinic-api/pkg/audit"
+	"vet-clinic-api/pkg/authentication"
+	"vet-clinic-api/pkg/model"
+	"vet-clinic-api/pkg/problem"
+	"vet-clinic-api/pkg/validation"
+
+	"github.com/go-chi/render"
+)
+
+// Lifetime of the password reset links
+const passwordResetTokenDuration = time.Hour
+
+// Lifetime of the invitation links
+const invitationTokenDuration = 72 * time.Hour
+
+// RequestPasswordResetHandler godoc
+// @Summary      Request a password reset
+// @Description  Sends a single-use link to reset the password by email. The answer is the same whether the email exists or not.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        reset body model.PasswordResetRequest true "User email"
+// @Success      200  {object}  map[string]string "Password reset email sent if the user exists"
+// @Failure      400  {object}  problem.Problem "Invalid JSON payload"
+// @Failure      422  {object}  problem.Problem "Validation failed"
+// @Failure      429  {object}  problem.Problem "Too many password reset requests, retry later"
+// @Router       /users/password-reset/request [post]
+func (config *UserConfig) RequestPasswordResetHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the request
+	req := &model.PasswordResetRequest{}
+	if err := render.Bind(r, req); err != nil {
+		problem.Bind(w, r, err, "Invalid password reset request payload")
+		return
+	}
+
+	// Every request counts as an attempt of the IP address and of the email, existing or not, to limit the emails sent.
+	// The requests have their own throttles, so they don't lock the logins from the same IP address.
+	ip, email := authentication.ClientIP(r), strings.ToLower(*req.Email)
+	if wait, blocked := config.PasswordResetIPThrottle.Blocked(ip); blocked {
+		tooManyAttempts(w, r, wait)
+		return
+	}
+	if wait, blocked := config.PasswordResetEmailThrottle.Blocked(email); blocked {
+		tooManyAttempts(w, r, wait)
+		return
+	}
+	config.PasswordResetIPThrottle.Failure(ip)
+	config.PasswordResetEmailThrottle.Failure(email)
+
+	// The email is only sent to an existing user, in background to answer as fast as for an unknown email
+	if user, err := config.UserEntryRepository.FindByEmail(*req.Email); err == nil {
+		go func() {
+			if err := config.sendOneTimeToken(user, dbmodel.PasswordResetToken, passwordResetTokenDuration); err != nil {
+				log.Println("Failed to send the password reset email of user", user.ID, err)
+			}
+		}()
+	}
+
+	render.JSON(w, r, map[string]string{"message": "If the email exists, a password reset link has been sent"})
+}
+
+// ConfirmPasswordResetHandler godoc
+// @Summary      Reset the password
+// @Description  Sets a new password with the token received by email. The token can only be used once and every session of the user is revoked.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        reset body model.TokenPasswordRequest true "Token and new password"
+// @Success      200  {object}  map[string]string "Password reset successfully"
+// @Failure      400  {object}  problem.Problem "Invalid JSON payload or password too weak"
+// @Failure      401  {object}  problem.Problem "Invalid or expired token"
+// @Failure      422  {object}  problem.Problem "Validation failed"
+// @Failure      500  {object}  problem.Problem "Failed to reset the password"
+// @Router       /users/password-reset/confirm [post]
+func (config *UserConfig) ConfirmPasswordResetHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the request
+	req := &model.TokenPasswordRequest{}
+	if err := render.Bind(r, req); err != nil {
+		problem.Bind(w, r, err, "Invalid password reset confirm request payload")
+		return
+	}
+
+	// Check the token and find its user
+	user, err := config.findOneTimeTokenUser(*req.Token, dbmodel.PasswordResetToken)
+	if err != nil {
+		problem.Unauthorized(w, r, "Invalid or expired token")
+		return
+	}
+
+	// The token stays valid until a strong enough password is given
+	if err := config.PasswordService.Validate(*req.Password, user.Email); err != nil {
+		problem.Fields(w, r, "Invalid password", validation.Errors{{Path: "user_password", Code: validation.CodePassword, Message: err.Error()}})
+		return
+	}
+
+	if err := config.consumeOneTimeToken(*req.Token, dbmodel.PasswordResetToken); err != nil {
+		problem.Unauthorized(w, r, "Invalid or expired token")
+		return
+	}
+
+	if err := config.setPassword(audit.ActionContext(r, audit.ResetPassword), user, *req.Password); err != nil {
+		problem.Error(w, r, err, "Failed to Reset User password")
+		return
+	}
+
+	// The sessions opened with the old password are closed
+	if err := config.RefreshTokenEntryRepository.RevokeByUserId(user.ID); err != nil {
+		problem.Error(w, r, err, "Failed to Revoke User sessions")
+		return
+	}
+
+	if err := config.revokeAccessTokens(user.ID); err != nil {
+		problem.Error(w, r, err, "Failed to Revoke User tokens")
+		return
+	}
+
+	render.JSON(w, r, map[string]string{"message": "Password reset successfully"})
+}
+
+// InviteHandler godoc
+// @Summary      Invite a new User
+// @Description  Creates a user without password and sends a single-use invitation link by email to choose it. Inviting again a user who didn't accept yet sends a new link.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        user  body      model.UserInviteRequest  true  "User invitation payload"
+// @Security     BearerAuth
+// @Success      200  {object}  model.UserResponse
+// @Failure      400  {object}  problem.Problem  "Invalid User Invite request payload"
+// @Failure      409  {object}  problem.Problem  "A User already exists with this email"
+// @Failure      422  {object}  problem.Problem  "Validation failed"
+// @Failure      500  {object}  problem.Problem  "Failed to send the invitation"
+// @Router       /users/invite [post]
+func (config *UserConfig) InviteHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the request
+	req := &model.UserInviteRequest{}
+	if err := render.Bind(r, req); err != nil {
+		problem.Bind(w, r, err, "Invalid User Invite request payload")
+		return
+	}
+
+	// Check if the requested role existe
+	if _, err := config.RoleEntryRepository.FindByName(*req.Role); err != nil {
+		problem.Reference(w, r, err, "Role not found in the DB")
+		return
+	}
+
+	// Check if the linked owner id existe
+	if req.OwnerId != nil && !config.OwnerEntryRepository.FindLastOwnerId(int(*req.OwnerId)) {
+		problem.InvalidReference(w, r, "OwnerId not found in the DB")
+		return
+	}
+
+	// A user who already chose a password can't be invited again
+	entries, err := config.UserEntryRepository.FindByEmail(*req.Email)
+	if err == nil && entries.Password != "" {
+		problem.AlreadyExists(w, r, "A User already exists with this email")
+		return
+	}
+
+	// Request the DB to Create the user without password
+	if err != nil {
+		entries, err = config.UserEntryRepository.WithContext(audit.ActionContext(r, audit.Invite)).Create(&dbmodel.UserEntry{
+			Email:   *req.Email,
+			Role:    *req.Role,
+			OwnerId: req.OwnerId,
+		})
+		if err != nil {
+			problem.Error(w, r, err, "Failed to Create specific User")
+			return
+		}
+	}
+
+	if err := config.sendOneTimeToken(entries, dbmodel.InvitationToken, invitationTokenDuration); err != nil {
+		problem.Error(w, r, err, "Failed to send the invitation email")
+		return
+	}
+
+	// Set up to a dedicated type for the response
+	res := userResponse(entries)
+
+	render.JSON(w, r, res)
+}
+
+// AcceptInvitationHandler godoc
+// @Summary      Accept an invitation
+// @Description  Chooses the password of an invited user with the token received by email, the token can only be used once
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        invitation body model.TokenPasswordRequest true "Token and password"
+// @Success      200  {object}  map[string]string "Invitation accepted successfully"
+// @Failure      400  {object}  problem.Problem "Invalid JSON payload or password too weak"
+// @Failure      401  {object}  problem.Problem "Invalid or expired token"
+// @Failure      422  {object}  problem.Problem "Validation failed"
+// @Failure      500  {object}  problem.Problem "Failed to set the password"
+// @Router       /users/invite/accept [post]
+func (config *UserConfig) AcceptInvitationHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the request
+	req := &model.TokenPasswordRequest{}
+	if err := render.Bind(r, req); err != nil {
+		problem.Bind(w, r, err, "Invalid invitation accept request payload")
+		return
+	}
+
+	// Check the token and find its user
+	user, err := config.findOneTimeTokenUser(*req.Token, dbmodel.InvitationToken)
+	if err != nil {
+		problem.Unauthorized(w, r, "Invalid or expired token")
+		return
+	}
+
+	// The token stays valid until a strong enough password is given
+	if err := config.PasswordService.Validate(*req.Password, user.Email); err != nil {
+		problem.Fields(w, r, "Invalid password", validation.Errors{{Path: "user_password", Code: validation.CodePassword, Message: err.Error()}})
+		return
+	}
+
+	if err := config.consumeOneTimeToken(*req.Token, dbmodel.InvitationToken); err != nil {
+		problem.Unauthorized(w, r, "Invalid or expired token")
+		return
+	}
+
+	if err := config.setPassword(audit.ActionContext(r, audit.AcceptInvitation), user, *req.Password); err != nil {
+		problem.Error(w, r, err, "Failed to Set User password")
+		return
+	}
+
+	render.JSON(w, r, map[string]string{"message": "Invitation accepted successfully"})
+}
+
+// Create a single-use token for a user, replacing the previous ones, and send its link by email
+func (config *UserConfig) sendOneTimeToken(user *dbmodel.UserEntry, purpose string, duration time.Duration) error {
+
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return err
+	}
+	token := hex.EncodeToString(bytes)
+
+	if err := config.OneTimeTokenEntryRepository.DeleteByUserId(user.ID, purpose); err != nil {
+		return err
+	}
+
+	if _, err := config.OneTimeTokenEntryRepository.Create(&dbmodel.OneTimeTokenEntry{
+		TokenHash: hashOneTimeToken(token),
+		Purpose:   purpose,
+		UserId:    user.ID,
+		ExpiresAt: time.Now().Add(duration),
+	}); err != nil {
+		return err
+	}
+
+	// Email content by purpose
+	validity := fmt.Sprintf("%.0f hours", duration.Hours())
+	if purpose == dbmodel.InvitationToken {
+		link := config.AppURL + "/accept-invitation?token=" + url.QueryEscape(token)
+		return config.Mailer.Send(user.Email, "Invitation to the veterinary clinic",
+			"You have been invited to the veterinary clinic.\n\n"+
+				"Choose your password with this link, valid for "+validity+":\n"+link+"\n\n"+
+				"Or send this token to POST /api/v1/vet/users/invite/accept:\n"+token+"\n")
+	}
+
+	link := config.AppURL + "/reset-password?token=" + url.QueryEscape(token)
+	return config.Mailer.Send(user.Email, "Password reset",
+		"A password reset has been requested for your account.\n\n"+
+			"Choose a new password with this link, valid for "+validity+":\n"+link+"\n\n"+
+			"Or send this token to POST /api/v1/vet/users/password-reset/confirm:\n"+token+"\n\n"+
+			"If you didn't request it, you can ignore this email.\n")
+}
+
+// Find the user of a valid token
+func (config *UserConfig) findOneTimeTokenUser(token string, purpose string) (*dbmodel.UserEntry, error) {
+
+	entry, err := config.OneTimeTokenEntryRepository.FindValid(hashOneTimeToken(token), purpose)
+	if err != nil {
+		return nil, err
+	}
+
+	return config.UserEntryRepository.FindById(int(entry.UserId))
+}
+
+// Mark a token as used, it fails if the token has been used in the meantime
+func (config *UserConfig) consumeOneTimeToken(token string, purpose string) error {
+
+	_, err := config.OneTimeTokenEntryRepository.Consume(hashOneTimeToken(token), purpose)
+
+	return err
+}
+
+// Hash and save a new password, the failed logins of the user are forgotten
+func (config *UserConfig) setPassword(ctx context.Context, user *dbmodel.UserEntry, password string) error {
+
+	hashedPassword, err := config.PasswordService.Hash(password)
+	if err != nil {
+		return err
+	}
+
+	return config.UserEntryRepository.WithContext(ctx).Patch(int(user.ID), map[string]interface{}{
+		"password":              hashedPassword,
+		"failed_login_attempts": 0,
+		"locked_until":          nil,
+	})
+}
+
+// Hash of a one-time token, the token itself is only known by the email recipient
+func hashOneTimeToken(token string) string {
+
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
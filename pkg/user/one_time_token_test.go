@@ -0,0 +1,66 @@
+package user
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"vet-clinic-api/config"
+	"vet-clinic-api/database/databasetest"
+	"vet-clinic-api/database/dbmodel"
+	"vet-clinic-api/pkg/authentication"
+)
+
+// Every reset request is counted, for an unknown email too, so the answers can't reveal which emails exist
+func TestRequestPasswordResetThrottle(t *testing.T) {
+
+	userConfig := &UserConfig{&config.Config{
+		UserEntryRepository:        dbmodel.NewUserEntryRepository(databasetest.Open(t)),
+		LoginIPThrottle:            authentication.NewLoginThrottle(authentication.IPFreeAttempts),
+		PasswordResetIPThrottle:    authentication.NewLoginThrottle(authentication.IPFreeAttempts),
+		PasswordResetEmailThrottle: authentication.NewLoginThrottle(authentication.AccountFreeAttempts),
+	}}
+
+	request := func(ip string, email string) int {
+		r := httptest.NewRequest("POST", "/api/v1/vet/users/password-reset/request", strings.NewReader(`{"user_email":"`+email+`"}`))
+		r.Header.Set("Content-Type", "application/json")
+		r.RemoteAddr = ip + ":1234"
+		w := httptest.NewRecorder()
+		userConfig.RequestPasswordResetHandler(w, r)
+		return w.Code
+	}
+
+	tests := []struct {
+		name     string
+		ip       string
+		email    string
+		requests int
+		want     int
+	}{
+		{"free requests of an email", "10.0.0.1", "nobody@example.com", authentication.AccountFreeAttempts, 200},
+		{"email blocked whatever its case", "10.0.0.2", "Nobody@Example.com", 1, 429},
+		{"other email", "10.0.0.2", "other@example.com", 1, 200},
+		{"free requests of an IP address", "10.0.0.3", "", authentication.IPFreeAttempts, 200},
+		{"IP address blocked", "10.0.0.3", "last@example.com", 1, 429},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < tt.requests; i++ {
+				email := tt.email
+				if email == "" {
+					email = "user" + strings.Repeat("x", i) + "@example.com"
+				}
+
+				if got := request(tt.ip, email); got != tt.want {
+					t.Fatalf("request %d: got status %d, want %d", i+1, got, tt.want)
+				}
+			}
+		})
+	}
+
+	t.Run("logins of a blocked IP address", func(t *testing.T) {
+		if _, blocked := userConfig.LoginIPThrottle.Blocked("10.0.0.3"); blocked {
+			t.Error("got the logins blocked, want them allowed")
+		}
+	})
+}
@@ -2,6 +2,7 @@ package user
 
 import (
 	"vet-clinic-api/config"
+	"vet-clinic-api/pkg/authentication"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -12,14 +13,58 @@ func Routes(configuration *config.Config) chi.Router {
 	userConfig := New(configuration)
 	router := chi.NewRouter()
 
-	// Routes definition
+	// Public routes used to get the tokens
 	router.Post("/login", userConfig.LoginHandler)
+	router.Post("/login/mfa", userConfig.LoginMfaHandler)
+	router.Post("/login/mfa/enroll", userConfig.LoginMfaEnrollHandler)
 	router.Post("/refresh", userConfig.RefreshHandler)
-	router.Get("/{id}", userConfig.GetByIdHandler)
-	router.Get("/", userConfig.GetAllHandler)
-	router.Post("/", userConfig.PostHandler)
-	router.Put("/{id}", userConfig.UpdateHandler)
-	router.Delete("/{id}", userConfig.DeleteHandler)
+	router.Post("/logout", userConfig.LogoutHandler)
+	router.Post("/password-reset/request", userConfig.RequestPasswordResetHandler)
+	router.Post("/password-reset/confirm", userConfig.ConfirmPasswordResetHandler)
+	router.Post("/invite/accept", userConfig.AcceptInvitationHandler)
+
+	// Routes protected by authentication
+	router.Group(func(router chi.Router) {
+		router.Use(authentication.AuthMiddleware(userConfig.AccessTokenKeys, userConfig.RevocationStore, userConfig.ApiKeyStore, userConfig.RoleEntryRepository))
+
+		router.Get("/me", userConfig.GetMeHandler)
+
+		// Routes changing the credentials of the account, not accessible with an API key
+		router.With(authentication.RequireAccessToken).Group(func(r chi.Router) {
+			r.Put("/me", userConfig.UpdateMeHandler)
+			r.Post("/me/mfa/enroll", userConfig.EnrollMfaHandler)
+			r.Post("/me/mfa/confirm", userConfig.ConfirmMfaHandler)
+			r.Post("/me/mfa/recovery-codes", userConfig.RegenerateRecoveryCodesHandler)
+			r.Post("/me/mfa/disable", userConfig.DisableMfaHandler)
+		})
+
+		// Routes protected by authentication and accessible with the "users:read" permission
+		router.With(authentication.RequirePermission("users:read")).Group(func(r chi.Router) {
+			r.Get("/{id}", userConfig.GetByIdHandler)
+			r.Get("/", userConfig.GetAllHandler)
+		})
+
+		// Routes protected by authentication and accessible with the "users:write" permission
+		router.With(authentication.RequirePermission("users:write")).Group(func(r chi.Router) {
+			r.Post("/", userConfig.PostHandler)
+			r.Post("/invite", userConfig.InviteHandler)
+			r.Put("/{id}", userConfig.UpdateHandler)
+			r.Patch("/{id}", userConfig.PatchHandler)
+			r.Delete("/{id}", userConfig.DeleteHandler)
+			r.Post("/{id}/sessions/revoke", userConfig.RevokeSessionsHandler)
+			r.Post("/{id}/mfa/reset", userConfig.ResetMfaHandler)
+			r.Post("/{id}/unlock", userConfig.UnlockHandler)
+		})
+
+		// API keys management, an API key can't be used to list or create other keys
+		router.With(authentication.RequireAccessToken).Group(func(router chi.Router) {
+			router.With(authentication.RequirePermission("users:read")).Get("/{id}/api-keys", userConfig.GetApiKeysHandler)
+			router.With(authentication.RequirePermission("users:write")).Group(func(r chi.Router) {
+				r.Post("/{id}/api-keys", userConfig.CreateApiKeyHandler)
+				r.Delete("/{id}/api-keys/{keyId}", userConfig.DeleteApiKeyHandler)
+			})
+		})
+	})
 
 	return router
 }
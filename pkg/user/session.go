@@ -0,0 +1,212 @@
+package user
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"vet-clinic-api/database/dbmodel"
+	"vet-clinic-api/pkg/audit"
+	"vet-clinic-api/pkg/authentication"
+	"vet-clinic-api/pkg/model"
+	"vet-clinic-api/pkg/problem"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// Lifetime of the access tokens
+const accessTokenDuration = 2 * time.Hour
+
+// Lifetime of the refresh tokens
+const refreshTokenDuration = 7 * 24 * time.Hour
+
+// LogoutHandler godoc
+// @Summary      Logout
+// @Description  Revokes the session of the given refresh token, every refresh token of the session becomes invalid. The access token sent in the Authorization header, if any, is revoked too.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        refresh body model.RefreshTokenRequest true "Refresh token payload"
+// @Success      200  {object}  map[string]string "Logged out successfully"
+// @Failure      400  {object}  problem.Problem "Invalid JSON payload"
+// @Failure      401  {object}  problem.Problem "Invalid refresh token"
+// @Failure      422  {object}  problem.Problem "Validation failed"
+// @Failure      500  {object}  problem.Problem "Failed to revoke session"
+// @Router       /users/logout [post]
+func (config *UserConfig) LogoutHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the request
+	req := &model.RefreshTokenRequest{}
+	if err := render.Bind(r, req); err != nil {
+		problem.Bind(w, r, err, "Invalid logout request payload")
+		return
+	}
+
+	// Check the refresh token validity and find the issued token in the DB
+	entry, err := config.findRefreshToken(*req.RefreshToken)
+	if err != nil {
+		problem.Unauthorized(w, r, "Invalid refresh token")
+		return
+	}
+
+	// Request the DB to revoke the whole session
+	if err := config.RefreshTokenEntryRepository.RevokeFamily(entry.FamilyId); err != nil {
+		problem.Internal(w, r, "Failed to revoke session")
+		return
+	}
+
+	if err := config.revokeRequestAccessToken(r, entry.UserId); err != nil {
+		problem.Internal(w, r, "Failed to revoke access token")
+		return
+	}
+
+	render.JSON(w, r, map[string]string{"message": "Logged out successfully"})
+}
+
+// RevokeSessionsHandler godoc
+// @Summary      Revoke the sessions of a user
+// @Description  Revokes every refresh token and access token issued to a specific user, the user has to log in again
+// @Tags         users
+// @Produce      json
+// @Param        id   path      int  true  "User ID"
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]string  "Sessions revoked successfully"
+// @Failure      400  {object}  problem.Problem  "Invalid request"
+// @Failure      404  {object}  problem.Problem  "User not found"
+// @Failure      500  {object}  problem.Problem  "Failed to revoke sessions"
+// @Router       /users/{id}/sessions/revoke [post]
+func (config *UserConfig) RevokeSessionsHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the id in the URL
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		problem.NotFound(w, r, "Missing id")
+		return
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		problem.BadRequest(w, r, "id must be an integer")
+		return
+	}
+
+	// Check if the user existe
+	if _, err := config.UserEntryRepository.FindById(id); err != nil {
+		problem.Error(w, r, err, "Failed to Find specific User")
+		return
+	}
+
+	// Request the DB to revoke every session of the user
+	if err := config.RefreshTokenEntryRepository.WithContext(audit.ActionContext(r, audit.RevokeSessions)).RevokeByUserId(uint(id)); err != nil {
+		problem.Error(w, r, err, "Failed to Revoke User sessions")
+		return
+	}
+
+	if err := config.revokeAccessTokens(uint(id)); err != nil {
+		problem.Error(w, r, err, "Failed to Revoke User tokens")
+		return
+	}
+
+	render.JSON(w, r, map[string]string{"message": "Sessions revoked successfully"})
+}
+
+// Generate an access token and a refresh token in a new session for a specific user
+func (config *UserConfig) issueTokens(user *dbmodel.UserEntry) (*model.TokensResponse, error) {
+
+	familyId, err := authentication.GenerateTokenId()
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := authentication.GenerateToken(config.AccessTokenKeys, accessClaims(user, familyId), accessTokenDuration)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := config.generateRefreshToken(user, familyId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TokensResponse{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
+
+// Generate a refresh token for a specific user in a session and save it in the DB
+func (config *UserConfig) generateRefreshToken(user *dbmodel.UserEntry, familyId string) (string, error) {
+
+	jti, err := authentication.GenerateTokenId()
+	if err != nil {
+		return "", err
+	}
+
+	refreshToken, err := authentication.GenerateToken(config.RefreshTokenKeys,
+		map[string]interface{}{
+			"user_id": user.ID,
+			"jti":     jti,
+			"family":  familyId},
+		refreshTokenDuration)
+
+	if err != nil {
+		return "", err
+	}
+
+	// Keep track of the issued token to allow the rotation and the revocation
+	if _, err := config.RefreshTokenEntryRepository.Create(&dbmodel.RefreshTokenEntry{
+		Jti:       jti,
+		FamilyId:  familyId,
+		UserId:    user.ID,
+		ExpiresAt: time.Now().Add(refreshTokenDuration),
+	}); err != nil {
+		return "", err
+	}
+
+	return refreshToken, nil
+}
+
+// Check the refresh token signature and return the issued token saved in the DB
+func (config *UserConfig) findRefreshToken(refreshToken string) (*dbmodel.RefreshTokenEntry, error) {
+
+	claims, err := authentication.ParseTokenClaims(config.RefreshTokenKeys, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	jti, ok := claims["jti"].(string)
+	if !ok || jti == "" {
+		return nil, errors.New("jti claim not found or invalid")
+	}
+
+	return config.RefreshTokenEntryRepository.FindByJti(jti)
+}
+
+// Revoke the access token sent with the request until it expires, when it belongs to the user.
+// The logout works without it, so a missing or invalid access token is ignored.
+func (config *UserConfig) revokeRequestAccessToken(r *http.Request, userId uint) error {
+
+	authHeader := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		return nil
+	}
+
+	claims, err := authentication.ParseTokenClaims(config.AccessTokenKeys, authHeader)
+	if err != nil {
+		return nil
+	}
+
+	jti, _ := claims["jti"].(string)
+	tokenUserId, _ := claims["user_id"].(float64)
+	expiresAt, _ := claims["exp"].(float64)
+
+	if jti == "" || uint(tokenUserId) != userId {
+		return nil
+	}
+
+	return config.RevocationStore.Revoke(jti, time.Unix(int64(expiresAt), 0))
+}
+
+// Revoke every access token issued to a user until now
+func (config *UserConfig) revokeAccessTokens(userId uint) error {
+	return config.RevocationStore.RevokeUser(userId, time.Now().Add(accessTokenDuration))
+}
@@ -0,0 +1,205 @@
+package user
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"vet-clinic-api/config"
+	"vet-clinic-api/database/databasetest"
+	"vet-clinic-api/database/dbmodel"
+	"vet-clinic-api/pkg/authentication"
+	"vet-clinic-api/pkg/model"
+	"vet-clinic-api/pkg/password"
+)
+
+// Config of the session and second factor handlers on a new DB, with a user to log in
+func sessionConfig(t *testing.T) (*UserConfig, *dbmodel.UserEntry) {
+
+	t.Helper()
+
+	db := databasetest.Open(t)
+	userConfig := &UserConfig{&config.Config{
+		UserEntryRepository:         dbmodel.NewUserEntryRepository(db),
+		RefreshTokenEntryRepository: dbmodel.NewRefreshTokenEntryRepository(db),
+		RecoveryCodeEntryRepository: dbmodel.NewRecoveryCodeEntryRepository(db),
+		AuditLogEntryRepository:     dbmodel.NewAuditLogEntryRepository(db),
+		RevocationStore:             authentication.NewMemoryRevocationStore(),
+		AccessTokenKeys:             authentication.NewHMACKeySet("access secret"),
+		RefreshTokenKeys:            authentication.NewHMACKeySet("refresh secret"),
+	}}
+
+	user, err := userConfig.UserEntryRepository.Create(&dbmodel.UserEntry{Email: "vet@example.com", Role: "vet"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return userConfig, user
+}
+
+// Password service fast enough for the tests
+func testPasswordService(t *testing.T) *password.Service {
+
+	t.Helper()
+
+	params := password.DefaultParams()
+	params.Iterations, params.Memory = 1, 1024
+	passwordService, err := password.New(params, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return passwordService
+}
+
+// Give a password to the user, hashed with the password service of the tests
+func withPassword(t *testing.T, userConfig *UserConfig, user *dbmodel.UserEntry, plain string) {
+
+	t.Helper()
+
+	userConfig.PasswordService = testPasswordService(t)
+
+	hash, err := userConfig.PasswordService.Hash(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := userConfig.UserEntryRepository.UpdatePassword(int(user.ID), hash); err != nil {
+		t.Fatal(err)
+	}
+	user.Password = hash
+}
+
+// Start a new session of the user like the login, and return its refresh token
+func newSession(t *testing.T, userConfig *UserConfig, user *dbmodel.UserEntry) string {
+
+	t.Helper()
+
+	familyId, err := authentication.GenerateTokenId()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refreshToken, err := userConfig.generateRefreshToken(user, familyId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return refreshToken
+}
+
+// Call a handler taking a refresh token, and return the status with the new tokens
+func postRefreshToken(handler func(w http.ResponseWriter, r *http.Request), refreshToken string) (int, *model.TokensResponse) {
+
+	r := httptest.NewRequest("POST", "/api/v1/vet/users/refresh", strings.NewReader(`{"refresh_token":"`+refreshToken+`"}`))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler(w, r)
+
+	tokens := &model.TokensResponse{}
+	json.NewDecoder(w.Body).Decode(tokens)
+
+	return w.Code, tokens
+}
+
+func TestRefreshRotation(t *testing.T) {
+
+	userConfig, user := sessionConfig(t)
+
+	// Each step uses a token of the previous steps, the rotated tokens are kept by step
+	tokens := map[string]string{"login": newSession(t, userConfig, user), "other session": newSession(t, userConfig, user)}
+	tokens["signed with another key"], _ = authentication.GenerateToken(authentication.NewHMACKeySet("other secret"), map[string]interface{}{"jti": "forged"}, refreshTokenDuration)
+
+	steps := []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{"first rotation", "login", 200},
+		{"second rotation", "first rotation", 200},
+		{"reuse of a rotated token", "first rotation", 401},
+		{"last token of the revoked session", "second rotation", 401},
+		{"other session kept", "other session", 200},
+		{"signed with another key", "signed with another key", 401},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			got, rotated := postRefreshToken(userConfig.RefreshHandler, tokens[step.token])
+			if got != step.want {
+				t.Fatalf("got status %d, want %d", got, step.want)
+			}
+
+			if got == 200 {
+				if rotated.RefreshToken == "" || rotated.RefreshToken == tokens[step.token] || rotated.AccessToken == "" {
+					t.Errorf("got %+v, want new tokens", rotated)
+				}
+				tokens[step.name] = rotated.RefreshToken
+			}
+		})
+	}
+}
+
+func TestLogout(t *testing.T) {
+
+	userConfig, user := sessionConfig(t)
+
+	refreshToken := newSession(t, userConfig, user)
+	_, rotated := postRefreshToken(userConfig.RefreshHandler, refreshToken)
+
+	// Logging out with the old token of the session revokes the new one too
+	if got, _ := postRefreshToken(userConfig.LogoutHandler, refreshToken); got != 200 {
+		t.Fatalf("logout: got status %d, want 200", got)
+	}
+	if got, _ := postRefreshToken(userConfig.RefreshHandler, rotated.RefreshToken); got != 401 {
+		t.Errorf("refresh after logout: got status %d, want 401", got)
+	}
+	if got, _ := postRefreshToken(userConfig.LogoutHandler, "not a token"); got != 401 {
+		t.Errorf("logout with an invalid token: got status %d, want 401", got)
+	}
+
+	// The access token sent with the logout is revoked, an access token of another user is not
+	t.Run("access token", func(t *testing.T) {
+		session, err := userConfig.issueTokens(user)
+		if err != nil {
+			t.Fatal(err)
+		}
+		other, err := authentication.GenerateToken(userConfig.AccessTokenKeys, map[string]interface{}{"user_id": user.ID + 1}, accessTokenDuration)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, accessToken := range []string{session.AccessToken, other} {
+			r := httptest.NewRequest("POST", "/api/v1/vet/users/logout", strings.NewReader(`{"refresh_token":"`+session.RefreshToken+`"}`))
+			r.Header.Set("Content-Type", "application/json")
+			r.Header.Set("Authorization", "Bearer "+accessToken)
+			w := httptest.NewRecorder()
+			userConfig.LogoutHandler(w, r)
+			if w.Code != 200 {
+				t.Fatalf("got status %d, want 200", w.Code)
+			}
+		}
+
+		tests := []struct {
+			name        string
+			accessToken string
+			revoked     bool
+		}{
+			{"token of the user", session.AccessToken, true},
+			{"token of another user", other, false},
+		}
+
+		for _, tt := range tests {
+			claims, err := authentication.ParseTokenClaims(userConfig.AccessTokenKeys, tt.accessToken)
+			if err != nil {
+				t.Fatal(err)
+			}
+			jti, _ := claims["jti"].(string)
+			userId, _ := claims["user_id"].(float64)
+			if got := userConfig.RevocationStore.IsRevoked(jti, uint(userId), time.Now().Add(-time.Minute)); got != tt.revoked {
+				t.Errorf("%s: got revoked %t, want %t", tt.name, got, tt.revoked)
+			}
+		}
+	})
+}
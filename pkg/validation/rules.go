@@ -0,0 +1,209 @@
+package validation
+
+import (
+	"fmt"
+	"net/mail"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Codes of the field errors
+const (
+	CodeRequired   = "required"
+	CodeMin        = "min"
+	CodeMax        = "max"
+	CodeMinLength  = "min_length"
+	CodeMaxLength  = "max_length"
+	CodeMinItems   = "min_items"
+	CodeEnum       = "enum"
+	CodeDateFormat = "date_format"
+	CodeEmail      = "email"
+	CodeFuture     = "future"
+	CodeAfter      = "after"
+
+	// Password refused by the password service
+	CodePassword = "password_policy"
+
+	// Scope of an API key not granted to the role of the user
+	CodeNotGranted = "not_granted"
+)
+
+// Rule checking the value of a field, built by the functions below
+type Rule struct {
+	code     string
+	message  string
+	params   map[string]interface{}
+	required bool
+	valid    func(value interface{}) bool
+}
+
+// The field must be given, and not empty for a text
+func Required() Rule {
+	return Rule{
+		code:     CodeRequired,
+		message:  "is required",
+		required: true,
+		valid: func(value interface{}) bool {
+			if value == nil {
+				return false
+			}
+			text, ok := value.(string)
+			return !ok || strings.TrimSpace(text) != ""
+		},
+	}
+}
+
+// Minimum of a number
+func Min(min int) Rule {
+	return Rule{
+		code:    CodeMin,
+		message: fmt.Sprintf("must be at least %d", min),
+		params:  map[string]interface{}{"min": min},
+		valid: func(value interface{}) bool {
+			number, ok := toInt(value)
+			return ok && number >= int64(min)
+		},
+	}
+}
+
+// Maximum of a number
+func Max(max int) Rule {
+	return Rule{
+		code:    CodeMax,
+		message: fmt.Sprintf("must be at most %d", max),
+		params:  map[string]interface{}{"max": max},
+		valid: func(value interface{}) bool {
+			number, ok := toInt(value)
+			return ok && number <= int64(max)
+		},
+	}
+}
+
+// Minimum number of characters of a text
+func MinLength(min int) Rule {
+	return Rule{
+		code:    CodeMinLength,
+		message: fmt.Sprintf("must be at least %d characters long", min),
+		params:  map[string]interface{}{"min": min},
+		valid: func(value interface{}) bool {
+			text, ok := value.(string)
+			return ok && utf8.RuneCountInString(text) >= min
+		},
+	}
+}
+
+// Maximum number of characters of a text
+func MaxLength(max int) Rule {
+	return Rule{
+		code:    CodeMaxLength,
+		message: fmt.Sprintf("must be at most %d characters long", max),
+		params:  map[string]interface{}{"max": max},
+		valid: func(value interface{}) bool {
+			text, ok := value.(string)
+			return ok && utf8.RuneCountInString(text) <= max
+		},
+	}
+}
+
+// Minimum number of items of a list
+func MinItems(min int) Rule {
+	return Rule{
+		code:    CodeMinItems,
+		message: fmt.Sprintf("must contain at least %d item(s)", min),
+		params:  map[string]interface{}{"min": min},
+		valid: func(value interface{}) bool {
+			items, ok := value.([]string)
+			return ok && len(items) >= min
+		},
+	}
+}
+
+// The text must be one of the values
+func OneOf(values ...string) Rule {
+	return Rule{
+		code:    CodeEnum,
+		message: "must be one of " + strings.Join(values, ", "),
+		params:  map[string]interface{}{"values": values},
+		valid: func(value interface{}) bool {
+			text, ok := value.(string)
+			return ok && slices.Contains(values, text)
+		},
+	}
+}
+
+// The text must be a date in the layout of the time package, the format is the layout shown to the client like YYYY-MM-DD
+func Date(layout string, format string) Rule {
+	return Rule{
+		code:    CodeDateFormat,
+		message: "wrong format, expected " + format,
+		params:  map[string]interface{}{"format": format},
+		valid: func(value interface{}) bool {
+			text, ok := value.(string)
+			if !ok {
+				return false
+			}
+			_, err := time.Parse(layout, text)
+			return err == nil
+		},
+	}
+}
+
+// The text must be a single email address, without a display name
+func Email() Rule {
+	return Rule{
+		code:    CodeEmail,
+		message: "must be a valid email address",
+		valid: func(value interface{}) bool {
+			text, ok := value.(string)
+			if !ok {
+				return false
+			}
+			address, err := mail.ParseAddress(text)
+			return err == nil && address.Address == text
+		},
+	}
+}
+
+// The time must be after now
+func Future() Rule {
+	return Rule{
+		code:    CodeFuture,
+		message: "must be in the future",
+		valid: func(value interface{}) bool {
+			date, ok := value.(time.Time)
+			return ok && date.After(time.Now())
+		},
+	}
+}
+
+// The time must be after the time of another field, not checked when the other field is not given
+func After(field string, other *time.Time) Rule {
+	return Rule{
+		code:    CodeAfter,
+		message: "must be after " + field,
+		params:  map[string]interface{}{"field": field},
+		valid: func(value interface{}) bool {
+			date, ok := value.(time.Time)
+			return ok && (other == nil || date.After(*other))
+		},
+	}
+}
+
+// Number of any integer type
+func toInt(value interface{}) (int64, bool) {
+
+	switch number := value.(type) {
+	case int:
+		return int64(number), true
+	case int64:
+		return number, true
+	case uint:
+		return int64(number), true
+	case uint64:
+		return int64(number), true
+	}
+
+	return 0, false
+}
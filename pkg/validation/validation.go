@@ -0,0 +1,105 @@
+package validation
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Error of a field of a request
+type FieldError struct {
+	Path    string                 `json:"path" example:"cat_age"`
+	Code    string                 `json:"code" example:"min"`
+	Message string                 `json:"message" example:"cat_age must be at least 1"`
+	Params  map[string]interface{} `json:"params,omitempty"`
+}
+
+// Every error of a request, returned by the Bind of the request models
+type Errors []*FieldError
+
+func (e Errors) Error() string {
+
+	messages := make([]string, 0, len(e))
+	for _, fieldError := range e {
+		messages = append(messages, fieldError.Message)
+	}
+
+	return strings.Join(messages, ", ")
+}
+
+// Collect the errors of the fields of a request
+type Validator struct {
+	errors Errors
+}
+
+func New() *Validator {
+	return &Validator{}
+}
+
+// Check a field with its rules, the next rules of the field are skipped after an error.
+// A nil value is only checked by Required, the other rules are for the given values.
+func (v *Validator) Field(path string, value interface{}, rules ...Rule) *Validator {
+
+	value, present := deref(value)
+
+	for _, rule := range rules {
+		if !present && !rule.required {
+			continue
+		}
+
+		if rule.valid(value) {
+			continue
+		}
+
+		v.Add(path, rule.code, path+" "+rule.message, rule.params)
+		break
+	}
+
+	return v
+}
+
+// Add an error checked by the request itself, like a field depending on another one
+func (v *Validator) Add(path string, code string, message string, params map[string]interface{}) *Validator {
+
+	v.errors = append(v.errors, &FieldError{
+		Path:    path,
+		Code:    code,
+		Message: message,
+		Params:  params,
+	})
+
+	return v
+}
+
+// Errors of the request, nil when every field is valid
+func (v *Validator) Err() error {
+
+	if len(v.errors) == 0 {
+		return nil
+	}
+
+	return v.errors
+}
+
+// Errors of the request, for a handler checking the fields against the DB
+func (v *Validator) Errors() Errors {
+	return v.errors
+}
+
+// Value pointed by a field of a request, false when the field is not given
+func deref(value interface{}) (interface{}, bool) {
+
+	reflected := reflect.ValueOf(value)
+	switch {
+	case !reflected.IsValid():
+		return nil, false
+	case reflected.Kind() == reflect.Pointer:
+		if reflected.IsNil() {
+			return nil, false
+		}
+		return reflected.Elem().Interface(), true
+	case reflected.Kind() == reflect.Slice && reflected.IsNil():
+		return nil, false
+	}
+
+	return value, true
+}
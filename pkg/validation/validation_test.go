@@ -0,0 +1,86 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestField(t *testing.T) {
+
+	text := func(value string) *string { return &value }
+	number := func(value int) *int { return &value }
+	date := func(value time.Time) *time.Time { return &value }
+	now := time.Now()
+
+	tests := []struct {
+		name  string
+		value interface{}
+		rules []Rule
+		code  string
+	}{
+		{"required missing", (*string)(nil), []Rule{Required()}, CodeRequired},
+		{"required blank", text("  "), []Rule{Required()}, CodeRequired},
+		{"required zero number", number(0), []Rule{Required()}, ""},
+		{"optional missing", (*int)(nil), []Rule{Min(1)}, ""},
+		{"min", number(0), []Rule{Min(1), Max(30)}, CodeMin},
+		{"max", number(31), []Rule{Min(1), Max(30)}, CodeMax},
+		{"in range", number(30), []Rule{Min(1), Max(30)}, ""},
+		{"unsigned number", uint(5), []Rule{Min(1)}, ""},
+		{"first error only", (*int)(nil), []Rule{Required(), Min(1)}, CodeRequired},
+		{"min length in characters", text("éé"), []Rule{MinLength(3)}, CodeMinLength},
+		{"max length in characters", text("ééé"), []Rule{MaxLength(3)}, ""},
+		{"min items", []string{}, []Rule{MinItems(1)}, CodeMinItems},
+		{"nil list not given", []string(nil), []Rule{MinItems(1)}, ""},
+		{"enum", text("dog"), []Rule{OneOf("cat", "kitten")}, CodeEnum},
+		{"date", text("2024-13-01"), []Rule{Date("2006-01-02", "YYYY-MM-DD")}, CodeDateFormat},
+		{"valid date", text("2024-12-01"), []Rule{Date("2006-01-02", "YYYY-MM-DD")}, ""},
+		{"email without a top-level domain", text("vet@example"), []Rule{Email()}, ""},
+		{"email with a display name", text("Vet <vet@example.com>"), []Rule{Email()}, CodeEmail},
+		{"not an email", text("vet.example.com"), []Rule{Email()}, CodeEmail},
+		{"future", date(now.Add(-time.Minute)), []Rule{Future()}, CodeFuture},
+		{"in the future", date(now.Add(time.Minute)), []Rule{Future()}, ""},
+		{"after", date(now), []Rule{After("starts_at", date(now))}, CodeAfter},
+		{"after a missing field", date(now), []Rule{After("starts_at", nil)}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fieldErrors := New().Field("field", tt.value, tt.rules...).Errors()
+
+			got := ""
+			if len(fieldErrors) > 0 {
+				got = fieldErrors[0].Code
+			}
+			if got != tt.code || len(fieldErrors) > 1 {
+				t.Errorf("got %v, want code %q", fieldErrors, tt.code)
+			}
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+
+	age := 0
+	err := New().
+		Field("cat_name", (*string)(nil), Required()).
+		Field("cat_age", &age, Min(1)).
+		Add("cat_owner_id", "not_found", "cat_owner_id not found", nil).
+		Err()
+
+	var fieldErrors Errors
+	if !errors.As(err, &fieldErrors) || len(fieldErrors) != 3 {
+		t.Fatalf("got %v, want 3 field errors", err)
+	}
+
+	if fieldErrors[1].Path != "cat_age" || fieldErrors[1].Params["min"] != 1 {
+		t.Errorf("got %+v, want the min of cat_age", fieldErrors[1])
+	}
+	if err.Error() != "cat_name is required, cat_age must be at least 1, cat_owner_id not found" {
+		t.Errorf("got message %q", err.Error())
+	}
+
+	if err := New().Field("cat_age", &age, Max(30)).Err(); err != nil {
+		t.Errorf("got %v, want no error", err)
+	}
+}
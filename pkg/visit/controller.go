@@ -1,17 +1,31 @@
 package visit
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 	"vet-clinic-api/config"
 	"vet-clinic-api/database/dbmodel"
+	"vet-clinic-api/pkg/audit"
+	"vet-clinic-api/pkg/authentication"
 	"vet-clinic-api/pkg/model"
+	"vet-clinic-api/pkg/pagination"
+	"vet-clinic-api/pkg/patch"
+	"vet-clinic-api/pkg/problem"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
 )
 
+// Fields of the response used to sort the visits, with their column
+var sortFields = map[string]string{
+	"id":              "id",
+	"visit_starts_at": "starts_at",
+	"visit_reason":    "reason",
+	"visit_vet":       "vet",
+}
+
 type VisitConfig struct {
 	*config.Config
 }
@@ -22,15 +36,16 @@ func New(configuration *config.Config) *VisitConfig {
 
 // PostHandler godoc
 // @Summary      Create a new visit
-// @Description  Creates a new visit entry in the database
+// @Description  Creates a new visit entry in the database. The times are in RFC 3339 with their offset, they are given back in the timezone of the clinic.
 // @Tags         visits
 // @Accept       json
 // @Produce      json
 // @Param        visit  body      model.VisitRequest  true  "Visit creation payload"
 // @Security     BearerAuth
 // @Success      200    {object}  model.VisitResponse
-// @Failure      400    {object}  map[string]string  "Invalid request payload"
-// @Failure      500    {object}  map[string]string  "Failed to create visit"
+// @Failure      400    {object}  problem.Problem  "Invalid request payload"
+// @Failure      422    {object}  problem.Problem  "Validation failed"
+// @Failure      500    {object}  problem.Problem  "Failed to create visit"
 // @Router       /visits [post]
 func (config *VisitConfig) PostHandler(w http.ResponseWriter, r *http.Request) {
 
@@ -38,96 +53,107 @@ func (config *VisitConfig) PostHandler(w http.ResponseWriter, r *http.Request) {
 	req := &model.VisitRequest{}
 	if err := render.Bind(r, req); err != nil {
 		// render.JSON(w, r, map[string]string{"error": "Invalid Visit Post request payload"})
-		render.JSON(w, r, map[string]string{"error": "Invalid Visit Post request payload. " + err.Error()})
+		problem.Bind(w, r, err, "Invalid Visit Post request payload")
 		return
 	}
 
 	// Check if the linked cat id existe
 	if !config.CatEntryRepository.FindLastCatId(int(*req.CatId)) {
-		render.JSON(w, r, map[string]string{"error": "CatId not found in the DB"})
+		problem.InvalidReference(w, r, "CatId not found in the DB")
 		return
 	}
 
 	// Convert the requested data into dbmodel.VisitEntry type for the "Create" function
-	visitEntry := &dbmodel.VisitEntry{CatId: *req.CatId, Date: *req.Date, Reason: *req.Reason, Vet: *req.Vet}
+	visitEntry := visitEntry(req)
 
 	// Request the DB to Create the informations
-	entries, err := config.VisitEntryRepository.Create(visitEntry)
+	entries, err := config.VisitEntryRepository.WithContext(audit.Context(r)).Create(visitEntry)
 	if err != nil {
-		render.JSON(w, r, map[string]string{"error": "Failed to Create visit"})
+		problem.Error(w, r, err, "Failed to Create visit")
 		return
 	}
 
 	// Set up to a dedicated type for the response
-	res := &model.VisitResponse{
-		Id:         entries.ID,
-		CatId:      entries.CatId,
-		Date:       entries.Date,
-		Reason:     entries.Reason,
-		Vet:        entries.Vet,
-		Treatments: []*model.TreatmentResponse{}}
+	res := visitResponse(entries, config.ClinicLocation)
 
 	render.JSON(w, r, res)
 }
 
 // GetAlldHandler godoc
 // @Summary      Get all visits with optional filters
-// @Description  Retrieves a list of all visits from the database, optionally filtered by vet, reason, or date
+// @Description  Retrieves the visits from the database by page. The filters are combined, a visit must match all of them. The total count is in the X-Total-Count header and the other pages in the Link header.
 // @Tags         visits
 // @Produce      json
-// @Param        vet     query     string  false  "Filter by veterinarian name"
-// @Param        reason  query     string  false  "Filter by visit reason"
-// @Param        date    query     string  false  "Filter by date (format: YYYY-MM-DD)"
+// @Param        cat_id         query     int     false  "Filter by cat id"
+// @Param        vet            query     string  false  "Filter by veterinarian name"
+// @Param        reason         query     string  false  "Filter by visit reason"
+// @Param        date           query     string  false  "Visits starting this day in the timezone of the clinic (format: YYYY-MM-DD), instead of date_from and date_to"
+// @Param        date_from      query     string  false  "Visits starting from this day included (format: YYYY-MM-DD)"
+// @Param        date_to        query     string  false  "Visits starting until this day included (format: YYYY-MM-DD)"
+// @Param        has_treatment  query     bool    false  "Visits with (true) or without (false) a treatment"
+// @Param        treatment      query     string  false  "Filter by name of a treatment of the visit"
+// @Param        limit          query     int     false  "Number of visits of the page (default 50, max 200)"
+// @Param        offset         query     int     false  "Number of visits skipped, instead of the cursor"
+// @Param        cursor         query     string  false  "Cursor of the next page, given in the Link header"
+// @Param        sort           query     string  false  "Fields separated by commas, - for descending (id, visit_starts_at, visit_reason, visit_vet)"
 // @Security     BearerAuth
 // @Success      200     {array}   model.VisitHistoryResponse
-// @Failure      500     {object}  map[string]string  "Failed to retrieve visits"
+// @Header       200     {integer}  X-Total-Count  "Number of visits matching the filters"
+// @Header       200     {string}   Link           "Links to the first, previous, next and last pages"
+// @Failure      400     {object}  problem.Problem  "Invalid filter or page"
+// @Failure      500     {object}  problem.Problem  "Failed to retrieve visits"
 // @Router       /visits [get]
 func (config *VisitConfig) GetAlldHandler(w http.ResponseWriter, r *http.Request) {
 
-	// Set up the filter by "vet" or "reason"
-	var entries []*dbmodel.VisitEntry
-	var err error
+	// Set up the search with every given filter
+	query, err := visitQuery(r, config.ClinicLocation)
+	if err != nil {
+		problem.BadRequest(w, r, err.Error())
+		return
+	}
 
-	vet := r.URL.Query().Get("vet")
-	reason := r.URL.Query().Get("reason")
-	date := r.URL.Query().Get("date")
+	// Owners only get the visits of their own cats, none without owner id
+	if ownerId, ok := authentication.OwnerIdFromContext(r.Context()); ok {
+		query.OwnerId = &ownerId
+	}
 
-	// Request the DB to Get the needed informations base on the filter
-	switch {
-	case vet != "":
-		entries, err = config.VisitEntryRepository.FindByVet(vet)
-	case reason != "":
-		entries, err = config.VisitEntryRepository.FindByReason(reason)
-	case date != "":
-		entries, err = config.VisitEntryRepository.FindByDate(date)
-	default:
-		entries, err = config.VisitEntryRepository.FindAll()
+	page, err := pagination.Parse(r, sortFields)
+	if err != nil {
+		problem.BadRequest(w, r, err.Error())
+		return
 	}
 
+	// Request the DB to Get the needed informations base on the search
+	entries, info, err := config.VisitEntryRepository.FindAll(query, page)
 	if err != nil {
-		render.JSON(w, r, map[string]string{"error": "Failed to Find Visits"})
+		problem.Error(w, r, err, "Failed to Find Visits")
 		return
 	}
 
 	// Set up to a dedicated type for the response
-	var res []*model.VisitHistoryResponse
+	res := []*model.VisitHistoryResponse{}
 	var treatments []*model.TreatmentHistoryResponse
 
 	for _, visit := range entries {
 		for _, treatment := range visit.Treatments {
-			treatments = append(treatments, &model.TreatmentHistoryResponse{Id: treatment.ID, Name: treatment.Name})
+			treatments = append(treatments, &model.TreatmentHistoryResponse{Id: treatment.ID, Name: treatment.Name, CreatedBy: treatment.CreatedBy, UpdatedBy: treatment.UpdatedBy})
 		}
 
+		startsAt, endsAt := visit.In(config.ClinicLocation)
 		res = append(res,
 			&model.VisitHistoryResponse{
 				Id:         visit.ID,
-				Date:       visit.Date,
+				StartsAt:   startsAt,
+				EndsAt:     endsAt,
 				Reason:     visit.Reason,
 				Vet:        visit.Vet,
-				Treatments: treatments})
+				Treatments: treatments,
+				CreatedBy:  visit.CreatedBy,
+				UpdatedBy:  visit.UpdatedBy})
 		treatments = nil
 	}
 
+	pagination.Write(w, r, page, info)
 	render.JSON(w, r, res)
 }
 
@@ -139,27 +165,36 @@ func (config *VisitConfig) GetAlldHandler(w http.ResponseWriter, r *http.Request
 // @Param        id   path      int  true  "Visit ID"
 // @Security     BearerAuth
 // @Success      200  {object}  model.VisitHistoryResponse
-// @Failure      404  {object}  map[string]string  "Visit not found"
-// @Failure      500  {object}  map[string]string  "Failed to find specific visit"
+// @Failure      400  {object}  problem.Problem  "Invalid request"
+// @Failure      403  {object}  problem.Problem  "Forbidden for an owner account"
+// @Failure      404  {object}  problem.Problem  "Visit not found"
+// @Failure      500  {object}  problem.Problem  "Failed to find specific visit"
 // @Router       /visits/{id} [get]
 func (config *VisitConfig) GetByIdHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Get the id in the URL
 	idStr := chi.URLParam(r, "id")
 	if idStr == "" {
-		w.WriteHeader(http.StatusNotFound)
+		problem.NotFound(w, r, "Missing id")
 		return
 	}
 
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		fmt.Println("Error during id convertion")
+		problem.BadRequest(w, r, "id must be an integer")
+		return
 	}
 
 	// Request the DB to Get the needed informations
 	entries, err := config.VisitEntryRepository.FindById(id)
 	if err != nil {
-		render.JSON(w, r, map[string]string{"error": "Failed to Find specific Visit"})
+		problem.Error(w, r, err, "Failed to Find specific Visit")
+		return
+	}
+
+	// Owners can only read the visits of their own cats
+	if ownerId, ok := authentication.OwnerIdFromContext(r.Context()); ok && !config.CatEntryRepository.BelongsToOwner(int(entries.CatId), ownerId) {
+		problem.Forbidden(w, r, "Visit not owned by the user")
 		return
 	}
 
@@ -167,15 +202,19 @@ func (config *VisitConfig) GetByIdHandler(w http.ResponseWriter, r *http.Request
 	var treatments []*model.TreatmentHistoryResponse
 
 	for _, visit := range entries.Treatments {
-		treatments = append(treatments, &model.TreatmentHistoryResponse{Id: visit.ID, Name: visit.Name})
+		treatments = append(treatments, &model.TreatmentHistoryResponse{Id: visit.ID, Name: visit.Name, CreatedBy: visit.CreatedBy, UpdatedBy: visit.UpdatedBy})
 	}
 
+	startsAt, endsAt := entries.In(config.ClinicLocation)
 	res := &model.VisitHistoryResponse{
 		Id:         entries.ID,
-		Date:       entries.Date,
+		StartsAt:   startsAt,
+		EndsAt:     endsAt,
 		Reason:     entries.Reason,
 		Vet:        entries.Vet,
-		Treatments: treatments}
+		Treatments: treatments,
+		CreatedBy:  entries.CreatedBy,
+		UpdatedBy:  entries.UpdatedBy}
 
 	render.JSON(w, r, res)
 }
@@ -190,64 +229,147 @@ func (config *VisitConfig) GetByIdHandler(w http.ResponseWriter, r *http.Request
 // @Param        visit  body      model.VisitRequest  true  "Visit update payload"
 // @Security     BearerAuth
 // @Success      200    {object}  model.VisitResponse
-// @Failure      400    {object}  map[string]string  "Invalid request payload"
-// @Failure      404    {object}  map[string]string  "Visit not found"
-// @Failure      500    {object}  map[string]string  "Failed to update visit"
+// @Failure      400    {object}  problem.Problem  "Invalid request payload"
+// @Failure      404    {object}  problem.Problem  "Visit not found"
+// @Failure      422    {object}  problem.Problem  "Validation failed"
+// @Failure      500    {object}  problem.Problem  "Failed to update visit"
 // @Router       /visits/{id} [put]
 func (config *VisitConfig) UpdateHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Get the id in the URL
 	idStr := chi.URLParam(r, "id")
 	if idStr == "" {
-		w.WriteHeader(http.StatusNotFound)
+		problem.NotFound(w, r, "Missing id")
 		return
 	}
 
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		fmt.Println("Error during id convertion")
+		problem.BadRequest(w, r, "id must be an integer")
+		return
 	}
 
 	// Get the request
 	req := &model.VisitRequest{}
 	if err := render.Bind(r, req); err != nil {
-		render.JSON(w, r, map[string]string{"error": "Invalid Visit Update request payload. " + err.Error()})
+		problem.Bind(w, r, err, "Invalid Visit Update request payload")
 		return
 	}
 
 	// Check if the linked cat id existe
 	if !config.CatEntryRepository.FindLastCatId(int(*req.CatId)) {
-		render.JSON(w, r, map[string]string{"error": "CatId not found in the DB"})
+		problem.InvalidReference(w, r, "CatId not found in the DB")
 		return
 	}
 
 	// Convert the requested data into dbmodel.VisitEntry type for the "Update" function
-	visitEntry := &dbmodel.VisitEntry{CatId: *req.CatId, Date: *req.Date, Reason: *req.Reason, Vet: *req.Vet}
+	visitEntry := visitEntry(req)
 
 	// Request the DB to Update the informations
-	entries, err := config.VisitEntryRepository.Update(id, visitEntry)
+	if _, err := config.VisitEntryRepository.WithContext(audit.Context(r)).Update(id, visitEntry); err != nil {
+		problem.Error(w, r, err, "Failed to Update Visit")
+		return
+	}
+
+	// Request the DB to get the updated visit with its treatments
+	entries, err := config.VisitEntryRepository.FindById(id)
 	if err != nil {
-		render.JSON(w, r, map[string]string{"error": "Failed to Update Visit"})
+		problem.Error(w, r, err, "Failed to Find updated Visit")
 		return
 	}
 
 	// Set up to a dedicated type for the response
-	var treatments []*model.TreatmentResponse
-	for _, treatment := range entries.Treatments {
-		treatments = append(treatments,
-			&model.TreatmentResponse{
-				Id:      treatment.ID,
-				Name:    treatment.Name,
-				VisitId: treatment.VisitId})
+	res := visitResponse(entries, config.ClinicLocation)
+
+	render.JSON(w, r, res)
+}
+
+// PatchHandler godoc
+// @Summary      Partially update a visit
+// @Description  Updates only the given fields of a visit, with a JSON Merge Patch (Content-Type application/merge-patch+json) or a JSON Patch (Content-Type application/json-patch+json). The patched visit is checked like a full update.
+// @Tags         visits
+// @Accept       json
+// @Produce      json
+// @Param        id     path      int                 true  "Visit ID"
+// @Param        visit  body      model.VisitRequest  true  "Fields to change (merge patch) or operations (JSON patch)"
+// @Security     BearerAuth
+// @Success      200  {object}  model.VisitResponse
+// @Failure      400  {object}  problem.Problem  "Invalid request payload"
+// @Failure      404  {object}  problem.Problem  "Visit not found"
+// @Failure      409  {object}  problem.Problem  "JSON Patch test failed"
+// @Failure      415  {object}  problem.Problem  "Unsupported Content-Type"
+// @Failure      422  {object}  problem.Problem  "Validation failed or operation not applicable"
+// @Failure      500  {object}  problem.Problem  "Failed to update visit"
+// @Router       /visits/{id} [patch]
+func (config *VisitConfig) PatchHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the id in the URL
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		problem.NotFound(w, r, "Missing id")
+		return
 	}
 
-	res := &model.VisitResponse{
-		Id:         uint(id),
-		CatId:      entries.CatId,
-		Date:       entries.Date,
-		Reason:     entries.Reason,
-		Vet:        entries.Vet,
-		Treatments: treatments}
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		problem.BadRequest(w, r, "id must be an integer")
+		return
+	}
+
+	// Get the current visit, the patch is applied to its informations
+	before, err := config.VisitEntryRepository.FindById(id)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find specific Visit")
+		return
+	}
+
+	req := &model.VisitRequest{}
+	if err := patch.Apply(r, visitRequest(before, config.ClinicLocation), req); err != nil {
+		problem.Patch(w, r, err, "Invalid Visit Patch request payload")
+		return
+	}
+
+	// The patched visit is checked like a full update
+	if err := req.Bind(r); err != nil {
+		problem.Bind(w, r, err, "Invalid Visit Patch request payload")
+		return
+	}
+
+	// Check if the linked cat id existe
+	if !config.CatEntryRepository.FindLastCatId(int(*req.CatId)) {
+		problem.InvalidReference(w, r, "CatId not found in the DB")
+		return
+	}
+
+	// Only the changed columns are updated, the times are compared in UTC like they are saved
+	patched := visitEntry(req)
+	columns := map[string]interface{}{}
+	patch.Changed(columns, "cat_id", before.CatId, patched.CatId)
+	patch.Changed(columns, "starts_at", before.StartsAt.UTC(), patched.StartsAt)
+	patch.Changed(columns, "ends_at", utc(before.EndsAt), patched.EndsAt)
+	patch.Changed(columns, "reason", before.Reason, *req.Reason)
+	patch.Changed(columns, "vet", before.Vet, *req.Vet)
+
+	if len(columns) == 0 {
+		render.JSON(w, r, visitResponse(before, config.ClinicLocation))
+		return
+	}
+
+	// Request the DB to Update the changed informations
+	if _, err := config.VisitEntryRepository.WithContext(audit.Context(r)).Patch(id, columns); err != nil {
+		problem.Error(w, r, err, "Failed to Update Visit")
+		return
+	}
+
+	// Request the DB to get the updated visit
+	entries, err := config.VisitEntryRepository.FindById(id)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find updated Visit")
+		return
+	}
+
+	// Set up to a dedicated type for the response
+	res := visitResponse(entries, config.ClinicLocation)
 
 	render.JSON(w, r, res)
 }
@@ -260,29 +382,203 @@ func (config *VisitConfig) UpdateHandler(w http.ResponseWriter, r *http.Request)
 // @Param        id   path      int  true  "Visit ID"
 // @Security     BearerAuth
 // @Success      200  {object}  map[string]string  "Visit deleted successfully"
-// @Failure      404  {object}  map[string]string  "Visit not found"
-// @Failure      500  {object}  map[string]string  "Failed to delete visit"
+// @Failure      400  {object}  problem.Problem  "Invalid request"
+// @Failure      404  {object}  problem.Problem  "Visit not found"
+// @Failure      500  {object}  problem.Problem  "Failed to delete visit"
 // @Router       /visits/{id} [delete]
 func (config *VisitConfig) DeleteHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Get the id in the URL
 	idStr := chi.URLParam(r, "id")
 	if idStr == "" {
-		w.WriteHeader(http.StatusNotFound)
+		problem.NotFound(w, r, "Missing id")
 		return
 	}
 
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		fmt.Println("Error during id convertion")
+		problem.BadRequest(w, r, "id must be an integer")
+		return
 	}
 
 	// Request the DB to Delete the informations
-	errDelete := config.VisitEntryRepository.DeleteById(id)
+	errDelete := config.VisitEntryRepository.WithContext(audit.Context(r)).DeleteById(id)
 	if errDelete != nil {
-		render.JSON(w, r, map[string]string{"error": "Failed to Delete Visit"})
+		problem.Error(w, r, errDelete, "Failed to Delete Visit")
 		return
 	}
 
 	render.JSON(w, r, map[string]string{"message": "Visit deleted successfully"})
 }
+
+// RestoreHandler godoc
+// @Summary      Restore a visit
+// @Description  Takes a visit out of the trash with the treatments deleted with it, its cat must not be in the trash
+// @Tags         visits
+// @Produce      json
+// @Param        id   path      int  true  "Visit ID"
+// @Security     BearerAuth
+// @Success      200  {object}  model.VisitResponse
+// @Failure      400  {object}  problem.Problem  "Invalid request"
+// @Failure      404  {object}  problem.Problem  "Visit not found in the trash"
+// @Failure      409  {object}  problem.Problem  "The cat of the visit is in the trash"
+// @Router       /visits/{id}/restore [post]
+func (config *VisitConfig) RestoreHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get the id in the URL
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		problem.NotFound(w, r, "Missing id")
+		return
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		problem.BadRequest(w, r, "id must be an integer")
+		return
+	}
+
+	// Request the DB to take the visit out of the trash
+	if _, err := config.VisitEntryRepository.WithContext(audit.Context(r)).Restore(id); err != nil {
+		if errors.Is(err, dbmodel.ErrParentDeleted) {
+			problem.Conflict(w, r, "The cat of the visit is in the trash, restore it first")
+			return
+		}
+		problem.Error(w, r, err, "Visit not found in the trash")
+		return
+	}
+
+	// Request the DB to get the restored visit
+	entries, err := config.VisitEntryRepository.FindById(id)
+	if err != nil {
+		problem.Error(w, r, err, "Failed to Find restored Visit")
+		return
+	}
+
+	// Set up to a dedicated type for the response
+	res := visitResponse(entries, config.ClinicLocation)
+
+	render.JSON(w, r, res)
+}
+
+// Set up a visit and its treatments to a dedicated type for the responses, the times are given in the timezone of the clinic
+func visitResponse(entries *dbmodel.VisitEntry, location *time.Location) *model.VisitResponse {
+
+	treatments := []*model.TreatmentResponse{}
+	for _, treatment := range entries.Treatments {
+		treatments = append(treatments,
+			&model.TreatmentResponse{
+				Id:        treatment.ID,
+				Name:      treatment.Name,
+				VisitId:   treatment.VisitId,
+				CreatedBy: treatment.CreatedBy,
+				UpdatedBy: treatment.UpdatedBy})
+	}
+
+	startsAt, endsAt := entries.In(location)
+	return &model.VisitResponse{
+		Id:         entries.ID,
+		CatId:      entries.CatId,
+		StartsAt:   startsAt,
+		EndsAt:     endsAt,
+		Reason:     entries.Reason,
+		Vet:        entries.Vet,
+		Treatments: treatments,
+		CreatedBy:  entries.CreatedBy,
+		UpdatedBy:  entries.UpdatedBy}
+}
+
+// Set up a visit to the request model, the patches are applied to it
+func visitRequest(entries *dbmodel.VisitEntry, location *time.Location) *model.VisitRequest {
+
+	startsAt, endsAt := entries.In(location)
+	return &model.VisitRequest{
+		CatId:    &entries.CatId,
+		StartsAt: &startsAt,
+		EndsAt:   endsAt,
+		Reason:   &entries.Reason,
+		Vet:      &entries.Vet}
+}
+
+// Convert the request into dbmodel.VisitEntry type, the times are saved in UTC to be ordered
+func visitEntry(req *model.VisitRequest) *dbmodel.VisitEntry {
+
+	return &dbmodel.VisitEntry{
+		CatId:    *req.CatId,
+		StartsAt: req.StartsAt.UTC(),
+		EndsAt:   utc(req.EndsAt),
+		Reason:   *req.Reason,
+		Vet:      *req.Vet}
+}
+
+// Time in UTC, nil stays nil
+func utc(value *time.Time) *time.Time {
+
+	if value == nil {
+		return nil
+	}
+
+	converted := value.UTC()
+	return &converted
+}
+
+// Set up the search of the visits from the query parameters, the days are in the timezone of the clinic
+func visitQuery(r *http.Request, location *time.Location) (dbmodel.VisitQuery, error) {
+
+	values := r.URL.Query()
+	query := dbmodel.VisitQuery{
+		Vet:           values.Get("vet"),
+		Reason:        values.Get("reason"),
+		TreatmentName: values.Get("treatment")}
+
+	if value := values.Get("cat_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return query, errors.New("cat_id must be a positive integer")
+		}
+		query.CatId = uint(id)
+	}
+
+	days := map[string]time.Time{}
+	for _, name := range []string{"date", "date_from", "date_to"} {
+		if values.Get(name) == "" {
+			continue
+		}
+
+		day, err := time.ParseInLocation("2006-01-02", values.Get(name), location)
+		if err != nil {
+			return query, errors.New(name + " wrong format, expected YYYY-MM-DD")
+		}
+		days[name] = day
+	}
+
+	// A single date is a range of one day
+	if date, ok := days["date"]; ok {
+		if values.Get("date_from") != "" || values.Get("date_to") != "" {
+			return query, errors.New("date can't be used with date_from or date_to")
+		}
+		days["date_from"], days["date_to"] = date, date
+	}
+
+	from, hasFrom := days["date_from"]
+	to, hasTo := days["date_to"]
+	if hasFrom && hasTo && from.After(to) {
+		return query, errors.New("date_from must be before date_to")
+	}
+
+	// The last day is included until the start of the next day, which is not always 24 hours later
+	query.StartsFrom = from
+	if hasTo {
+		query.StartsBefore = to.AddDate(0, 0, 1)
+	}
+
+	if value := values.Get("has_treatment"); value != "" {
+		hasTreatment, err := strconv.ParseBool(value)
+		if err != nil {
+			return query, errors.New("has_treatment must be true or false")
+		}
+		query.HasTreatment = &hasTreatment
+	}
+
+	return query, nil
+}
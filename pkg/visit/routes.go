@@ -15,16 +15,21 @@ func Routes(configuration *config.Config) chi.Router {
 
 	// Routes protected by authentication
 	router.Group(func(router chi.Router) {
-		router.Use(authentication.AuthMiddleware(visitConfig.JWTSecret))
+		router.Use(authentication.AuthMiddleware(visitConfig.AccessTokenKeys, visitConfig.RevocationStore, visitConfig.ApiKeyStore, visitConfig.RoleEntryRepository))
 
-		router.Get("/", visitConfig.GetAlldHandler)
-		router.Get("/{id}", visitConfig.GetByIdHandler)
+		// Routes protected by authentication and accessible with the "visits:read" permission
+		router.With(authentication.RequirePermission("visits:read")).Group(func(r chi.Router) {
+			r.Get("/", visitConfig.GetAlldHandler)
+			r.Get("/{id}", visitConfig.GetByIdHandler)
+		})
 
-		// Routes protected by authentication and accessible by admin only
-		router.With(authentication.RoleMiddleware("admin")).Group(func(r chi.Router) {
+		// Routes protected by authentication and accessible with the "visits:write" permission
+		router.With(authentication.RequirePermission("visits:write")).Group(func(r chi.Router) {
 			r.Post("/", visitConfig.PostHandler)
 			r.Put("/{id}", visitConfig.UpdateHandler)
+			r.Patch("/{id}", visitConfig.PatchHandler)
 			r.Delete("/{id}", visitConfig.DeleteHandler)
+			r.Post("/{id}/restore", visitConfig.RestoreHandler)
 		})
 	})
 